@@ -31,17 +31,21 @@ var (
 // AssociationDefinition defines one-to-one relation between values named left
 // and right. The relation is unique.
 type AssociationDefinition[L, R any] struct {
-	bucketPathLeft   [][]byte
-	bucketPathRight  [][]byte
-	leftEncoding     Encoding[L]
-	rightEncoding    Encoding[R]
-	fillPercent      float64
-	errLeftNotFound  error
-	errRightNotFound error
-	errLeftExists    error
-	errRightExists   error
-	setCallback      func(left []byte) error
-	deleteCallback   func(left []byte) error
+	bucketPathLeft     [][]byte
+	bucketPathRight    [][]byte
+	leftEncoding       Encoding[L]
+	rightEncoding      Encoding[R]
+	fillPercent        float64
+	errLeftNotFound    error
+	errRightNotFound   error
+	errLeftExists      error
+	errRightExists     error
+	hashedLeft         bool
+	hashedRight        bool
+	unorderedIteration bool
+	corruptedHandler   func(key []byte, err error)
+	setCallback        func(left, right []byte) error
+	deleteCallback     func(left, right []byte) error
 }
 
 // AssociationOptions provides additional configuration for an Association.
@@ -56,6 +60,25 @@ type AssociationOptions struct {
 	ErrLeftExists error
 	// ErrRightExists is returned if the right value in relation already exists.
 	ErrRightExists error
+	// CorruptedHandler is called with the encoded left or right value of every
+	// relation that is skipped by iteration and pagination methods because it
+	// can not be decoded with ErrCorrupted error. If it is nil, iteration is
+	// aborted. Skipped relations are still counted in total elements and pages
+	// returned by pagination methods, so a page may contain fewer elements
+	// than the limit.
+	CorruptedHandler func(key []byte, err error)
+	// HashedLeft marks if left values are stored in bolt as their fixed size
+	// hashes, with the original values stored alongside right values. It
+	// allows left values that are larger than the bolt key size limit.
+	// Iteration and pagination over left values return ErrKeysNotOrdered,
+	// unless UnorderedIteration is set.
+	HashedLeft bool
+	// HashedRight marks if right values are stored in bolt as their fixed size
+	// hashes, in the same way as HashedLeft does for left values.
+	HashedRight bool
+	// UnorderedIteration allows iteration and pagination of hashed values in
+	// the order of their hashes.
+	UnorderedIteration bool
 }
 
 // NewAssociationDefinition constructs a new AssociationDefinition with a unique
@@ -70,15 +93,19 @@ func NewAssociationDefinition[L, R any](
 		o = new(AssociationOptions)
 	}
 	return &AssociationDefinition[L, R]{
-		bucketPathLeft:   bucketPath("boltron: association: " + name + " left"),
-		bucketPathRight:  bucketPath("boltron: association: " + name + " right"),
-		leftEncoding:     leftEncoding,
-		rightEncoding:    rightEncoding,
-		fillPercent:      o.FillPercent,
-		errLeftNotFound:  withDefaultError(o.ErrLeftNotFound, ErrLeftNotFound),
-		errRightNotFound: withDefaultError(o.ErrRightNotFound, ErrRightNotFound),
-		errLeftExists:    withDefaultError(o.ErrLeftExists, ErrLeftExists),
-		errRightExists:   withDefaultError(o.ErrRightExists, ErrRightExists),
+		bucketPathLeft:     bucketPath("boltron: association: " + name + " left"),
+		bucketPathRight:    bucketPath("boltron: association: " + name + " right"),
+		leftEncoding:       leftEncoding,
+		rightEncoding:      rightEncoding,
+		fillPercent:        o.FillPercent,
+		errLeftNotFound:    withDefaultError(o.ErrLeftNotFound, ErrLeftNotFound),
+		errRightNotFound:   withDefaultError(o.ErrRightNotFound, ErrRightNotFound),
+		errLeftExists:      withDefaultError(o.ErrLeftExists, ErrLeftExists),
+		errRightExists:     withDefaultError(o.ErrRightExists, ErrRightExists),
+		hashedLeft:         o.HashedLeft,
+		hashedRight:        o.HashedRight,
+		unorderedIteration: o.UnorderedIteration,
+		corruptedHandler:   o.CorruptedHandler,
 	}
 }
 
@@ -129,6 +156,15 @@ func (a *Association[L, R]) rightBucket(create bool) (*bolt.Bucket, error) {
 	return bucket, nil
 }
 
+// checkOrder returns ErrKeysNotOrdered if values are hashed and they are not
+// allowed to be iterated in the order of hashes.
+func (a *Association[L, R]) checkOrder(hashed bool) error {
+	if hashed && !a.definition.unorderedIteration {
+		return ErrKeysNotOrdered
+	}
+	return nil
+}
+
 // HasLeft returns true if the left already exists in the database.
 func (a *Association[L, R]) HasLeft(left L) (bool, error) {
 	l, err := a.definition.leftEncoding.Encode(left)
@@ -145,7 +181,7 @@ func (a *Association[L, R]) HasLeft(left L) (bool, error) {
 		return false, nil
 	}
 
-	return leftBucket.Get(l) != nil, nil
+	return bucketGet(leftBucket, a.definition.hashedLeft, l) != nil, nil
 }
 
 // HasRight returns true if the right value already exists in the database.
@@ -164,7 +200,7 @@ func (a *Association[L, R]) HasRight(right R) (bool, error) {
 		return false, nil
 	}
 
-	return rightBucket.Get(r) != nil, nil
+	return bucketGet(rightBucket, a.definition.hashedRight, r) != nil, nil
 }
 
 // Left returns left value associated with the given right value. If value does
@@ -183,7 +219,7 @@ func (a *Association[L, R]) Left(right R) (left L, err error) {
 		return left, a.definition.errLeftNotFound
 	}
 
-	l := rightBucket.Get(r)
+	l := bucketGet(rightBucket, a.definition.hashedRight, r)
 	if l == nil {
 		return left, a.definition.errLeftNotFound
 	}
@@ -208,7 +244,7 @@ func (a *Association[L, R]) Right(left L) (right R, err error) {
 	if leftBucket == nil {
 		return right, a.definition.errRightNotFound
 	}
-	r := leftBucket.Get(l)
+	r := bucketGet(leftBucket, a.definition.hashedLeft, l)
 	if r == nil {
 		return right, a.definition.errRightNotFound
 	}
@@ -221,7 +257,8 @@ func (a *Association[L, R]) Right(left L) (right R, err error) {
 
 // Set saves the relation between the left and right values. If left value
 // already exists, configured ErrLeftExists is returned, if right value exists,
-// configured ErrValueExists is returned.
+// configured ErrValueExists is returned. Replace can be used to overwrite
+// existing relations.
 func (a *Association[L, R]) Set(left L, right R) error {
 	l, err := a.definition.leftEncoding.Encode(left)
 	if err != nil {
@@ -232,7 +269,7 @@ func (a *Association[L, R]) Set(left L, right R) error {
 		return fmt.Errorf("left bucket: %w", err)
 	}
 
-	currentRight := leftBucket.Get(l)
+	currentRight := bucketGet(leftBucket, a.definition.hashedLeft, l)
 
 	r, err := a.definition.rightEncoding.Encode(right)
 	if err != nil {
@@ -244,7 +281,7 @@ func (a *Association[L, R]) Set(left L, right R) error {
 		return fmt.Errorf("right bucket: %w", err)
 	}
 
-	currentLeft := rightBucket.Get(r)
+	currentLeft := bucketGet(rightBucket, a.definition.hashedRight, r)
 
 	if bytes.Equal(l, currentLeft) && bytes.Equal(r, currentRight) {
 		return nil
@@ -258,15 +295,212 @@ func (a *Association[L, R]) Set(left L, right R) error {
 		return a.definition.errLeftExists
 	}
 
-	if err := leftBucket.Put(l, r); err != nil {
+	if err := bucketPut(leftBucket, a.definition.hashedLeft, l, r); err != nil {
+		return fmt.Errorf("put left: %w", err)
+	}
+	if err := bucketPut(rightBucket, a.definition.hashedRight, r, l); err != nil {
+		return fmt.Errorf("put right: %w", err)
+	}
+
+	if a.definition.setCallback != nil {
+		if err := a.definition.setCallback(l, r); err != nil {
+			return fmt.Errorf("set callback: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Replace saves the relation between the left and right values, as Set does,
+// but instead of returning an error, it removes existing relations of both left
+// and right values.
+func (a *Association[L, R]) Replace(left L, right R) error {
+	l, err := a.definition.leftEncoding.Encode(left)
+	if err != nil {
+		return fmt.Errorf("encode left: %w", err)
+	}
+	r, err := a.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return fmt.Errorf("encode right: %w", err)
+	}
+
+	_, err = a.replace(l, r)
+	return err
+}
+
+// SetAndReplace saves the relation between the left and right values, as
+// Replace does, and returns relations that are removed because they contained
+// either the left or the right value. The relation of the left value is
+// returned before the relation of the right value.
+func (a *Association[L, R]) SetAndReplace(left L, right R) (replaced []AssociationElement[L, R], err error) {
+	l, err := a.definition.leftEncoding.Encode(left)
+	if err != nil {
+		return nil, fmt.Errorf("encode left: %w", err)
+	}
+	r, err := a.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return nil, fmt.Errorf("encode right: %w", err)
+	}
+
+	encoded, err := a.replace(l, r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range encoded {
+		left, err := a.definition.leftEncoding.Decode(e.left)
+		if err != nil {
+			return nil, fmt.Errorf("decode left: %w", err)
+		}
+		right, err := a.definition.rightEncoding.Decode(e.right)
+		if err != nil {
+			return nil, fmt.Errorf("decode right: %w", err)
+		}
+		replaced = append(replaced, AssociationElement[L, R]{
+			Left:  left,
+			Right: right,
+		})
+	}
+	return replaced, nil
+}
+
+type encodedAssociationElement struct {
+	left, right []byte
+}
+
+// replace removes existing relations of encoded left and right values and
+// saves the relation between them, returning removed relations.
+func (a *Association[L, R]) replace(l, r []byte) (replaced []encodedAssociationElement, err error) {
+	leftBucket, err := a.leftBucket(true)
+	if err != nil {
+		return nil, fmt.Errorf("left bucket: %w", err)
+	}
+	rightBucket, err := a.rightBucket(true)
+	if err != nil {
+		return nil, fmt.Errorf("right bucket: %w", err)
+	}
+
+	currentRight := bucketGet(leftBucket, a.definition.hashedLeft, l)
+	currentLeft := bucketGet(rightBucket, a.definition.hashedRight, r)
+
+	if bytes.Equal(l, currentLeft) && bytes.Equal(r, currentRight) {
+		return nil, nil
+	}
+
+	if currentRight != nil {
+		replaced = append(replaced, encodedAssociationElement{
+			left:  l,
+			right: append([]byte(nil), currentRight...),
+		})
+	}
+	if currentLeft != nil {
+		replaced = append(replaced, encodedAssociationElement{
+			left:  append([]byte(nil), currentLeft...),
+			right: r,
+		})
+	}
+
+	for _, e := range replaced {
+		if err := bucketDelete(leftBucket, a.definition.hashedLeft, e.left); err != nil {
+			return nil, fmt.Errorf("delete left: %w", err)
+		}
+		if err := bucketDelete(rightBucket, a.definition.hashedRight, e.right); err != nil {
+			return nil, fmt.Errorf("delete right: %w", err)
+		}
+		if a.definition.deleteCallback != nil {
+			if err := a.definition.deleteCallback(e.left, e.right); err != nil {
+				return nil, fmt.Errorf("delete callback: %w", err)
+			}
+		}
+	}
+
+	if err := bucketPut(leftBucket, a.definition.hashedLeft, l, r); err != nil {
+		return nil, fmt.Errorf("put left: %w", err)
+	}
+	if err := bucketPut(rightBucket, a.definition.hashedRight, r, l); err != nil {
+		return nil, fmt.Errorf("put right: %w", err)
+	}
+
+	if a.definition.setCallback != nil {
+		if err := a.definition.setCallback(l, r); err != nil {
+			return nil, fmt.Errorf("set callback: %w", err)
+		}
+	}
+
+	return replaced, nil
+}
+
+// Swap exchanges right values of relations that contain the provided left
+// values. If any of the left values does not exist, configured ErrLeftNotFound
+// is returned.
+func (a *Association[L, R]) Swap(left1, left2 L) error {
+	l1, err := a.definition.leftEncoding.Encode(left1)
+	if err != nil {
+		return fmt.Errorf("encode left: %w", err)
+	}
+	l2, err := a.definition.leftEncoding.Encode(left2)
+	if err != nil {
+		return fmt.Errorf("encode left: %w", err)
+	}
+
+	leftBucket, err := a.leftBucket(false)
+	if err != nil {
+		return fmt.Errorf("left bucket: %w", err)
+	}
+	if leftBucket == nil {
+		return a.definition.errLeftNotFound
+	}
+
+	r1 := bucketGet(leftBucket, a.definition.hashedLeft, l1)
+	if r1 == nil {
+		return a.definition.errLeftNotFound
+	}
+	r1 = append([]byte(nil), r1...)
+
+	r2 := bucketGet(leftBucket, a.definition.hashedLeft, l2)
+	if r2 == nil {
+		return a.definition.errLeftNotFound
+	}
+	r2 = append([]byte(nil), r2...)
+
+	if bytes.Equal(l1, l2) {
+		return nil
+	}
+
+	rightBucket, err := a.rightBucket(false)
+	if err != nil {
+		return fmt.Errorf("right bucket: %w", err)
+	}
+	if rightBucket == nil {
+		return a.definition.errRightNotFound
+	}
+
+	if err := bucketPut(leftBucket, a.definition.hashedLeft, l1, r2); err != nil {
+		return fmt.Errorf("put left: %w", err)
+	}
+	if err := bucketPut(leftBucket, a.definition.hashedLeft, l2, r1); err != nil {
 		return fmt.Errorf("put left: %w", err)
 	}
-	if err := rightBucket.Put(r, l); err != nil {
+	if err := bucketPut(rightBucket, a.definition.hashedRight, r1, l2); err != nil {
+		return fmt.Errorf("put right: %w", err)
+	}
+	if err := bucketPut(rightBucket, a.definition.hashedRight, r2, l1); err != nil {
 		return fmt.Errorf("put right: %w", err)
 	}
 
+	if a.definition.deleteCallback != nil {
+		if err := a.definition.deleteCallback(l1, r1); err != nil {
+			return fmt.Errorf("delete callback: %w", err)
+		}
+		if err := a.definition.deleteCallback(l2, r2); err != nil {
+			return fmt.Errorf("delete callback: %w", err)
+		}
+	}
 	if a.definition.setCallback != nil {
-		if err := a.definition.setCallback(l); err != nil {
+		if err := a.definition.setCallback(l1, r2); err != nil {
+			return fmt.Errorf("set callback: %w", err)
+		}
+		if err := a.definition.setCallback(l2, r1); err != nil {
 			return fmt.Errorf("set callback: %w", err)
 		}
 	}
@@ -295,7 +529,7 @@ func (a *Association[L, R]) DeleteByLeft(left L, ensure bool) error {
 		return nil
 	}
 
-	r := leftBucket.Get(l)
+	r := bucketGet(leftBucket, a.definition.hashedLeft, l)
 	if r == nil {
 		if ensure {
 			return a.definition.errLeftNotFound
@@ -303,7 +537,7 @@ func (a *Association[L, R]) DeleteByLeft(left L, ensure bool) error {
 		return nil
 	}
 
-	if err := leftBucket.Delete(l); err != nil {
+	if err := bucketDelete(leftBucket, a.definition.hashedLeft, l); err != nil {
 		return fmt.Errorf("delete left: %w", err)
 	}
 
@@ -319,12 +553,12 @@ func (a *Association[L, R]) DeleteByLeft(left L, ensure bool) error {
 		return nil
 	}
 
-	if err := rightBucket.Delete(r); err != nil {
+	if err := bucketDelete(rightBucket, a.definition.hashedRight, r); err != nil {
 		return fmt.Errorf("delete right: %w", err)
 	}
 
 	if a.definition.deleteCallback != nil {
-		if err := a.definition.deleteCallback(l); err != nil {
+		if err := a.definition.deleteCallback(l, r); err != nil {
 			return fmt.Errorf("delete callback: %w", err)
 		}
 	}
@@ -353,7 +587,7 @@ func (a *Association[L, R]) DeleteByRight(right R, ensure bool) error {
 		return nil
 	}
 
-	l := rightBucket.Get(r)
+	l := bucketGet(rightBucket, a.definition.hashedRight, r)
 	if l == nil {
 		if ensure {
 			return a.definition.errRightNotFound
@@ -373,16 +607,16 @@ func (a *Association[L, R]) DeleteByRight(right R, ensure bool) error {
 		return nil
 	}
 
-	if err := leftBucket.Delete(l); err != nil {
+	if err := bucketDelete(leftBucket, a.definition.hashedLeft, l); err != nil {
 		return fmt.Errorf("delete left: %w", err)
 	}
 
-	if err := rightBucket.Delete(r); err != nil {
+	if err := bucketDelete(rightBucket, a.definition.hashedRight, r); err != nil {
 		return fmt.Errorf("delete right: %w", err)
 	}
 
 	if a.definition.deleteCallback != nil {
-		if err := a.definition.deleteCallback(l); err != nil {
+		if err := a.definition.deleteCallback(l, r); err != nil {
 			return fmt.Errorf("delete callback: %w", err)
 		}
 	}
@@ -394,6 +628,9 @@ func (a *Association[L, R]) DeleteByRight(right R, ensure bool) error {
 // values. If the callback function f returns false, the iteration stops and the
 // next can be used to continue the iteration.
 func (a *Association[L, R]) Iterate(start *L, reverse bool, f func(L, R) (bool, error)) (next *L, err error) {
+	if err := a.checkOrder(a.definition.hashedLeft); err != nil {
+		return nil, err
+	}
 	leftBucket, err := a.leftBucket(false)
 	if err != nil {
 		return nil, fmt.Errorf("left bucket: %w", err)
@@ -401,14 +638,20 @@ func (a *Association[L, R]) Iterate(start *L, reverse bool, f func(L, R) (bool,
 	if leftBucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(leftBucket, a.definition.leftEncoding, start, reverse, func(l, r []byte) (bool, error) {
+	return iterateBucket(leftBucket, a.definition.hashedLeft, a.definition.leftEncoding, a.definition.corruptedHandler, start, reverse, func(l, r []byte) (bool, error) {
 		left, err := a.definition.leftEncoding.Decode(l)
 		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, l, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode left: %w", err)
 		}
 
 		right, err := a.definition.rightEncoding.Decode(r)
 		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, l, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode right: %w", err)
 		}
 
@@ -420,6 +663,9 @@ func (a *Association[L, R]) Iterate(start *L, reverse bool, f func(L, R) (bool,
 // left values. If the callback function f returns false, the iteration stops
 // and the next can be used to continue the iteration.
 func (a *Association[L, R]) IterateLeftValues(start *L, reverse bool, f func(L) (bool, error)) (next *L, err error) {
+	if err := a.checkOrder(a.definition.hashedLeft); err != nil {
+		return nil, err
+	}
 	leftBucket, err := a.leftBucket(false)
 	if err != nil {
 		return nil, fmt.Errorf("left bucket: %w", err)
@@ -427,9 +673,12 @@ func (a *Association[L, R]) IterateLeftValues(start *L, reverse bool, f func(L)
 	if leftBucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(leftBucket, a.definition.leftEncoding, start, reverse, func(l, _ []byte) (bool, error) {
+	return iterateBucket(leftBucket, a.definition.hashedLeft, a.definition.leftEncoding, a.definition.corruptedHandler, start, reverse, func(l, _ []byte) (bool, error) {
 		left, err := a.definition.leftEncoding.Decode(l)
 		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, l, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode left: %w", err)
 		}
 
@@ -441,6 +690,9 @@ func (a *Association[L, R]) IterateLeftValues(start *L, reverse bool, f func(L)
 // right values. If the callback function f returns false, the iteration stops
 // and the next can be used to continue the iteration.
 func (a *Association[L, R]) IterateRightValues(start *R, reverse bool, f func(R) (bool, error)) (next *R, err error) {
+	if err := a.checkOrder(a.definition.hashedRight); err != nil {
+		return nil, err
+	}
 	rightBucket, err := a.rightBucket(false)
 	if err != nil {
 		return nil, fmt.Errorf("right bucket: %w", err)
@@ -448,9 +700,12 @@ func (a *Association[L, R]) IterateRightValues(start *R, reverse bool, f func(R)
 	if rightBucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(rightBucket, a.definition.rightEncoding, start, reverse, func(r, _ []byte) (bool, error) {
+	return iterateBucket(rightBucket, a.definition.hashedRight, a.definition.rightEncoding, a.definition.corruptedHandler, start, reverse, func(r, _ []byte) (bool, error) {
 		right, err := a.definition.rightEncoding.Decode(r)
 		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, r, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode right: %w", err)
 		}
 
@@ -480,6 +735,9 @@ type AssociationElement[L, R any] struct {
 // Page returns at most a limit of elements of associations at the provided page
 // number.
 func (a *Association[L, R]) Page(number, limit int, reverse bool) (s []AssociationElement[L, R], totalElements, pages int, err error) {
+	if err := a.checkOrder(a.definition.hashedLeft); err != nil {
+		return nil, 0, 0, err
+	}
 	leftBucket, err := a.leftBucket(false)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("left bucket: %w", err)
@@ -487,14 +745,20 @@ func (a *Association[L, R]) Page(number, limit int, reverse bool) (s []Associati
 	if leftBucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(leftBucket, false, number, limit, reverse, func(l, r []byte) (e AssociationElement[L, R], err error) {
+	return pageBucket(leftBucket, a.definition.hashedLeft, number, limit, reverse, func(l, r []byte) (e AssociationElement[L, R], err error) {
 		left, err := a.definition.leftEncoding.Decode(l)
 		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, l, err) {
+				return e, errSkipElement
+			}
 			return e, fmt.Errorf("left value: %w", err)
 		}
 
 		right, err := a.definition.rightEncoding.Decode(r)
 		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, l, err) {
+				return e, errSkipElement
+			}
 			return e, fmt.Errorf("decode right: %w", err)
 		}
 
@@ -508,6 +772,9 @@ func (a *Association[L, R]) Page(number, limit int, reverse bool) (s []Associati
 // PageOfLeftValues returns at most a limit of left values at the provided page
 // number.
 func (a *Association[L, R]) PageOfLeftValues(number, limit int, reverse bool) (s []L, totalElements, pages int, err error) {
+	if err := a.checkOrder(a.definition.hashedLeft); err != nil {
+		return nil, 0, 0, err
+	}
 	leftBucket, err := a.leftBucket(false)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("left bucket: %w", err)
@@ -515,14 +782,21 @@ func (a *Association[L, R]) PageOfLeftValues(number, limit int, reverse bool) (s
 	if leftBucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(leftBucket, false, number, limit, reverse, func(l, _ []byte) (left L, err error) {
-		return a.definition.leftEncoding.Decode(l)
+	return pageBucket(leftBucket, a.definition.hashedLeft, number, limit, reverse, func(l, _ []byte) (left L, err error) {
+		left, err = a.definition.leftEncoding.Decode(l)
+		if skipCorrupted(a.definition.corruptedHandler, l, err) {
+			return left, errSkipElement
+		}
+		return left, err
 	})
 }
 
 // PageOfRightValues returns at most a limit of right values at the provided
 // page number.
 func (a *Association[L, R]) PageOfRightValues(number, limit int, reverse bool) (s []R, totalElements, pages int, err error) {
+	if err := a.checkOrder(a.definition.hashedRight); err != nil {
+		return nil, 0, 0, err
+	}
 	rightBucket, err := a.rightBucket(false)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("right bucket: %w", err)
@@ -530,7 +804,11 @@ func (a *Association[L, R]) PageOfRightValues(number, limit int, reverse bool) (
 	if rightBucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(rightBucket, false, number, limit, reverse, func(r, _ []byte) (right R, err error) {
-		return a.definition.rightEncoding.Decode(r)
+	return pageBucket(rightBucket, a.definition.hashedRight, number, limit, reverse, func(r, _ []byte) (right R, err error) {
+		right, err = a.definition.rightEncoding.Decode(r)
+		if skipCorrupted(a.definition.corruptedHandler, r, err) {
+			return right, errSkipElement
+		}
+		return right, err
 	})
 }
@@ -975,6 +975,128 @@ func TestAssociation_customErrKeyExists_and_customErrValueExists(t *testing.T) {
 	})
 }
 
+func TestAssociation_replace(t *testing.T) {
+	db := newNumbersDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		numbers := numbersDefinition.Association(tx)
+
+		replaced, err := numbers.SetAndReplace("one", 2)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", replaced, []boltron.AssociationElement[string, int]{
+			{Left: "one", Right: 1},
+			{Left: "two", Right: 2},
+		})
+
+		// no replacement as values are the same
+		replaced, err = numbers.SetAndReplace("one", 2)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", replaced, nil)
+
+		replaced, err = numbers.SetAndReplace("eight", 8)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", replaced, nil)
+
+		err = numbers.Replace("eight", 1)
+		assertErrorFail(t, "", err, nil)
+
+		err = numbers.Replace("ten", 3)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		numbers := numbersDefinition.Association(tx)
+
+		for _, r := range []struct {
+			L string
+			R int
+		}{
+			{"one", 2},
+			{"eight", 1},
+			{"ten", 3},
+			{"four", 4},
+		} {
+			right, err := numbers.Right(r.L)
+			assertErrorFail(t, fmt.Sprintf("%+v", r), err, nil)
+			assert(t, fmt.Sprintf("%+v", r), right, r.R)
+
+			left, err := numbers.Left(r.R)
+			assertErrorFail(t, fmt.Sprintf("%+v", r), err, nil)
+			assert(t, fmt.Sprintf("%+v", r), left, r.L)
+		}
+
+		for _, l := range []string{"two", "three"} {
+			has, err := numbers.HasLeft(l)
+			assertErrorFail(t, l, err, nil)
+			assert(t, l, has, false)
+		}
+
+		has, err := numbers.HasRight(8)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		size, err := numbers.Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 7)
+	})
+}
+
+func TestAssociation_swap(t *testing.T) {
+	db := newNumbersDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		numbers := numbersDefinition.Association(tx)
+
+		err := numbers.Swap("one", "two")
+		assertErrorFail(t, "", err, nil)
+
+		err = numbers.Swap("three", "three")
+		assertErrorFail(t, "", err, nil)
+
+		err = numbers.Swap("one", "unknown")
+		assertErrorFail(t, "", err, boltron.ErrLeftNotFound)
+
+		err = numbers.Swap("unknown", "one")
+		assertErrorFail(t, "", err, boltron.ErrLeftNotFound)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		numbers := numbersDefinition.Association(tx)
+
+		for _, r := range []struct {
+			L string
+			R int
+		}{
+			{"one", 2},
+			{"two", 1},
+			{"three", 3},
+		} {
+			right, err := numbers.Right(r.L)
+			assertErrorFail(t, fmt.Sprintf("%+v", r), err, nil)
+			assert(t, fmt.Sprintf("%+v", r), right, r.R)
+
+			left, err := numbers.Left(r.R)
+			assertErrorFail(t, fmt.Sprintf("%+v", r), err, nil)
+			assert(t, fmt.Sprintf("%+v", r), left, r.L)
+		}
+
+		size, err := numbers.Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, len(testNumbers))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		db := newDB(t)
+
+		dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+			numbers := numbersDefinition.Association(tx)
+
+			err := numbers.Swap("one", "two")
+			assertErrorFail(t, "", err, boltron.ErrLeftNotFound)
+		})
+	})
+}
+
 func newNumbersDB(t testing.TB) *bolt.DB {
 	t.Helper()
 
@@ -19,16 +19,19 @@ type AssociationsDefinition[A, L, R any] struct {
 	bucketNameLeft         []byte
 	bucketNameRight        []byte
 	bucketNameLeftIndex    []byte
+	bucketNameRightIndex   []byte
 	associationKeyEncoding Encoding[A]
 	leftEncoding           Encoding[L]
 	rightEncoding          Encoding[R]
 	uniqueLeftValues       bool
+	uniqueRightValues      bool
 	fillPercent            float64
 	errAssociationNotFound error
 	errLeftNotFound        error
 	errRightNotFound       error
 	errLeftExists          error
 	errRightExists         error
+	corruptedHandler       func(key []byte, err error)
 }
 
 // AssociationsOptions provides additional configuration for an Association
@@ -40,6 +43,9 @@ type AssociationsOptions struct {
 	// UniqueLeftValues marks if left value can be added only to a single
 	// association.
 	UniqueLeftValues bool
+	// UniqueRightValues marks if right value can be added only to a single
+	// association.
+	UniqueRightValues bool
 	// ErrAssociationNotFound is returned if the association identified by the
 	// key is not found.
 	ErrAssociationNotFound error
@@ -53,8 +59,18 @@ type AssociationsOptions struct {
 	// left value already exists in another association. Also if the left value
 	// exists in the same association.
 	ErrLeftExists error
-	// ErrRightExists is returned if the right value in relation already exists.
+	// ErrRightExists is returned if UniqueRightValues option is set to true and
+	// the right value already exists in another association. Also if the right
+	// value exists in the same association.
 	ErrRightExists error
+	// CorruptedHandler is called with the encoded value of every element that
+	// is skipped by iteration and pagination methods of Associations and of
+	// every Association because it can not be decoded with ErrCorrupted error,
+	// as AssociationOptions CorruptedHandler is for a single Association. If it
+	// is nil, iteration is aborted. Skipped elements are still counted in
+	// total elements and pages returned by pagination methods, so a page may
+	// contain fewer elements than the limit.
+	CorruptedHandler func(key []byte, err error)
 }
 
 // NewAssociationsDefinition constructs a new AssociationsDefinition with a
@@ -73,16 +89,19 @@ func NewAssociationsDefinition[A, L, R any](
 		bucketNameLeft:         []byte("boltron: associations: " + name + " left"),
 		bucketNameRight:        []byte("boltron: associations: " + name + " right"),
 		bucketNameLeftIndex:    []byte("boltron: associations: " + name + " left index"),
+		bucketNameRightIndex:   []byte("boltron: associations: " + name + " right index"),
 		associationKeyEncoding: associationKeyEncoding,
 		leftEncoding:           leftEncoding,
 		rightEncoding:          rightEncoding,
 		fillPercent:            o.FillPercent,
 		uniqueLeftValues:       o.UniqueLeftValues,
+		uniqueRightValues:      o.UniqueRightValues,
 		errAssociationNotFound: withDefaultError(o.ErrAssociationNotFound, ErrNotFound),
 		errLeftNotFound:        withDefaultError(o.ErrLeftNotFound, ErrLeftNotFound),
 		errRightNotFound:       withDefaultError(o.ErrRightNotFound, ErrRightNotFound),
 		errLeftExists:          withDefaultError(o.ErrLeftExists, ErrLeftExists),
 		errRightExists:         withDefaultError(o.ErrRightExists, ErrRightExists),
+		corruptedHandler:       o.CorruptedHandler,
 	}
 }
 
@@ -97,11 +116,12 @@ func (d *AssociationsDefinition[A, L, R]) Associations(tx *bolt.Tx) *Association
 
 // Associations provides methods to access and change a set of Associations.
 type Associations[A, L, R any] struct {
-	tx                    *bolt.Tx
-	leftIndexBucketsCache *bolt.Bucket
-	leftBucketsCache      *bolt.Bucket
-	rightBucketsCache     *bolt.Bucket
-	definition            *AssociationsDefinition[A, L, R]
+	tx                     *bolt.Tx
+	leftIndexBucketsCache  *bolt.Bucket
+	rightIndexBucketsCache *bolt.Bucket
+	leftBucketsCache       *bolt.Bucket
+	rightBucketsCache      *bolt.Bucket
+	definition             *AssociationsDefinition[A, L, R]
 }
 
 func (a *Associations[A, L, R]) leftIndexBuckets(create bool) (*bolt.Bucket, error) {
@@ -116,6 +136,79 @@ func (a *Associations[A, L, R]) leftIndexBuckets(create bool) (*bolt.Bucket, err
 	return bucket, nil
 }
 
+// rightIndexBuckets returns the bucket with right values index. Associations
+// that are stored before right values were indexed are added to the index when
+// it is created.
+func (a *Associations[A, L, R]) rightIndexBuckets(create bool) (*bolt.Bucket, error) {
+	if a.rightIndexBucketsCache != nil {
+		return a.rightIndexBucketsCache, nil
+	}
+	bucket, err := rootBucket(a.tx, false, a.definition.bucketNameRightIndex)
+	if err != nil {
+		return nil, err
+	}
+	if bucket == nil && create {
+		bucket, err = a.createRightIndexBuckets()
+		if err != nil {
+			return nil, err
+		}
+	}
+	a.rightIndexBucketsCache = bucket
+	return bucket, nil
+}
+
+// readRightIndexBuckets returns the bucket with right values index for methods
+// that read it. If associations are stored before right values were indexed,
+// the index is created in a writable transaction, while in a read-only
+// transaction ErrIndexNotFound is returned, as results without the index would
+// not be correct.
+func (a *Associations[A, L, R]) readRightIndexBuckets() (*bolt.Bucket, error) {
+	bucket, err := a.rightIndexBuckets(false)
+	if err != nil || bucket != nil {
+		return bucket, err
+	}
+	rightBuckets, err := a.rightBuckets(false)
+	if err != nil {
+		return nil, fmt.Errorf("right buckets: %w", err)
+	}
+	if rightBuckets == nil {
+		return nil, nil
+	}
+	if k, _ := rightBuckets.Cursor().First(); k == nil {
+		return nil, nil
+	}
+	if !a.tx.Writable() {
+		return nil, ErrIndexNotFound
+	}
+	return a.rightIndexBuckets(true)
+}
+
+func (a *Associations[A, L, R]) createRightIndexBuckets() (*bolt.Bucket, error) {
+	rightIndexBuckets, err := a.tx.CreateBucket(a.definition.bucketNameRightIndex)
+	if err != nil {
+		return nil, fmt.Errorf("create right index buckets: %w", err)
+	}
+	rightBuckets, err := a.rightBuckets(false)
+	if err != nil {
+		return nil, fmt.Errorf("right buckets: %w", err)
+	}
+	if rightBuckets == nil {
+		return rightIndexBuckets, nil
+	}
+	if err := rightBuckets.ForEach(func(ak, _ []byte) error {
+		rightBucket := rightBuckets.Bucket(ak)
+		if rightBucket == nil {
+			return nil
+		}
+		return rightBucket.ForEach(func(r, _ []byte) error {
+			return associationsIndexPut(rightIndexBuckets, r, ak, false, nil)
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("index right values: %w", err)
+	}
+	return rightIndexBuckets, nil
+}
+
 func (a *Associations[A, L, R]) leftBuckets(create bool) (*bolt.Bucket, error) {
 	if a.leftBucketsCache != nil {
 		return a.leftBucketsCache, nil
@@ -167,47 +260,44 @@ func (a *Associations[A, L, R]) Association(key A) (association *Association[L,
 			errRightNotFound: a.definition.errRightNotFound,
 			errLeftExists:    a.definition.errLeftExists,
 			errRightExists:   a.definition.errRightExists,
-			setCallback: func(left []byte) error {
+			corruptedHandler: a.definition.corruptedHandler,
+			setCallback: func(left, right []byte) error {
 				leftIndexBuckets, err := a.leftIndexBuckets(true)
 				if err != nil {
 					return fmt.Errorf("left index buckets: %w", err)
 				}
-				leftIndexBucket := leftIndexBuckets.Bucket(left)
-				if leftIndexBucket != nil {
-					if a.definition.uniqueLeftValues {
-						firstKey, _ := leftIndexBucket.Cursor().First()
-						if firstKey != nil && !bytes.Equal(firstKey, ak) {
-							return a.definition.errLeftExists
-						}
-					}
-				} else {
-					b, err := leftIndexBuckets.CreateBucket(left)
-					if err != nil {
-						return fmt.Errorf("create left index bucket: %w", err)
-					}
-					leftIndexBucket = b
+				if err := associationsIndexPut(leftIndexBuckets, left, ak, a.definition.uniqueLeftValues, a.definition.errLeftExists); err != nil {
+					return fmt.Errorf("put left index: %w", err)
+				}
+				rightIndexBuckets, err := a.rightIndexBuckets(true)
+				if err != nil {
+					return fmt.Errorf("right index buckets: %w", err)
+				}
+				if err := associationsIndexPut(rightIndexBuckets, right, ak, a.definition.uniqueRightValues, a.definition.errRightExists); err != nil {
+					return fmt.Errorf("put right index: %w", err)
 				}
-				return leftIndexBucket.Put(ak, nil)
+				return nil
 			},
-			deleteCallback: func(left []byte) error {
+			deleteCallback: func(left, right []byte) error {
 				leftIndexBuckets, err := a.leftIndexBuckets(false)
 				if err != nil {
 					return fmt.Errorf("left index buckets: %w", err)
 				}
-				if leftIndexBuckets == nil {
-					return fmt.Errorf("missing associations left index buckets: %w", a.definition.errLeftNotFound)
+				found, err := associationsIndexDelete(leftIndexBuckets, left, ak)
+				if err != nil {
+					return fmt.Errorf("delete left index: %w", err)
 				}
-				leftIndexBucket := leftIndexBuckets.Bucket(left)
-				if leftIndexBucket == nil {
+				if !found {
 					return fmt.Errorf("missing value in associations left index buckets: %w", a.definition.errLeftNotFound)
 				}
-				if err := leftIndexBucket.Delete(ak); err != nil {
-					return fmt.Errorf("delete value from lists values bucket: %w", err)
+				rightIndexBuckets, err := a.rightIndexBuckets(false)
+				if err != nil {
+					return fmt.Errorf("right index buckets: %w", err)
 				}
-				if leftIndexBuckets.Stats().KeyN == 1 { // stats are updated after the transaction
-					if err := leftIndexBuckets.DeleteBucket(left); err != nil {
-						return fmt.Errorf("delete empty left bucket: %w", err)
-					}
+				// right values may not be indexed if they are stored before
+				// the index was introduced
+				if _, err := associationsIndexDelete(rightIndexBuckets, right, ak); err != nil {
+					return fmt.Errorf("delete right index: %w", err)
 				}
 				return nil
 			},
@@ -262,6 +352,29 @@ func (a *Associations[A, L, R]) HasLeft(left L) (bool, error) {
 	return f != nil, nil
 }
 
+// HasRight returns true if the right value already exists in any Association.
+func (a *Associations[A, L, R]) HasRight(right R) (bool, error) {
+	r, err := a.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return false, fmt.Errorf("encode right: %w", err)
+	}
+
+	rightIndexBuckets, err := a.readRightIndexBuckets()
+	if err != nil {
+		return false, fmt.Errorf("right index buckets: %w", err)
+	}
+	if rightIndexBuckets == nil {
+		return false, nil
+	}
+
+	if rightIndexBuckets.Bucket(r) == nil {
+		return false, nil
+	}
+
+	f, _ := rightIndexBuckets.Bucket(r).Cursor().First()
+	return f != nil, nil
+}
+
 // DeleteAssociation removes the association from the database. If ensure flag
 // is set to true and the key does not exist, configured ErrAssociationNotFound
 // is returned.
@@ -310,23 +423,30 @@ func (a *Associations[A, L, R]) DeleteAssociation(key A, ensure bool) error {
 	}
 
 	if err := leftBucket.ForEach(func(l, _ []byte) error {
-		leftIndexBucket := leftIndexBuckets.Bucket(l)
-		if leftIndexBucket == nil {
-			return nil
-		}
-		if err := leftIndexBucket.Delete(ak); err != nil {
+		if _, err := associationsIndexDelete(leftIndexBuckets, l, ak); err != nil {
 			return fmt.Errorf("delete association key from left index buckets: %w", err)
 		}
-		if leftIndexBucket.Stats().KeyN == 1 { // stats are updated after the transaction
-			if err := leftIndexBuckets.DeleteBucket(l); err != nil {
-				return fmt.Errorf("delete association key from left index buckets: %w", err)
-			}
-		}
 		return nil
 	}); err != nil {
 		return fmt.Errorf("delete association key in left index buckets: %w", err)
 	}
 
+	rightIndexBuckets, err := a.rightIndexBuckets(false)
+	if err != nil {
+		return fmt.Errorf("right index buckets: %w", err)
+	}
+
+	if rightBucket := rightBuckets.Bucket(ak); rightBucket != nil && rightIndexBuckets != nil {
+		if err := rightBucket.ForEach(func(r, _ []byte) error {
+			if _, err := associationsIndexDelete(rightIndexBuckets, r, ak); err != nil {
+				return fmt.Errorf("delete association key from right index buckets: %w", err)
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("delete association key in right index buckets: %w", err)
+		}
+	}
+
 	if err := leftBuckets.DeleteBucket(ak); err != nil {
 		return fmt.Errorf("delete left bucket: %w", err)
 	}
@@ -389,6 +509,11 @@ func (a *Associations[A, L, R]) DeleteLeft(left L, ensure bool) error {
 		return nil
 	}
 
+	rightIndexBuckets, err := a.rightIndexBuckets(false)
+	if err != nil {
+		return fmt.Errorf("right index buckets: %w", err)
+	}
+
 	if leftBuckets != nil && rightBuckets != nil {
 		association := (&AssociationDefinition[L, R]{
 			leftEncoding:     a.definition.leftEncoding,
@@ -400,6 +525,14 @@ func (a *Associations[A, L, R]) DeleteLeft(left L, ensure bool) error {
 		if err := leftIndexBucket.ForEach(func(ak, _ []byte) error {
 			association.leftBucketCache = leftBuckets.Bucket(ak)
 			association.rightBucketCache = rightBuckets.Bucket(ak)
+			if association.leftBucketCache == nil {
+				return nil
+			}
+			if r := association.leftBucketCache.Get(l); r != nil {
+				if _, err := associationsIndexDelete(rightIndexBuckets, r, ak); err != nil {
+					return fmt.Errorf("delete association key from right index buckets: %w", err)
+				}
+			}
 			return association.DeleteByLeft(left, false)
 		}); err != nil {
 			return fmt.Errorf("delete relation in left and right buckets: %w", err)
@@ -413,6 +546,107 @@ func (a *Associations[A, L, R]) DeleteLeft(left L, ensure bool) error {
 	return nil
 }
 
+// DeleteRight removes the right value from all associations that contain it.
+// If ensure flag is set to true and the right value does not exist, configured
+// ErrNotFound is returned.
+func (a *Associations[A, L, R]) DeleteRight(right R, ensure bool) error {
+	r, err := a.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return fmt.Errorf("encode right: %w", err)
+	}
+
+	rightBuckets, err := a.rightBuckets(false)
+	if err != nil {
+		return fmt.Errorf("right buckets: %w", err)
+	}
+	if rightBuckets == nil {
+		if ensure {
+			return a.definition.errRightNotFound
+		}
+		return nil
+	}
+
+	// create the index if associations are stored before right values were
+	// indexed
+	rightIndexBuckets, err := a.rightIndexBuckets(true)
+	if err != nil {
+		return fmt.Errorf("right index buckets: %w", err)
+	}
+
+	rightIndexBucket := rightIndexBuckets.Bucket(r)
+	if rightIndexBucket == nil {
+		if ensure {
+			return a.definition.errRightNotFound
+		}
+		return nil
+	}
+
+	leftBuckets, err := a.leftBuckets(false)
+	if err != nil {
+		return fmt.Errorf("left buckets: %w", err)
+	}
+	if leftBuckets == nil {
+		if ensure {
+			return a.definition.errRightNotFound
+		}
+		return nil
+	}
+
+	leftIndexBuckets, err := a.leftIndexBuckets(false)
+	if err != nil {
+		return fmt.Errorf("left index buckets: %w", err)
+	}
+
+	association := (&AssociationDefinition[L, R]{
+		leftEncoding:     a.definition.leftEncoding,
+		rightEncoding:    a.definition.rightEncoding,
+		errLeftNotFound:  a.definition.errLeftNotFound,
+		errRightNotFound: a.definition.errRightNotFound,
+	}).Association(nil)
+
+	if err := rightIndexBucket.ForEach(func(ak, _ []byte) error {
+		association.leftBucketCache = leftBuckets.Bucket(ak)
+		association.rightBucketCache = rightBuckets.Bucket(ak)
+		if association.rightBucketCache == nil {
+			return nil
+		}
+		if l := association.rightBucketCache.Get(r); l != nil {
+			if _, err := associationsIndexDelete(leftIndexBuckets, l, ak); err != nil {
+				return fmt.Errorf("delete association key from left index buckets: %w", err)
+			}
+		}
+		return association.DeleteByRight(right, false)
+	}); err != nil {
+		return fmt.Errorf("delete relation in left and right buckets: %w", err)
+	}
+
+	if err := rightIndexBuckets.DeleteBucket(r); err != nil {
+		return fmt.Errorf("delete right value bucket from right index buckets: %w", err)
+	}
+
+	return nil
+}
+
+// RebuildRightIndex recreates the index of right values from all associations.
+// Associations that are stored before right values were indexed are added to
+// the index on the first change of any association, on DeleteRight, or when
+// right values are read in a writable transaction. Until then, HasRight and
+// iteration and pagination over right values return ErrIndexNotFound in
+// read-only transactions. This method can be used to index them without
+// changing any association.
+func (a *Associations[A, L, R]) RebuildRightIndex() error {
+	if a.tx.Bucket(a.definition.bucketNameRightIndex) != nil {
+		if err := a.tx.DeleteBucket(a.definition.bucketNameRightIndex); err != nil {
+			return fmt.Errorf("delete right index buckets: %w", err)
+		}
+	}
+	a.rightIndexBucketsCache = nil
+	if _, err := a.rightIndexBuckets(true); err != nil {
+		return fmt.Errorf("right index buckets: %w", err)
+	}
+	return nil
+}
+
 // Size returns the number of associations.
 func (a *Associations[A, L, R]) Size() (int, error) {
 	leftBuckets, err := a.leftBuckets(false)
@@ -436,9 +670,12 @@ func (a *Associations[A, L, R]) IterateAssociations(start *A, reverse bool, f fu
 	if leftbuckets == nil {
 		return nil, nil
 	}
-	return iterateKeys(leftbuckets, a.definition.associationKeyEncoding, start, reverse, func(ak, _ []byte) (bool, error) {
+	return iterateKeys(leftbuckets, a.definition.associationKeyEncoding, a.definition.corruptedHandler, start, reverse, func(ak, _ []byte) (bool, error) {
 		key, err := a.definition.associationKeyEncoding.Decode(ak)
 		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, ak, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode association key: %w", err)
 		}
 
@@ -456,8 +693,12 @@ func (a *Associations[A, L, R]) PageOfAssociations(number, limit int, reverse bo
 	if leftBuckets == nil {
 		return nil, 0, 0, nil
 	}
-	return page(leftBuckets, true, number, limit, reverse, func(ak, _ []byte) (A, error) {
-		return a.definition.associationKeyEncoding.Decode(ak)
+	return page(leftBuckets, true, number, limit, reverse, func(ak, _ []byte) (e A, err error) {
+		e, err = a.definition.associationKeyEncoding.Decode(ak)
+		if skipCorrupted(a.definition.corruptedHandler, ak, err) {
+			return e, errSkipElement
+		}
+		return e, err
 	})
 }
 
@@ -481,9 +722,12 @@ func (a *Associations[A, L, R]) IterateAssociationsWithLeftValue(left L, start *
 	if leftIndexBucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(leftIndexBucket, a.definition.associationKeyEncoding, start, reverse, func(ak, _ []byte) (bool, error) {
+	return iterateKeys(leftIndexBucket, a.definition.associationKeyEncoding, a.definition.corruptedHandler, start, reverse, func(ak, _ []byte) (bool, error) {
 		key, err := a.definition.associationKeyEncoding.Decode(ak)
 		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, ak, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode association key: %w", err)
 		}
 
@@ -509,8 +753,12 @@ func (a *Associations[A, L, R]) PageOfAssociationsWithLeftValue(left L, number,
 	if leftIndexBucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(leftIndexBucket, false, number, limit, reverse, func(k, _ []byte) (A, error) {
-		return a.definition.associationKeyEncoding.Decode(k)
+	return page(leftIndexBucket, false, number, limit, reverse, func(k, _ []byte) (e A, err error) {
+		e, err = a.definition.associationKeyEncoding.Decode(k)
+		if skipCorrupted(a.definition.corruptedHandler, k, err) {
+			return e, errSkipElement
+		}
+		return e, err
 	})
 }
 
@@ -525,9 +773,12 @@ func (a *Associations[A, L, R]) IterateLeftValues(start *L, reverse bool, f func
 	if leftIndexBuckets == nil {
 		return nil, nil
 	}
-	return iterateKeys(leftIndexBuckets, a.definition.leftEncoding, start, reverse, func(l, _ []byte) (bool, error) {
+	return iterateKeys(leftIndexBuckets, a.definition.leftEncoding, a.definition.corruptedHandler, start, reverse, func(l, _ []byte) (bool, error) {
 		left, err := a.definition.leftEncoding.Decode(l)
 		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, l, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode left: %w", err)
 		}
 
@@ -545,7 +796,158 @@ func (a *Associations[A, L, R]) PageOfLeftValues(number, limit int, reverse bool
 	if leftIndexBuckets == nil {
 		return nil, 0, 0, nil
 	}
-	return page(leftIndexBuckets, true, number, limit, reverse, func(l, _ []byte) (L, error) {
-		return a.definition.leftEncoding.Decode(l)
+	return page(leftIndexBuckets, true, number, limit, reverse, func(l, _ []byte) (e L, err error) {
+		e, err = a.definition.leftEncoding.Decode(l)
+		if skipCorrupted(a.definition.corruptedHandler, l, err) {
+			return e, errSkipElement
+		}
+		return e, err
+	})
+}
+
+// IterateAssociationsWithRightValue iterates over Association keys that
+// contain the provided right value in the lexicographical order of keys. If the
+// callback function f returns false, the iteration stops and the next can be
+// used to continue the iteration.
+func (a *Associations[A, L, R]) IterateAssociationsWithRightValue(right R, start *A, reverse bool, f func(A) (bool, error)) (next *A, err error) {
+	r, err := a.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return nil, fmt.Errorf("encode right: %w", err)
+	}
+	rightIndexBuckets, err := a.readRightIndexBuckets()
+	if err != nil {
+		return nil, fmt.Errorf("right index buckets: %w", err)
+	}
+	if rightIndexBuckets == nil {
+		return nil, nil
+	}
+	rightIndexBucket := rightIndexBuckets.Bucket(r)
+	if rightIndexBucket == nil {
+		return nil, nil
+	}
+	return iterateKeys(rightIndexBucket, a.definition.associationKeyEncoding, a.definition.corruptedHandler, start, reverse, func(ak, _ []byte) (bool, error) {
+		key, err := a.definition.associationKeyEncoding.Decode(ak)
+		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, ak, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode association key: %w", err)
+		}
+
+		return f(key)
+	})
+}
+
+// PageOfAssociationsWithRightValue returns at most a limit of Association keys
+// that contain the provided right value at the provided page number.
+func (a *Associations[A, L, R]) PageOfAssociationsWithRightValue(right R, number, limit int, reverse bool) (s []A, totalElements, pages int, err error) {
+	r, err := a.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("encode right: %w", err)
+	}
+	rightIndexBuckets, err := a.readRightIndexBuckets()
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("right index buckets: %w", err)
+	}
+	if rightIndexBuckets == nil {
+		return nil, 0, 0, nil
+	}
+	rightIndexBucket := rightIndexBuckets.Bucket(r)
+	if rightIndexBucket == nil {
+		return nil, 0, 0, nil
+	}
+	return page(rightIndexBucket, false, number, limit, reverse, func(k, _ []byte) (e A, err error) {
+		e, err = a.definition.associationKeyEncoding.Decode(k)
+		if skipCorrupted(a.definition.corruptedHandler, k, err) {
+			return e, errSkipElement
+		}
+		return e, err
+	})
+}
+
+// IterateRightValues iterates over all right values in the lexicographical
+// order of right values. If the callback function f returns false, the
+// iteration stops and the next can be used to continue the iteration.
+func (a *Associations[A, L, R]) IterateRightValues(start *R, reverse bool, f func(R) (bool, error)) (next *R, err error) {
+	rightIndexBuckets, err := a.readRightIndexBuckets()
+	if err != nil {
+		return nil, fmt.Errorf("right index buckets: %w", err)
+	}
+	if rightIndexBuckets == nil {
+		return nil, nil
+	}
+	return iterateKeys(rightIndexBuckets, a.definition.rightEncoding, a.definition.corruptedHandler, start, reverse, func(r, _ []byte) (bool, error) {
+		right, err := a.definition.rightEncoding.Decode(r)
+		if err != nil {
+			if skipCorrupted(a.definition.corruptedHandler, r, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode right: %w", err)
+		}
+
+		return f(right)
+	})
+}
+
+// PageOfRightValues returns at most a limit of right values at the provided
+// page number.
+func (a *Associations[A, L, R]) PageOfRightValues(number, limit int, reverse bool) (s []R, totalElements, pages int, err error) {
+	rightIndexBuckets, err := a.readRightIndexBuckets()
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("right index buckets: %w", err)
+	}
+	if rightIndexBuckets == nil {
+		return nil, 0, 0, nil
+	}
+	return page(rightIndexBuckets, true, number, limit, reverse, func(r, _ []byte) (e R, err error) {
+		e, err = a.definition.rightEncoding.Decode(r)
+		if skipCorrupted(a.definition.corruptedHandler, r, err) {
+			return e, errSkipElement
+		}
+		return e, err
 	})
 }
+
+// associationsIndexPut adds the association key to the index bucket of the
+// left or right value. If unique flag is set and the value is already in
+// another association, errExists is returned.
+func associationsIndexPut(indexBuckets *bolt.Bucket, v, ak []byte, unique bool, errExists error) error {
+	indexBucket := indexBuckets.Bucket(v)
+	if indexBucket != nil {
+		if unique {
+			firstKey, _ := indexBucket.Cursor().First()
+			if firstKey != nil && !bytes.Equal(firstKey, ak) {
+				return errExists
+			}
+		}
+	} else {
+		b, err := indexBuckets.CreateBucket(v)
+		if err != nil {
+			return fmt.Errorf("create index bucket: %w", err)
+		}
+		indexBucket = b
+	}
+	return indexBucket.Put(ak, nil)
+}
+
+// associationsIndexDelete removes the association key from the index bucket of
+// the left or right value and deletes the bucket if it becomes empty. It
+// returns false if the value is not in the index.
+func associationsIndexDelete(indexBuckets *bolt.Bucket, v, ak []byte) (found bool, err error) {
+	if indexBuckets == nil {
+		return false, nil
+	}
+	indexBucket := indexBuckets.Bucket(v)
+	if indexBucket == nil {
+		return false, nil
+	}
+	if err := indexBucket.Delete(ak); err != nil {
+		return false, fmt.Errorf("delete association key: %w", err)
+	}
+	if k, _ := indexBucket.Cursor().First(); k == nil {
+		if err := indexBuckets.DeleteBucket(v); err != nil {
+			return false, fmt.Errorf("delete empty index bucket: %w", err)
+		}
+	}
+	return true, nil
+}
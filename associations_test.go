@@ -1072,6 +1072,470 @@ func TestAssociations_uniqueKeys_customErrKeyExists(t *testing.T) {
 	})
 }
 
+func TestAssociations_rightValues(t *testing.T) {
+	db := ballotsDB(t)
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		for _, b := range testBallots {
+			has, err := ballots.HasRight(b.BallotID)
+			assertErrorFail(t, fmt.Sprintf("%+v", b), err, nil)
+			assert(t, fmt.Sprintf("%+v", b), has, true)
+		}
+
+		has, err := ballots.HasRight(100)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		var rights []uint64
+		next, err := ballots.IterateRightValues(nil, false, func(r uint64) (bool, error) {
+			rights = append(rights, r)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", next, nil)
+		assert(t, "", rights, []uint64{0, 1, 2, 3, 4, 5, 6})
+
+		rights = nil
+		next, err = ballots.IterateRightValues(nil, true, func(r uint64) (bool, error) {
+			rights = append(rights, r)
+			return len(rights) < 3, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", *next, 3)
+		assert(t, "", rights, []uint64{6, 5, 4})
+
+		page, totalElements, totalPages, err := ballots.PageOfRightValues(2, 3, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", page, []uint64{3, 4, 5})
+		assert(t, "", totalElements, 7)
+		assert(t, "", totalPages, 3)
+
+		var associations []uint64
+		next, err = ballots.IterateAssociationsWithRightValue(2, nil, false, func(a uint64) (bool, error) {
+			associations = append(associations, a)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", next, nil)
+		assert(t, "", associations, []uint64{1, 3, 6})
+
+		associations = nil
+		next, err = ballots.IterateAssociationsWithRightValue(1, nil, true, func(a uint64) (bool, error) {
+			associations = append(associations, a)
+			return len(associations) < 2, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", *next, 3)
+		assert(t, "", associations, []uint64{7, 6})
+
+		next, err = ballots.IterateAssociationsWithRightValue(100, nil, false, func(a uint64) (bool, error) {
+			t.Errorf("unexpected association %v", a)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", next, nil)
+
+		associationsPage, totalElements, totalPages, err := ballots.PageOfAssociationsWithRightValue(0, 1, 3, true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", associationsPage, []uint64{7, 6, 3})
+		assert(t, "", totalElements, 4)
+		assert(t, "", totalPages, 2)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		err := ballots.DeleteRight(100, true)
+		assertErrorFail(t, "", err, boltron.ErrRightNotFound)
+
+		err = ballots.DeleteRight(100, false)
+		assertErrorFail(t, "", err, nil)
+
+		err = ballots.DeleteRight(2, true)
+		assertErrorFail(t, "", err, nil)
+
+		has, err := ballots.HasRight(2)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		// edit had ballot 2 in voting 6 and still has ballot 3 in voting 1
+		var associations []uint64
+		_, err = ballots.IterateAssociationsWithLeftValue("edit", nil, false, func(a uint64) (bool, error) {
+			associations = append(associations, a)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", associations, []uint64{1})
+
+		ballot, _, err := ballots.Association(3)
+		assertErrorFail(t, "", err, nil)
+
+		has, err = ballot.HasLeft("dave")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		err := ballots.DeleteLeft("paul", true)
+		assertErrorFail(t, "", err, nil)
+
+		has, err := ballots.HasRight(5)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		ballot, _, err := ballots.Association(6)
+		assertErrorFail(t, "", err, nil)
+
+		err = ballot.DeleteByRight(6, true)
+		assertErrorFail(t, "", err, nil)
+
+		has, err = ballots.HasRight(6)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		has, err = ballots.HasLeft("john")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		err = ballots.DeleteAssociation(6, true)
+		assertErrorFail(t, "", err, nil)
+
+		var associations []uint64
+		_, err = ballots.IterateAssociationsWithRightValue(4, nil, false, func(a uint64) (bool, error) {
+			associations = append(associations, a)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", associations, []uint64{1})
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		var rights []uint64
+		_, err := ballots.IterateRightValues(nil, false, func(r uint64) (bool, error) {
+			rights = append(rights, r)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", rights, []uint64{0, 1, 3, 4})
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		db := newDB(t)
+
+		dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+			ballots := ballotsDefinition.Associations(tx)
+
+			has, err := ballots.HasRight(1)
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", has, false)
+
+			next, err := ballots.IterateRightValues(nil, false, func(r uint64) (bool, error) {
+				t.Errorf("unexpected right value %v", r)
+				return true, nil
+			})
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", next, nil)
+
+			page, totalElements, totalPages, err := ballots.PageOfRightValues(1, 3, false)
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", page, nil)
+			assert(t, "", totalElements, 0)
+			assert(t, "", totalPages, 0)
+		})
+
+		dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+			ballots := ballotsDefinition.Associations(tx)
+
+			err := ballots.DeleteRight(1, true)
+			assertErrorFail(t, "", err, boltron.ErrRightNotFound)
+		})
+	})
+}
+
+func TestAssociations_missingRightIndex(t *testing.T) {
+	db := ballotsDB(t)
+
+	// remove the index to simulate associations stored before right values
+	// were indexed
+	dropIndex := func(t testing.TB, tx *bolt.Tx) {
+		t.Helper()
+
+		err := tx.DeleteBucket([]byte("boltron: associations: ballots right index"))
+		assertErrorFail(t, "", err, nil)
+	}
+
+	dbUpdate(t, db, dropIndex)
+
+	rightValueAssociations := func(t testing.TB, ballots *boltron.Associations[uint64, string, uint64], right uint64) (associations []uint64) {
+		t.Helper()
+
+		_, err := ballots.IterateAssociationsWithRightValue(right, nil, false, func(a uint64) (bool, error) {
+			associations = append(associations, a)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		return associations
+	}
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		_, err := ballots.HasRight(1)
+		assertError(t, "", err, boltron.ErrIndexNotFound)
+
+		_, err = ballots.IterateRightValues(nil, false, func(uint64) (bool, error) {
+			return true, nil
+		})
+		assertError(t, "", err, boltron.ErrIndexNotFound)
+
+		_, _, _, err = ballots.PageOfAssociationsWithRightValue(1, 1, 10, false)
+		assertError(t, "", err, boltron.ErrIndexNotFound)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		ballot, _, err := ballots.Association(1)
+		assertErrorFail(t, "", err, nil)
+
+		err = ballot.DeleteByLeft("alice", true)
+		assertErrorFail(t, "", err, nil)
+
+		err = ballots.DeleteAssociation(3, true)
+		assertErrorFail(t, "", err, nil)
+
+		err = ballots.DeleteLeft("john", true)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		ballot, _, err := ballots.Association(7)
+		assertErrorFail(t, "", err, nil)
+
+		err = ballot.Set("mick", 9)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		assert(t, "", rightValueAssociations(t, ballots, 1), []uint64{6, 7})
+		assert(t, "", rightValueAssociations(t, ballots, 9), []uint64{7})
+		assert(t, "", rightValueAssociations(t, ballots, 6), nil)
+	})
+
+	dbUpdate(t, db, dropIndex)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		err := ballots.DeleteRight(2, true)
+		assertErrorFail(t, "", err, nil)
+
+		has, err := ballots.HasLeft("edit")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		assert(t, "", rightValueAssociations(t, ballots, 0), []uint64{1, 6, 7})
+	})
+
+	dbUpdate(t, db, dropIndex)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		// the index is created when it is read in a writable transaction
+		has, err := ballots.HasRight(9)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+	})
+
+	dbUpdate(t, db, dropIndex)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		err := ballots.RebuildRightIndex()
+		assertErrorFail(t, "", err, nil)
+
+		err = ballots.RebuildRightIndex()
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		var rights []uint64
+		_, err := ballots.IterateRightValues(nil, false, func(r uint64) (bool, error) {
+			rights = append(rights, r)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", rights, []uint64{0, 1, 3, 4, 5, 9})
+
+		assert(t, "", rightValueAssociations(t, ballots, 0), []uint64{1, 6, 7})
+	})
+}
+
+func TestAssociations_uniqueRightValues(t *testing.T) {
+
+	customBallotsDefinition := boltron.NewAssociationsDefinition(
+		"ballots",
+		boltron.Uint64BinaryEncoding,       // voting id
+		boltron.StringNaturalOrderEncoding, // voter
+		boltron.Uint64Base36Encoding,       // ballot serial number
+		&boltron.AssociationsOptions{
+			UniqueRightValues: true,
+		},
+	)
+
+	db := newDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := customBallotsDefinition.Associations(tx)
+
+		election0, _, err := ballots.Association(0)
+		assertErrorFail(t, "", err, nil)
+
+		election1, _, err := ballots.Association(1)
+		assertErrorFail(t, "", err, nil)
+
+		err = election0.Set("john", 1000)
+		assertErrorFail(t, "", err, nil)
+
+		err = election1.Set("john", 1001)
+		assertErrorFail(t, "", err, nil)
+
+		err = election1.Set("paul", 1000)
+		assertErrorFail(t, "", err, boltron.ErrRightExists)
+	})
+}
+
+func TestAssociations_replaceAndSwap(t *testing.T) {
+	db := ballotsDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		ballot1, _, err := ballots.Association(1)
+		assertErrorFail(t, "", err, nil)
+
+		replaced, err := ballot1.SetAndReplace("alice", 2)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", replaced, []boltron.AssociationElement[string, uint64]{
+			{Left: "alice", Right: 1},
+			{Left: "bob", Right: 2},
+		})
+
+		ballot6, _, err := ballots.Association(6)
+		assertErrorFail(t, "", err, nil)
+
+		err = ballot6.Swap("paul", "john")
+		assertErrorFail(t, "", err, nil)
+
+		ballot7, _, err := ballots.Association(7)
+		assertErrorFail(t, "", err, nil)
+
+		err = ballot7.Replace("mick", 0)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		ballots := ballotsDefinition.Associations(tx)
+
+		for _, a := range []struct {
+			Left         string
+			Associations []uint64
+		}{
+			{"alice", []uint64{1, 3, 7}},
+			{"bob", []uint64{3, 6}},
+			{"dave", []uint64{1, 3, 6}},
+			{"mick", []uint64{3, 7}},
+			{"paul", []uint64{6}},
+			{"john", []uint64{6}},
+		} {
+			var associations []uint64
+			_, err := ballots.IterateAssociationsWithLeftValue(a.Left, nil, false, func(k uint64) (bool, error) {
+				associations = append(associations, k)
+				return true, nil
+			})
+			assertErrorFail(t, fmt.Sprintf("%+v", a), err, nil)
+			assert(t, fmt.Sprintf("%+v", a), associations, a.Associations)
+		}
+
+		for _, a := range []struct {
+			Right        uint64
+			Associations []uint64
+		}{
+			{0, []uint64{1, 3, 6, 7}},
+			{1, []uint64{3, 6, 7}},
+			{2, []uint64{1, 3, 6}},
+			{5, []uint64{6}},
+			{6, []uint64{6}},
+		} {
+			var associations []uint64
+			_, err := ballots.IterateAssociationsWithRightValue(a.Right, nil, false, func(k uint64) (bool, error) {
+				associations = append(associations, k)
+				return true, nil
+			})
+			assertErrorFail(t, fmt.Sprintf("%+v", a), err, nil)
+			assert(t, fmt.Sprintf("%+v", a), associations, a.Associations)
+		}
+
+		ballot6, _, err := ballots.Association(6)
+		assertErrorFail(t, "", err, nil)
+
+		right, err := ballot6.Right("paul")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", right, 6)
+
+		right, err = ballot6.Right("john")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", right, 5)
+	})
+
+	t.Run("unique right values", func(t *testing.T) {
+		customBallotsDefinition := boltron.NewAssociationsDefinition(
+			"ballots",
+			boltron.Uint64BinaryEncoding,       // voting id
+			boltron.StringNaturalOrderEncoding, // voter
+			boltron.Uint64Base36Encoding,       // ballot serial number
+			&boltron.AssociationsOptions{
+				UniqueRightValues: true,
+			},
+		)
+
+		db := newDB(t)
+
+		dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+			ballots := customBallotsDefinition.Associations(tx)
+
+			election0, _, err := ballots.Association(0)
+			assertErrorFail(t, "", err, nil)
+
+			election1, _, err := ballots.Association(1)
+			assertErrorFail(t, "", err, nil)
+
+			err = election0.Set("john", 1000)
+			assertErrorFail(t, "", err, nil)
+
+			err = election0.Replace("john", 1001)
+			assertErrorFail(t, "", err, nil)
+
+			err = election1.Replace("paul", 1001)
+			assertErrorFail(t, "", err, boltron.ErrRightExists)
+		})
+	})
+}
+
 func ballotsDB(t testing.TB) *bolt.DB {
 	t.Helper()
 
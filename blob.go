@@ -0,0 +1,625 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultBlobChunkSize is the size of blob chunks if it is not set in
+// BlobStoreOptions.
+const DefaultBlobChunkSize = 32 * 1024
+
+// blobMetadataLen is the length of the fixed part of the encoded BlobMetadata,
+// size, chunk size, chunks generation and SHA-256 checksum, that is followed by
+// the content type.
+const blobMetadataLen = 8 + 4 + 8 + sha256.Size
+
+// BlobStoreDefinition defines a store of large binary values, identified by
+// unique keys. Values are split into chunks of a fixed size stored in a nested
+// bucket, which avoids bolt limits on value sizes and keeps pages compact.
+// Every write of a blob stores its chunks in a new generation bucket under the
+// blob bucket, and the metadata references the generation of the current
+// content.
+type BlobStoreDefinition[K any] struct {
+	bucketNameChunks   []byte
+	bucketNameMetadata []byte
+	keyEncoding        Encoding[K]
+	chunkSize          int
+	fillPercent        float64
+	errNotFound        error
+}
+
+// BlobStoreOptions provides additional configuration for a BlobStore.
+type BlobStoreOptions struct {
+	// ChunkSize is the maximal length of a single chunk in bytes. If it is 0,
+	// DefaultBlobChunkSize is used. Changing the chunk size does not affect
+	// the already stored blobs.
+	ChunkSize int
+	// FillPercent is the value for the bolt bucket fill percent.
+	FillPercent float64
+	// ErrNotFound is returned if the blob is not found.
+	ErrNotFound error
+}
+
+// NewBlobStoreDefinition constructs a new BlobStoreDefinition with a unique
+// name and key encoding.
+func NewBlobStoreDefinition[K any](
+	name string,
+	keyEncoding Encoding[K],
+	o *BlobStoreOptions,
+) *BlobStoreDefinition[K] {
+	if o == nil {
+		o = new(BlobStoreOptions)
+	}
+	chunkSize := o.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultBlobChunkSize
+	}
+	return &BlobStoreDefinition[K]{
+		bucketNameChunks:   []byte("boltron: blob store: " + name + " chunks"),
+		bucketNameMetadata: []byte("boltron: blob store: " + name + " metadata"),
+		keyEncoding:        keyEncoding,
+		chunkSize:          chunkSize,
+		fillPercent:        o.FillPercent,
+		errNotFound:        withDefaultError(o.ErrNotFound, ErrNotFound),
+	}
+}
+
+// BlobStore returns a BlobStore that has access to the stored data through the
+// bolt transaction.
+func (d *BlobStoreDefinition[K]) BlobStore(tx *bolt.Tx) *BlobStore[K] {
+	return &BlobStore[K]{
+		tx:         tx,
+		definition: d,
+	}
+}
+
+// BlobStore provides methods to store and read large binary values.
+type BlobStore[K any] struct {
+	tx                  *bolt.Tx
+	chunksBucketCache   *bolt.Bucket
+	metadataBucketCache *bolt.Bucket
+	definition          *BlobStoreDefinition[K]
+}
+
+func (s *BlobStore[K]) chunksBucket(create bool) (*bolt.Bucket, error) {
+	if s.chunksBucketCache != nil {
+		return s.chunksBucketCache, nil
+	}
+	bucket, err := rootBucket(s.tx, create, s.definition.bucketNameChunks)
+	if err != nil {
+		return nil, err
+	}
+	s.chunksBucketCache = bucket
+	return bucket, nil
+}
+
+func (s *BlobStore[K]) metadataBucket(create bool) (*bolt.Bucket, error) {
+	if s.metadataBucketCache != nil {
+		return s.metadataBucketCache, nil
+	}
+	bucket, err := rootBucket(s.tx, create, s.definition.bucketNameMetadata)
+	if err != nil {
+		return nil, err
+	}
+	if s.definition.fillPercent > 0 && bucket != nil {
+		bucket.FillPercent = s.definition.fillPercent
+	}
+	s.metadataBucketCache = bucket
+	return bucket, nil
+}
+
+// BlobMetadata contains information about the stored blob.
+type BlobMetadata struct {
+	// Size is the length of the blob in bytes.
+	Size int64 `json:"size"`
+	// ContentType is an arbitrary media type set when the blob is created.
+	ContentType string `json:"contentType,omitempty"`
+	// SHA256 is the SHA-256 checksum of the blob data.
+	SHA256 []byte `json:"sha256"`
+
+	chunkSize  int
+	generation uint64
+}
+
+func encodeBlobMetadata(m BlobMetadata) []byte {
+	b := make([]byte, blobMetadataLen, blobMetadataLen+len(m.ContentType))
+	binary.BigEndian.PutUint64(b[:8], uint64(m.Size))
+	binary.BigEndian.PutUint32(b[8:12], uint32(m.chunkSize))
+	binary.BigEndian.PutUint64(b[12:20], m.generation)
+	copy(b[20:blobMetadataLen], m.SHA256)
+	return append(b, m.ContentType...)
+}
+
+func decodeBlobMetadata(b []byte) (m BlobMetadata, err error) {
+	if l := len(b); l < blobMetadataLen {
+		return m, fmt.Errorf("invalid encoded blob metadata length %v", l)
+	}
+	return BlobMetadata{
+		Size:        int64(binary.BigEndian.Uint64(b[:8])),
+		ContentType: string(b[blobMetadataLen:]),
+		SHA256:      append([]byte(nil), b[20:blobMetadataLen]...),
+		chunkSize:   int(binary.BigEndian.Uint32(b[8:12])),
+		generation:  binary.BigEndian.Uint64(b[12:20]),
+	}, nil
+}
+
+// Has returns true if the blob with the key already exists in the database.
+func (s *BlobStore[K]) Has(key K) (bool, error) {
+	k, err := s.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return false, fmt.Errorf("encode key: %w", err)
+	}
+	metadataBucket, err := s.metadataBucket(false)
+	if err != nil {
+		return false, fmt.Errorf("metadata bucket: %w", err)
+	}
+	if metadataBucket == nil {
+		return false, nil
+	}
+	return metadataBucket.Get(k) != nil, nil
+}
+
+// Metadata returns the metadata of the blob. If the blob does not exist,
+// configured ErrNotFound is returned.
+func (s *BlobStore[K]) Metadata(key K) (m BlobMetadata, err error) {
+	k, err := s.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return m, fmt.Errorf("encode key: %w", err)
+	}
+	return s.metadata(k)
+}
+
+func (s *BlobStore[K]) metadata(k []byte) (m BlobMetadata, err error) {
+	metadataBucket, err := s.metadataBucket(false)
+	if err != nil {
+		return m, fmt.Errorf("metadata bucket: %w", err)
+	}
+	if metadataBucket == nil {
+		return m, s.definition.errNotFound
+	}
+	v := metadataBucket.Get(k)
+	if v == nil {
+		return m, s.definition.errNotFound
+	}
+	m, err = decodeBlobMetadata(v)
+	if err != nil {
+		return m, fmt.Errorf("decode metadata: %w", err)
+	}
+	return m, nil
+}
+
+// Put stores the data read from the reader until io.EOF as a blob with the key
+// replacing the existing blob if it exists.
+func (s *BlobStore[K]) Put(key K, r io.Reader, contentType string) (m BlobMetadata, err error) {
+	w, err := s.Create(key, contentType)
+	if err != nil {
+		return m, err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return m, fmt.Errorf("copy: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return m, err
+	}
+	return w.Metadata(), nil
+}
+
+// Create returns a BlobWriter that stores all written data as a blob with the
+// key replacing the existing blob if it exists. The blob is saved only when the
+// BlobWriter is closed, until then the existing blob is not changed. Written
+// chunks are stored in a new generation of the blob chunks, and the previous
+// generation is deleted on Close.
+func (s *BlobStore[K]) Create(key K, contentType string) (*BlobWriter, error) {
+	k, err := s.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return nil, fmt.Errorf("encode key: %w", err)
+	}
+
+	chunksBucket, err := s.chunksBucket(true)
+	if err != nil {
+		return nil, fmt.Errorf("chunks bucket: %w", err)
+	}
+	metadataBucket, err := s.metadataBucket(true)
+	if err != nil {
+		return nil, fmt.Errorf("metadata bucket: %w", err)
+	}
+	blobBucket, err := chunksBucket.CreateBucketIfNotExists(k)
+	if err != nil {
+		return nil, fmt.Errorf("create blob bucket: %w", err)
+	}
+
+	var current []byte
+	if v := metadataBucket.Get(k); v != nil {
+		m, err := decodeBlobMetadata(v)
+		if err != nil {
+			return nil, fmt.Errorf("decode metadata: %w", err)
+		}
+		current = encodeChunkIndex(m.generation)
+	}
+	// remove chunks of previous writers that were not closed
+	var abandoned [][]byte
+	if err := blobBucket.ForEach(func(g, _ []byte) error {
+		if !bytes.Equal(g, current) {
+			abandoned = append(abandoned, append([]byte(nil), g...))
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("blob generations: %w", err)
+	}
+	for _, g := range abandoned {
+		if err := blobBucket.DeleteBucket(g); err != nil {
+			return nil, fmt.Errorf("delete blob generation bucket: %w", err)
+		}
+	}
+
+	generation, err := blobBucket.NextSequence()
+	if err != nil {
+		return nil, fmt.Errorf("blob generation: %w", err)
+	}
+	bucket, err := blobBucket.CreateBucket(encodeChunkIndex(generation))
+	if err != nil {
+		return nil, fmt.Errorf("create blob generation bucket: %w", err)
+	}
+	bucket.FillPercent = 1 // chunks are always appended
+
+	return &BlobWriter{
+		key:            k,
+		generation:     generation,
+		current:        current,
+		bucket:         bucket,
+		blobBucket:     blobBucket,
+		metadataBucket: metadataBucket,
+		contentType:    contentType,
+		chunkSize:      s.definition.chunkSize,
+		buf:            make([]byte, 0, s.definition.chunkSize),
+		hash:           sha256.New(),
+	}, nil
+}
+
+// Open returns a BlobReader for reading the blob data. If the blob does not
+// exist, configured ErrNotFound is returned. The reader is valid only during
+// the transaction.
+func (s *BlobStore[K]) Open(key K) (*BlobReader, error) {
+	k, err := s.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return nil, fmt.Errorf("encode key: %w", err)
+	}
+	return s.open(k)
+}
+
+func (s *BlobStore[K]) open(k []byte) (*BlobReader, error) {
+	m, err := s.metadata(k)
+	if err != nil {
+		return nil, err
+	}
+	chunksBucket, err := s.chunksBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("chunks bucket: %w", err)
+	}
+	if chunksBucket == nil {
+		return nil, errors.New("chunks bucket does not exist")
+	}
+	blobBucket := chunksBucket.Bucket(k)
+	if blobBucket == nil {
+		return nil, errors.New("blob bucket does not exist")
+	}
+	bucket := blobBucket.Bucket(encodeChunkIndex(m.generation))
+	if bucket == nil {
+		return nil, errors.New("blob generation bucket does not exist")
+	}
+	return &BlobReader{
+		bucket:   bucket,
+		metadata: m,
+	}, nil
+}
+
+// Delete removes the blob and its metadata from the database. If ensure flag is
+// set to true and the blob does not exist, configured ErrNotFound is returned.
+func (s *BlobStore[K]) Delete(key K, ensure bool) error {
+	k, err := s.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+	if ensure {
+		has, err := s.Has(key)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return s.definition.errNotFound
+		}
+	}
+	return s.delete(k)
+}
+
+func (s *BlobStore[K]) delete(k []byte) error {
+	chunksBucket, err := s.chunksBucket(false)
+	if err != nil {
+		return fmt.Errorf("chunks bucket: %w", err)
+	}
+	if chunksBucket != nil && chunksBucket.Bucket(k) != nil {
+		if err := chunksBucket.DeleteBucket(k); err != nil {
+			return fmt.Errorf("delete blob bucket: %w", err)
+		}
+	}
+	metadataBucket, err := s.metadataBucket(false)
+	if err != nil {
+		return fmt.Errorf("metadata bucket: %w", err)
+	}
+	if metadataBucket != nil {
+		if err := metadataBucket.Delete(k); err != nil {
+			return fmt.Errorf("delete metadata: %w", err)
+		}
+	}
+	return nil
+}
+
+// Iterate iterates over blob keys and their metadata in the lexicographical
+// order of keys. If the callback function f returns false, the iteration stops
+// and the next can be used to continue the iteration.
+func (s *BlobStore[K]) Iterate(start *K, reverse bool, f func(K, BlobMetadata) (bool, error)) (next *K, err error) {
+	metadataBucket, err := s.metadataBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("metadata bucket: %w", err)
+	}
+	if metadataBucket == nil {
+		return nil, nil
+	}
+	return iterateKeys(metadataBucket, s.definition.keyEncoding, nil, start, reverse, func(k, v []byte) (bool, error) {
+		key, err := s.definition.keyEncoding.Decode(k)
+		if err != nil {
+			return false, fmt.Errorf("decode key: %w", err)
+		}
+
+		m, err := decodeBlobMetadata(v)
+		if err != nil {
+			return false, fmt.Errorf("decode metadata: %w", err)
+		}
+
+		return f(key, m)
+	})
+}
+
+// Size returns the number of blobs.
+func (s *BlobStore[K]) Size() (int, error) {
+	metadataBucket, err := s.metadataBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("metadata bucket: %w", err)
+	}
+	if metadataBucket == nil {
+		return 0, nil
+	}
+	return size(metadataBucket, false), nil
+}
+
+// BlobReference identifies a specific content of a blob in a BlobStore. It can
+// be stored as a part of Collection values, for example encoded by
+// NewJSONEncoding, to reference large data from smaller records.
+type BlobReference[K any] struct {
+	Key K `json:"key"`
+	BlobMetadata
+}
+
+// Reference returns a BlobReference to the current content of the blob.
+func (s *BlobStore[K]) Reference(key K) (r BlobReference[K], err error) {
+	m, err := s.Metadata(key)
+	if err != nil {
+		return r, err
+	}
+	return BlobReference[K]{
+		Key:          key,
+		BlobMetadata: m,
+	}, nil
+}
+
+// OpenReference returns a BlobReader for the referenced blob. If the blob does
+// not exist or its content changed after the reference was created, configured
+// ErrNotFound is returned.
+func (s *BlobStore[K]) OpenReference(r BlobReference[K]) (*BlobReader, error) {
+	reader, err := s.Open(r.Key)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(reader.metadata.SHA256, r.SHA256) {
+		return nil, fmt.Errorf("blob content changed: %w", s.definition.errNotFound)
+	}
+	return reader, nil
+}
+
+// BlobWriter writes data to a blob chunk by chunk.
+type BlobWriter struct {
+	key            []byte
+	generation     uint64
+	current        []byte // generation of the replaced blob
+	bucket         *bolt.Bucket
+	blobBucket     *bolt.Bucket
+	metadataBucket *bolt.Bucket
+	contentType    string
+	chunkSize      int
+	chunks         uint64
+	size           int64
+	buf            []byte
+	hash           hash.Hash
+	metadata       BlobMetadata
+	closed         bool
+}
+
+// Write appends data to the blob.
+func (w *BlobWriter) Write(p []byte) (n int, err error) {
+	if w.closed {
+		return 0, errors.New("blob writer closed")
+	}
+	for len(p) > 0 {
+		c := copy(w.buf[len(w.buf):w.chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		p = p[c:]
+		n += c
+		if len(w.buf) == w.chunkSize {
+			if err := w.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (w *BlobWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	if err := w.bucket.Put(encodeChunkIndex(w.chunks), w.buf); err != nil {
+		return fmt.Errorf("put chunk %v: %w", w.chunks, err)
+	}
+	w.hash.Write(w.buf)
+	w.size += int64(len(w.buf))
+	w.chunks++
+	w.buf = make([]byte, 0, w.chunkSize) // bolt keeps the reference until commit
+	return nil
+}
+
+// Close writes the remaining data, saves the blob metadata that references the
+// written chunks generation and deletes the chunks of the replaced blob.
+func (w *BlobWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	w.metadata = BlobMetadata{
+		Size:        w.size,
+		ContentType: w.contentType,
+		SHA256:      w.hash.Sum(nil),
+		chunkSize:   w.chunkSize,
+		generation:  w.generation,
+	}
+	if err := w.metadataBucket.Put(w.key, encodeBlobMetadata(w.metadata)); err != nil {
+		return fmt.Errorf("put metadata: %w", err)
+	}
+
+	if w.current != nil && w.blobBucket.Bucket(w.current) != nil {
+		if err := w.blobBucket.DeleteBucket(w.current); err != nil {
+			return fmt.Errorf("delete blob generation bucket: %w", err)
+		}
+	}
+	return nil
+}
+
+// Metadata returns the metadata of the written blob after the BlobWriter is
+// closed.
+func (w *BlobWriter) Metadata() BlobMetadata {
+	return w.metadata
+}
+
+// BlobReader provides sequential and random access to the blob data. It
+// implements io.Reader, io.ReaderAt, io.Seeker and io.WriterTo interfaces.
+// Range reads can be done with ReadAt or io.NewSectionReader.
+type BlobReader struct {
+	bucket   *bolt.Bucket
+	metadata BlobMetadata
+	offset   int64
+}
+
+// Metadata returns the metadata of the blob.
+func (r *BlobReader) Metadata() BlobMetadata {
+	return r.metadata
+}
+
+// Size returns the length of the blob in bytes.
+func (r *BlobReader) Size() int64 {
+	return r.metadata.Size
+}
+
+// Read reads data from the current offset.
+func (r *BlobReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt reads len(p) bytes from the blob starting at the offset.
+func (r *BlobReader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= r.metadata.Size {
+		return 0, io.EOF
+	}
+	chunkSize := int64(r.metadata.chunkSize)
+	for len(p) > 0 && off < r.metadata.Size {
+		index := uint64(off / chunkSize)
+		chunk := r.bucket.Get(encodeChunkIndex(index))
+		if chunk == nil {
+			return n, fmt.Errorf("missing chunk %v", index)
+		}
+		c := copy(p, chunk[off%chunkSize:])
+		p = p[c:]
+		n += c
+		off += int64(c)
+	}
+	if len(p) > 0 {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Seek sets the offset for the next Read.
+func (r *BlobReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.metadata.Size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// WriteTo writes the blob data from the current offset to the writer.
+func (r *BlobReader) WriteTo(w io.Writer) (n int64, err error) {
+	chunkSize := int64(r.metadata.chunkSize)
+	for r.offset < r.metadata.Size {
+		index := uint64(r.offset / chunkSize)
+		chunk := r.bucket.Get(encodeChunkIndex(index))
+		if chunk == nil {
+			return n, fmt.Errorf("missing chunk %v", index)
+		}
+		c, err := w.Write(chunk[r.offset%chunkSize:])
+		n += int64(c)
+		r.offset += int64(c)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func encodeChunkIndex(i uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, i)
+	return b
+}
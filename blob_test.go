@@ -0,0 +1,244 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"math/rand"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+var attachmentsDefinition = boltron.NewBlobStoreDefinition(
+	"attachments",
+	boltron.StringEncoding,
+	&boltron.BlobStoreOptions{
+		ChunkSize: 1000,
+	},
+)
+
+func TestBlobStore(t *testing.T) {
+	db := newDB(t)
+
+	data := make([]byte, 4321)
+	_, _ = rand.New(rand.NewSource(1)).Read(data)
+	checksum := sha256.Sum256(data)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		attachments := attachmentsDefinition.BlobStore(tx)
+
+		m, err := attachments.Put("report.bin", bytes.NewReader(data), "application/octet-stream")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", m.Size, int64(len(data)))
+		assert(t, "", m.ContentType, "application/octet-stream")
+		assert(t, "", m.SHA256, checksum[:])
+
+		w, err := attachments.Create("empty.txt", "text/plain")
+		assertErrorFail(t, "", err, nil)
+		err = w.Close()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", w.Metadata().Size, int64(0))
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		attachments := attachmentsDefinition.BlobStore(tx)
+
+		has, err := attachments.Has("report.bin")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		has, err = attachments.Has("missing")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		size, err := attachments.Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 2)
+
+		r, err := attachments.Open("report.bin")
+		assertErrorFail(t, "", err, nil)
+
+		got, err := io.ReadAll(r)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "read all", got, data)
+
+		for _, tc := range []struct {
+			offset, length int64
+		}{
+			{0, 10},
+			{995, 10},
+			{1000, 1000},
+			{1500, 2500},
+			{4300, 21},
+		} {
+			got, err := io.ReadAll(io.NewSectionReader(r, tc.offset, tc.length))
+			assertErrorFail(t, "", err, nil)
+			assert(t, "range", got, data[tc.offset:tc.offset+tc.length])
+		}
+
+		_, err = r.Seek(-100, io.SeekEnd)
+		assertErrorFail(t, "", err, nil)
+		var buf bytes.Buffer
+		n, err := r.WriteTo(&buf)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", n, int64(100))
+		assert(t, "", buf.Bytes(), data[len(data)-100:])
+
+		r, err = attachments.Open("empty.txt")
+		assertErrorFail(t, "", err, nil)
+		got, err = io.ReadAll(r)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(got), 0)
+
+		_, err = attachments.Open("missing")
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		var keys []string
+		_, err = attachments.Iterate(nil, false, func(k string, m boltron.BlobMetadata) (bool, error) {
+			keys = append(keys, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", keys, []string{"empty.txt", "report.bin"})
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		attachments := attachmentsDefinition.BlobStore(tx)
+
+		_, err := attachments.Put("report.bin", bytes.NewReader(data[:10]), "text/plain")
+		assertErrorFail(t, "", err, nil)
+
+		m, err := attachments.Metadata("report.bin")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", m.Size, int64(10))
+
+		err = attachments.Delete("empty.txt", true)
+		assertErrorFail(t, "", err, nil)
+
+		err = attachments.Delete("empty.txt", true)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		err = attachments.Delete("empty.txt", false)
+		assertError(t, "", err, nil)
+	})
+}
+
+func TestBlobStore_reference(t *testing.T) {
+	db := newDB(t)
+
+	type message struct {
+		Text       string
+		Attachment boltron.BlobReference[string]
+	}
+
+	messagesDefinition := boltron.NewCollectionDefinition(
+		"messages",
+		boltron.Uint64BinaryEncoding,
+		boltron.NewJSONEncoding[*message](),
+		nil,
+	)
+
+	data := bytes.Repeat([]byte("attachment "), 500)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		attachments := attachmentsDefinition.BlobStore(tx)
+
+		_, err := attachments.Put("a1", bytes.NewReader(data), "text/plain")
+		assertErrorFail(t, "", err, nil)
+
+		ref, err := attachments.Reference("a1")
+		assertErrorFail(t, "", err, nil)
+
+		_, err = messagesDefinition.Collection(tx).Save(1, &message{
+			Text:       "Hello",
+			Attachment: ref,
+		}, false)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		attachments := attachmentsDefinition.BlobStore(tx)
+
+		m, err := messagesDefinition.Collection(tx).Get(1)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", m.Attachment.Size, int64(len(data)))
+		assert(t, "", m.Attachment.ContentType, "text/plain")
+
+		r, err := attachments.OpenReference(m.Attachment)
+		assertErrorFail(t, "", err, nil)
+		got, err := io.ReadAll(r)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", got, data)
+
+		_, err = attachments.Put("a1", bytes.NewReader([]byte("changed")), "text/plain")
+		assertErrorFail(t, "", err, nil)
+
+		_, err = attachments.OpenReference(m.Attachment)
+		assertError(t, "", err, boltron.ErrNotFound)
+	})
+}
+
+func TestBlobStore_createKeepsBlobUntilClose(t *testing.T) {
+	db := newDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		attachments := attachmentsDefinition.BlobStore(tx)
+
+		_, err := attachments.Put("note.txt", bytes.NewReader([]byte("old content")), "text/plain")
+		assertErrorFail(t, "", err, nil)
+
+		w, err := attachments.Create("note.txt", "text/markdown")
+		assertErrorFail(t, "", err, nil)
+		_, err = w.Write(bytes.Repeat([]byte("new"), 1000))
+		assertErrorFail(t, "", err, nil)
+
+		r, err := attachments.Open("note.txt")
+		assertErrorFail(t, "", err, nil)
+		got, err := io.ReadAll(r)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "before close", string(got), "old content")
+		assert(t, "before close", r.Metadata().ContentType, "text/plain")
+
+		err = w.Close()
+		assertErrorFail(t, "", err, nil)
+
+		r, err = attachments.Open("note.txt")
+		assertErrorFail(t, "", err, nil)
+		got, err = io.ReadAll(r)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "after close", got, bytes.Repeat([]byte("new"), 1000))
+		assert(t, "after close", r.Metadata().ContentType, "text/markdown")
+
+		// an abandoned writer does not change the blob
+		w, err = attachments.Create("note.txt", "text/plain")
+		assertErrorFail(t, "", err, nil)
+		_, err = w.Write([]byte("abandoned"))
+		assertErrorFail(t, "", err, nil)
+
+		_, err = attachments.Put("note.txt", bytes.NewReader([]byte("final")), "text/plain")
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		r, err := attachmentsDefinition.BlobStore(tx).Open("note.txt")
+		assertErrorFail(t, "", err, nil)
+		got, err := io.ReadAll(r)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", string(got), "final")
+
+		size, err := attachmentsDefinition.BlobStore(tx).Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 1)
+
+		// only the chunks of the current content are kept
+		blobBucket := tx.Bucket([]byte("boltron: blob store: attachments chunks")).Bucket([]byte("note.txt"))
+		assert(t, "generations", blobBucket.Stats().BucketN-1, 1)
+	})
+}
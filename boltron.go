@@ -19,6 +19,7 @@ package boltron
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 
 	bolt "go.etcd.io/bbolt"
@@ -79,7 +80,7 @@ func bucketPath(s ...string) [][]byte {
 	return r
 }
 
-func iterateKeys[K any](bucket *bolt.Bucket, keyEncoding Encoding[K], start *K, reverse bool, f func(k, v []byte) (bool, error)) (next *K, err error) {
+func iterateKeys[K any](bucket *bolt.Bucket, keyEncoding Encoding[K], corruptedHandler func(key []byte, err error), start *K, reverse bool, f func(k, v []byte) (bool, error)) (next *K, err error) {
 	var startKey []byte
 	if start != nil {
 
@@ -95,18 +96,23 @@ func iterateKeys[K any](bucket *bolt.Bucket, keyEncoding Encoding[K], start *K,
 		return nil, err
 	}
 
-	if nextKey != nil {
+	for nextKey != nil {
 		n, err := keyEncoding.Decode(nextKey)
 		if err != nil {
+			if skipCorrupted(corruptedHandler, nextKey, err) {
+				nextKey, _ = adjacent(bucket, nextKey, reverse)
+				continue
+			}
 			return nil, fmt.Errorf("decode start key: %w", err)
 		}
 		next = &n
+		break
 	}
 
 	return next, nil
 }
 
-func iterateList[V, O any](bucket *bolt.Bucket, valueEncoding Encoding[V], orderByEncoding Encoding[O], start *ListElement[V, O], reverse bool, f func(k, v []byte) (bool, error)) (next *ListElement[V, O], err error) {
+func iterateList[V, O any](bucket *bolt.Bucket, valueEncoding Encoding[V], orderByEncoding Encoding[O], keys listKeys, corruptedHandler func(key []byte, err error), start *ListElement[V, O], reverse bool, f func(k, v []byte) (bool, error)) (next *ListElement[V, O], err error) {
 	var startKey []byte
 	if start != nil {
 
@@ -119,7 +125,11 @@ func iterateList[V, O any](bucket *bolt.Bucket, valueEncoding Encoding[V], order
 		if err != nil {
 			return nil, fmt.Errorf("encode start order by: %w", err)
 		}
-		startKey = append(o, v...)
+		var seq []byte
+		if keys.sequenced() {
+			seq = encodeListSequence(start.Sequence)
+		}
+		startKey = keys.key(o, v, seq)
 	}
 
 	nextKey, nextValue, err := iterate(bucket, startKey, reverse, f)
@@ -127,20 +137,30 @@ func iterateList[V, O any](bucket *bolt.Bucket, valueEncoding Encoding[V], order
 		return nil, err
 	}
 
-	if nextKey != nil {
+	for nextKey != nil {
 		value, err := valueEncoding.Decode(nextValue)
 		if err != nil {
+			if skipCorrupted(corruptedHandler, nextKey, err) {
+				nextKey, nextValue = adjacent(bucket, nextKey, reverse)
+				continue
+			}
 			return nil, fmt.Errorf("decode start key: %w", err)
 		}
-		nextOrderBy := nextKey[:len(nextKey)-len(nextValue)]
+		nextOrderBy := keys.orderBy(nextKey, nextValue)
 		orderBy, err := orderByEncoding.Decode(nextOrderBy)
 		if err != nil {
+			if skipCorrupted(corruptedHandler, nextKey, err) {
+				nextKey, nextValue = adjacent(bucket, nextKey, reverse)
+				continue
+			}
 			return nil, fmt.Errorf("decode start key: %w", err)
 		}
 		next = &ListElement[V, O]{
-			Value:   value,
-			OrderBy: orderBy,
+			Value:    value,
+			OrderBy:  orderBy,
+			Sequence: decodeListSequence(keys.sequence(nextKey, nextValue)),
 		}
+		break
 	}
 
 	return next, nil
@@ -184,6 +204,17 @@ func iterate(bucket *bolt.Bucket, startKey []byte, reverse bool, f func(k, v []b
 	return nextKey, nextValue, nil
 }
 
+// adjacent returns the key and value that follow the existing key k in the
+// iteration order.
+func adjacent(bucket *bolt.Bucket, k []byte, reverse bool) (nextKey, nextValue []byte) {
+	cursor := bucket.Cursor()
+	cursor.Seek(k)
+	if reverse {
+		return cursor.Prev()
+	}
+	return cursor.Next()
+}
+
 func page[E any](bucket *bolt.Bucket, bucketOfBuckets bool, number, limit int, reverse bool, f func(k, v []byte) (E, error)) (s []E, totalElements, pages int, err error) {
 	if number <= 0 {
 		return nil, 0, 0, ErrInvalidPageNumber
@@ -224,6 +255,9 @@ func page[E any](bucket *bolt.Bucket, bucketOfBuckets bool, number, limit int, r
 
 		e, err := f(k, v)
 		if err != nil {
+			if errors.Is(err, errSkipElement) {
+				continue
+			}
 			return nil, 0, 0, err
 		}
 
@@ -240,6 +274,35 @@ func size(bucket *bolt.Bucket, bucketOfBuckets bool) int {
 	return bucket.Stats().KeyN
 }
 
+// errSkipElement is returned by page callback function to exclude the element
+// from the page. Skipped element is still counted in total elements and pages,
+// as they are calculated from bucket statistics without decoding, and it keeps
+// its position so that the page is not filled with an element from the next
+// one.
+var errSkipElement = errors.New("skip element")
+
+// skipCorrupted calls the handler for the key of the element that could not be
+// decoded if the error is ErrCorrupted and returns true if the element should be
+// skipped.
+func skipCorrupted(handler func(key []byte, err error), key []byte, err error) bool {
+	if handler == nil || !errors.Is(err, ErrCorrupted) {
+		return false
+	}
+	handler(append([]byte(nil), key...), err)
+	return true
+}
+
+// bucketValue returns the value of the key k in the bucket and true if the key
+// exists. Bolt returns nil from Get for keys with empty values that are put in
+// the same transaction, so it can not be used to check if such keys exist.
+func bucketValue(bucket *bolt.Bucket, k []byte) (v []byte, exists bool) {
+	key, value := bucket.Cursor().Seek(k)
+	if key == nil || !bytes.Equal(key, k) {
+		return nil, false
+	}
+	return value, true
+}
+
 func withDefaultError(v, d error) error {
 	if v != nil {
 		return v
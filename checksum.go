@@ -0,0 +1,57 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksumLen is the length of the CRC32C checksum appended to the encoded
+// value.
+const checksumLen = crc32.Size
+
+// ChecksumEncoding appends the CRC32C checksum to values encoded by another
+// encoding and verifies it on decoding to detect data corruption. If the
+// checksum does not match, ErrCorrupted is returned.
+type ChecksumEncoding[T any] struct {
+	encoding Encoding[T]
+}
+
+// NewChecksumEncoding constructs a ChecksumEncoding that verifies values
+// encoded by the provided encoding.
+func NewChecksumEncoding[T any](encoding Encoding[T]) *ChecksumEncoding[T] {
+	return &ChecksumEncoding[T]{
+		encoding: encoding,
+	}
+}
+
+// Encode serializes the value with the wrapped encoding and appends its
+// checksum.
+func (e *ChecksumEncoding[T]) Encode(t T) ([]byte, error) {
+	b, err := e.encoding.Encode(t)
+	if err != nil {
+		return nil, err
+	}
+	return binary.BigEndian.AppendUint32(b, crc32.Checksum(b, castagnoliTable)), nil
+}
+
+// Decode verifies the checksum and deserializes the value with the wrapped
+// encoding.
+func (e *ChecksumEncoding[T]) Decode(b []byte) (t T, err error) {
+	l := len(b)
+	if l < checksumLen {
+		return t, fmt.Errorf("missing checksum: %w", ErrCorrupted)
+	}
+	data := b[:l-checksumLen]
+	if binary.BigEndian.Uint32(b[l-checksumLen:]) != crc32.Checksum(data, castagnoliTable) {
+		return t, fmt.Errorf("checksum mismatch: %w", ErrCorrupted)
+	}
+	return e.encoding.Decode(data)
+}
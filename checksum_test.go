@@ -0,0 +1,246 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+func TestChecksumEncoding(t *testing.T) {
+	encoding := boltron.NewChecksumEncoding(boltron.StringEncoding)
+
+	testEncoding(t, boltron.Encoding[string](encoding), "test", []byte{'t', 'e', 's', 't', 0x86, 0xa0, 0x72, 0xc0})
+	testEncoding(t, boltron.Encoding[string](encoding), "", []byte{0, 0, 0, 0})
+
+	_, err := encoding.Decode([]byte{'t', 'e', 'x', 't', 0x86, 0xa0, 0x72, 0xc0})
+	assertError(t, "", err, boltron.ErrCorrupted)
+
+	_, err = encoding.Decode([]byte{0})
+	assertError(t, "", err, boltron.ErrCorrupted)
+}
+
+func TestChecksumEncoding_skipCorrupted(t *testing.T) {
+	db := newDB(t)
+
+	var corrupted []string
+
+	definition := boltron.NewCollectionDefinition(
+		"checksums",
+		boltron.StringEncoding,
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		&boltron.CollectionOptions{
+			CorruptedHandler: func(key []byte, err error) {
+				corrupted = append(corrupted, string(key))
+			},
+		},
+	)
+
+	strictDefinition := boltron.NewCollectionDefinition(
+		"checksums",
+		boltron.StringEncoding,
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		nil,
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		c := definition.Collection(tx)
+		for _, k := range []string{"a", "b", "c", "d"} {
+			_, err := c.Save(k, "value "+k, false)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		bucket := tx.Bucket([]byte("boltron: collection: checksums"))
+		v := append([]byte(nil), bucket.Get([]byte("b"))...)
+		v[0] ^= 0xff
+		err := bucket.Put([]byte("b"), v)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		var keys []string
+		_, err := definition.Collection(tx).Iterate(nil, false, func(k, v string) (bool, error) {
+			keys = append(keys, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", keys, []string{"a", "c", "d"})
+		assert(t, "", corrupted, []string{"b"})
+
+		values, totalElements, pages, err := definition.Collection(tx).PageOfValues(1, 2, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []string{"value a"})
+		assert(t, "", totalElements, 4)
+		assert(t, "", pages, 2)
+		assert(t, "", corrupted, []string{"b", "b"})
+
+		_, err = strictDefinition.Collection(tx).Iterate(nil, false, func(k, v string) (bool, error) {
+			return true, nil
+		})
+		assertError(t, "", err, boltron.ErrCorrupted)
+	})
+}
+
+func TestChecksumEncoding_skipCorruptedNext(t *testing.T) {
+	db := newDB(t)
+
+	var corrupted [][]byte
+
+	handler := func(key []byte, err error) {
+		corrupted = append(corrupted, key)
+	}
+
+	collectionDefinition := boltron.NewCollectionDefinition(
+		"checksums",
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		boltron.StringEncoding,
+		&boltron.CollectionOptions{
+			CorruptedHandler: handler,
+		},
+	)
+
+	listDefinition := boltron.NewListDefinition(
+		"checksums",
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		boltron.Uint64BinaryEncoding,
+		&boltron.ListOptions{
+			CorruptedHandler: handler,
+		},
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		c := collectionDefinition.Collection(tx)
+		l := listDefinition.List(tx)
+		for i, k := range []string{"a", "b", "c", "d"} {
+			_, err := c.Save(k, "value "+k, false)
+			assertErrorFail(t, "", err, nil)
+
+			err = l.Add(k, uint64(i))
+			assertErrorFail(t, "", err, nil)
+		}
+
+		// corrupt the checksum of the key b in the collection
+		bucket := tx.Bucket([]byte("boltron: collection: checksums"))
+		cursor := bucket.Cursor()
+		k, v := cursor.Seek([]byte("b"))
+		k, v = append([]byte(nil), k...), append([]byte(nil), v...)
+		err := bucket.Delete(k)
+		assertErrorFail(t, "", err, nil)
+		k[len(k)-1] ^= 0xff
+		err = bucket.Put(k, v)
+		assertErrorFail(t, "", err, nil)
+
+		// corrupt the value b in the list
+		bucket = tx.Bucket([]byte("boltron: list: checksums values"))
+		cursor = bucket.Cursor()
+		cursor.First()
+		k, v = cursor.Next()
+		k, v = append([]byte(nil), k...), append([]byte(nil), v...)
+		v[0] ^= 0xff
+		err = bucket.Put(k, v)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		var keys []string
+		next, err := collectionDefinition.Collection(tx).IterateKeys(nil, false, func(k string) (bool, error) {
+			keys = append(keys, k)
+			return false, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", keys, []string{"a"})
+		assert(t, "", *next, "c")
+		assert(t, "", len(corrupted), 1)
+
+		var values []string
+		nextElement, err := listDefinition.List(tx).IterateValues(nil, false, func(v string) (bool, error) {
+			values = append(values, v)
+			return false, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []string{"a"})
+		assert(t, "", nextElement.Value, "c")
+		assert(t, "", len(corrupted), 2)
+
+		_, err = listDefinition.List(tx).IterateValues(nextElement, false, func(v string) (bool, error) {
+			values = append(values, v)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []string{"a", "c", "d"})
+		assert(t, "", len(corrupted), 2)
+
+		nextElement, err = listDefinition.List(tx).IterateValues(nil, true, func(v string) (bool, error) {
+			return v != "c", nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", nextElement.Value, "a")
+		assert(t, "", len(corrupted), 3)
+	})
+}
+
+func TestChecksumEncoding_skipCorruptedCollections(t *testing.T) {
+	db := newDB(t)
+
+	var corrupted [][]byte
+	handler := func(key []byte, err error) {
+		assertError(t, "", err, boltron.ErrCorrupted)
+		corrupted = append(corrupted, key)
+	}
+
+	definition := boltron.NewCollectionsDefinition(
+		"checksums",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		&boltron.CollectionsOptions{
+			CorruptedHandler: handler,
+		},
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		c := definition.Collections(tx)
+		for _, collection := range []string{"x", "y"} {
+			cc, _, err := c.Collection(collection)
+			assertErrorFail(t, "", err, nil)
+			for _, k := range []string{"a", "b", "c"} {
+				_, err := cc.Save(k, "value "+k, false)
+				assertErrorFail(t, "", err, nil)
+			}
+		}
+
+		// corrupt the value b in the collection x
+		bucket := tx.Bucket([]byte("boltron: collections: checksums collections")).Bucket([]byte("x"))
+		v := append([]byte(nil), bucket.Get([]byte("b"))...)
+		v[0] ^= 0xff
+		err := bucket.Put([]byte("b"), v)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		c, _, err := definition.Collections(tx).Collection("x")
+		assertErrorFail(t, "", err, nil)
+		var keys []string
+		_, err = c.Iterate(nil, false, func(k, _ string) (bool, error) {
+			keys = append(keys, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", keys, []string{"a", "c"})
+		assert(t, "", len(corrupted), 1)
+
+		keys = nil
+		_, err = definition.Collections(tx).IterateAll(nil, false, func(c, k, _ string) (bool, error) {
+			keys = append(keys, c+k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", keys, []string{"xa", "xc", "ya", "yb", "yc"})
+		assert(t, "", len(corrupted), 2)
+	})
+}
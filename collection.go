@@ -7,6 +7,8 @@ package boltron
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 
 	bolt "go.etcd.io/bbolt"
@@ -15,14 +17,18 @@ import (
 // CollectionDefinition defines the most basic data model which is a Collection
 // of keys and values. Each key is a unique within a Collection.
 type CollectionDefinition[K, V any] struct {
-	bucketPath     [][]byte
-	keyEncoding    Encoding[K]
-	valueEncoding  Encoding[V]
-	fillPercent    float64
-	errNotFound    error
-	errKeyExists   error
-	saveCallback   func(key []byte) error
-	deleteCallback func(key []byte) error
+	bucketPath         [][]byte
+	bucketPathContent  [][]byte // used only if values are content addressed
+	keyEncoding        Encoding[K]
+	valueEncoding      Encoding[V]
+	fillPercent        float64
+	errNotFound        error
+	errKeyExists       error
+	hashedKeys         bool
+	unorderedIteration bool
+	corruptedHandler   func(key []byte, err error)
+	saveCallback       func(key []byte) error
+	deleteCallback     func(key []byte) error
 }
 
 // CollectionOptions provides additional configuration for a Collection.
@@ -34,6 +40,29 @@ type CollectionOptions struct {
 	// ErrKeyExists is returned if the key already exists and its value is not
 	// allowed to be overwritten.
 	ErrKeyExists error
+	// CorruptedHandler is called with the key of every element that is skipped
+	// by iteration and pagination methods because its key or value can not be
+	// decoded with ErrCorrupted error. If it is nil, iteration is aborted.
+	// Skipped elements are still counted in total elements and pages returned
+	// by pagination methods, so a page may contain fewer elements than the
+	// limit.
+	CorruptedHandler func(key []byte, err error)
+	// ContentAddressed marks if every distinct value is stored only once under
+	// its SHA-256 hash with the number of keys that reference it. Keys point to
+	// the hash and values that are no longer referenced are removed. It reduces
+	// the database size if many keys have identical values. Values that are
+	// stored before the option is set are still read as they are and they are
+	// replaced with references when they are saved again.
+	ContentAddressed bool
+	// HashedKeys marks if keys are stored in bolt as their fixed size hashes,
+	// with the original keys stored alongside values. It allows keys that are
+	// larger than the bolt key size limit and saves page space for long keys.
+	// Iteration and pagination methods return ErrKeysNotOrdered, unless
+	// UnorderedIteration is set, as keys are not in the lexicographical order.
+	HashedKeys bool
+	// UnorderedIteration allows iteration and pagination of hashed keys in
+	// the order of their hashes.
+	UnorderedIteration bool
 }
 
 // NewCollectionDefinition constructs a new CollectionDefinition with a unique
@@ -47,13 +76,21 @@ func NewCollectionDefinition[K, V any](
 	if o == nil {
 		o = new(CollectionOptions)
 	}
+	var bucketPathContent [][]byte
+	if o.ContentAddressed {
+		bucketPathContent = bucketPath("boltron: collection content: " + name)
+	}
 	return &CollectionDefinition[K, V]{
-		bucketPath:    bucketPath("boltron: collection: " + name),
-		keyEncoding:   keyEncoding,
-		valueEncoding: valueEncoding,
-		fillPercent:   o.FillPercent,
-		errNotFound:   withDefaultError(o.ErrNotFound, ErrNotFound),
-		errKeyExists:  withDefaultError(o.ErrKeyExists, ErrKeyExists),
+		bucketPath:         bucketPath("boltron: collection: " + name),
+		bucketPathContent:  bucketPathContent,
+		keyEncoding:        keyEncoding,
+		valueEncoding:      valueEncoding,
+		fillPercent:        o.FillPercent,
+		errNotFound:        withDefaultError(o.ErrNotFound, ErrNotFound),
+		errKeyExists:       withDefaultError(o.ErrKeyExists, ErrKeyExists),
+		hashedKeys:         o.HashedKeys,
+		unorderedIteration: o.UnorderedIteration,
+		corruptedHandler:   o.CorruptedHandler,
 	}
 }
 
@@ -68,9 +105,10 @@ func (d *CollectionDefinition[K, V]) Collection(tx *bolt.Tx) *Collection[K, V] {
 
 // Collection provides methods to access and change key/value pairs.
 type Collection[K, V any] struct {
-	tx          *bolt.Tx
-	bucketCache *bolt.Bucket
-	definition  *CollectionDefinition[K, V]
+	tx                 *bolt.Tx
+	bucketCache        *bolt.Bucket
+	contentBucketCache *bolt.Bucket
+	definition         *CollectionDefinition[K, V]
 }
 
 func (c *Collection[K, V]) bucket(create bool) (*bolt.Bucket, error) {
@@ -88,6 +126,111 @@ func (c *Collection[K, V]) bucket(create bool) (*bolt.Bucket, error) {
 	return bucket, nil
 }
 
+func (c *Collection[K, V]) contentBucket(create bool) (*bolt.Bucket, error) {
+	if c.contentBucketCache != nil {
+		return c.contentBucketCache, nil
+	}
+	bucket, err := deepBucket(c.tx, create, c.definition.bucketPathContent...)
+	if err != nil {
+		return nil, err
+	}
+	if c.definition.fillPercent > 0 && bucket != nil {
+		bucket.FillPercent = c.definition.fillPercent
+	}
+	c.contentBucketCache = bucket
+	return bucket, nil
+}
+
+// checkOrder returns ErrKeysNotOrdered if keys are hashed and they are not
+// allowed to be iterated in the order of hashes.
+func (c *Collection[K, V]) checkOrder() error {
+	if c.definition.hashedKeys && !c.definition.unorderedIteration {
+		return ErrKeysNotOrdered
+	}
+	return nil
+}
+
+func (c *Collection[K, V]) contentAddressed() bool {
+	return c.definition.bucketPathContent != nil
+}
+
+// decodeValue decodes the value stored under the key, resolving it from the
+// content bucket if values are content addressed.
+func (c *Collection[K, V]) decodeValue(v []byte) (value V, err error) {
+	if c.contentAddressed() {
+		content, err := c.content(v)
+		if err != nil {
+			return value, err
+		}
+		if content != nil {
+			v = content[8:]
+		}
+	}
+	return c.definition.valueEncoding.Decode(v)
+}
+
+// content returns the reference count and the value from the content bucket if
+// the stored value is a reference to it, or nil if the stored value is not a
+// reference, as it is stored before values were content addressed.
+func (c *Collection[K, V]) content(v []byte) ([]byte, error) {
+	if len(v) != sha256.Size {
+		return nil, nil
+	}
+	contentBucket, err := c.contentBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("content bucket: %w", err)
+	}
+	if contentBucket == nil {
+		return nil, nil
+	}
+	content := contentBucket.Get(v)
+	if len(content) < 8 {
+		return nil, nil
+	}
+	return content, nil
+}
+
+// retainContent stores the value under its hash or increments the reference
+// count if it already exists.
+func (c *Collection[K, V]) retainContent(hash, v []byte) error {
+	contentBucket, err := c.contentBucket(true)
+	if err != nil {
+		return fmt.Errorf("content bucket: %w", err)
+	}
+	var count uint64
+	if content := contentBucket.Get(hash); len(content) >= 8 {
+		count = binary.BigEndian.Uint64(content[:8])
+	}
+	content := make([]byte, 8, 8+len(v))
+	binary.BigEndian.PutUint64(content, count+1)
+	return contentBucket.Put(hash, append(content, v...))
+}
+
+// releaseContent decrements the reference count of the value and removes it
+// if it is no longer referenced. Stored values that are not references are
+// ignored.
+func (c *Collection[K, V]) releaseContent(hash []byte) error {
+	content, err := c.content(hash)
+	if err != nil {
+		return err
+	}
+	if content == nil {
+		return nil
+	}
+	contentBucket, err := c.contentBucket(false)
+	if err != nil {
+		return fmt.Errorf("content bucket: %w", err)
+	}
+	count := binary.BigEndian.Uint64(content[:8])
+	if count <= 1 {
+		return contentBucket.Delete(hash)
+	}
+	updated := make([]byte, len(content))
+	copy(updated, content)
+	binary.BigEndian.PutUint64(updated, count-1)
+	return contentBucket.Put(hash, updated)
+}
+
 // Has returns true if the key already exists in the database.
 func (c *Collection[K, V]) Has(key K) (bool, error) {
 	k, err := c.definition.keyEncoding.Encode(key)
@@ -101,7 +244,7 @@ func (c *Collection[K, V]) Has(key K) (bool, error) {
 	if bucket == nil {
 		return false, nil
 	}
-	return bucket.Get(k) != nil, nil
+	return bucketGet(bucket, c.definition.hashedKeys, k) != nil, nil
 }
 
 // Get returns a value associated with the given key. If key does not exist,
@@ -118,11 +261,11 @@ func (c *Collection[K, V]) Get(key K) (value V, err error) {
 	if bucket == nil {
 		return value, c.definition.errNotFound
 	}
-	v := bucket.Get(k)
+	v := bucketGet(bucket, c.definition.hashedKeys, k)
 	if v == nil {
 		return value, c.definition.errNotFound
 	}
-	value, err = c.definition.valueEncoding.Decode(v)
+	value, err = c.decodeValue(v)
 	if err != nil {
 		return value, fmt.Errorf("decode value: %w", err)
 	}
@@ -144,8 +287,24 @@ func (c *Collection[K, V]) Save(key K, value V, overwrite bool) (overwritten boo
 	if err != nil {
 		return false, fmt.Errorf("encode value: %w", err)
 	}
-	currentValue := bucket.Get(k)
-	overwritten = currentValue != nil && !bytes.Equal(currentValue, v)
+	stored := v
+	if c.contentAddressed() {
+		hash := sha256.Sum256(v)
+		stored = hash[:]
+	}
+	currentValue := bucketGet(bucket, c.definition.hashedKeys, k)
+	overwritten = currentValue != nil && !bytes.Equal(currentValue, stored)
+	if c.contentAddressed() && overwritten {
+		content, err := c.content(currentValue)
+		if err != nil {
+			return false, err
+		}
+		if content == nil {
+			// the value is stored before values were content addressed and it
+			// is converted to the reference even if it is not changed
+			overwritten = !bytes.Equal(currentValue, v)
+		}
+	}
 	if overwritten && !overwrite {
 		return false, c.definition.errKeyExists
 	}
@@ -156,7 +315,18 @@ func (c *Collection[K, V]) Save(key K, value V, overwrite bool) (overwritten boo
 		}
 	}
 
-	return overwritten, bucket.Put(k, v)
+	if c.contentAddressed() && !bytes.Equal(currentValue, stored) {
+		if currentValue != nil {
+			if err := c.releaseContent(append([]byte(nil), currentValue...)); err != nil {
+				return false, fmt.Errorf("release value content: %w", err)
+			}
+		}
+		if err := c.retainContent(stored, v); err != nil {
+			return false, fmt.Errorf("retain value content: %w", err)
+		}
+	}
+
+	return overwritten, bucketPut(bucket, c.definition.hashedKeys, k, stored)
 }
 
 // Delete removes the key and its associated value from the database. If ensure
@@ -177,11 +347,9 @@ func (c *Collection[K, V]) Delete(key K, ensure bool) error {
 		}
 		return nil
 	}
-	if ensure {
-		v := bucket.Get(k)
-		if v == nil {
-			return c.definition.errNotFound
-		}
+	v := bucketGet(bucket, c.definition.hashedKeys, k)
+	if ensure && v == nil {
+		return c.definition.errNotFound
 	}
 
 	if c.definition.deleteCallback != nil {
@@ -190,11 +358,20 @@ func (c *Collection[K, V]) Delete(key K, ensure bool) error {
 		}
 	}
 
-	return bucket.Delete(k)
+	if c.contentAddressed() && v != nil {
+		if err := c.releaseContent(append([]byte(nil), v...)); err != nil {
+			return fmt.Errorf("release value content: %w", err)
+		}
+	}
+
+	return bucketDelete(bucket, c.definition.hashedKeys, k)
 }
 
 // Iterate iterates over keys and values in the lexicographical order of keys.
 func (c *Collection[K, V]) Iterate(start *K, reverse bool, f func(K, V) (bool, error)) (next *K, err error) {
+	if err := c.checkOrder(); err != nil {
+		return nil, err
+	}
 	bucket, err := c.bucket(false)
 	if err != nil {
 		return nil, fmt.Errorf("bucket: %w", err)
@@ -202,14 +379,20 @@ func (c *Collection[K, V]) Iterate(start *K, reverse bool, f func(K, V) (bool, e
 	if bucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(bucket, c.definition.keyEncoding, start, reverse, func(k, v []byte) (bool, error) {
+	return iterateBucket(bucket, c.definition.hashedKeys, c.definition.keyEncoding, c.definition.corruptedHandler, start, reverse, func(k, v []byte) (bool, error) {
 		key, err := c.definition.keyEncoding.Decode(k)
 		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode key: %w", err)
 		}
 
-		value, err := c.definition.valueEncoding.Decode(v)
+		value, err := c.decodeValue(v)
 		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode value: %w", err)
 		}
 
@@ -221,6 +404,9 @@ func (c *Collection[K, V]) Iterate(start *K, reverse bool, f func(K, V) (bool, e
 // callback function f returns false, the iteration stops and the next can be
 // used to continue the iteration.
 func (c *Collection[K, V]) IterateKeys(start *K, reverse bool, f func(K) (bool, error)) (next *K, err error) {
+	if err := c.checkOrder(); err != nil {
+		return nil, err
+	}
 	bucket, err := c.bucket(false)
 	if err != nil {
 		return nil, fmt.Errorf("bucket: %w", err)
@@ -228,9 +414,12 @@ func (c *Collection[K, V]) IterateKeys(start *K, reverse bool, f func(K) (bool,
 	if bucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(bucket, c.definition.keyEncoding, start, reverse, func(k, _ []byte) (bool, error) {
+	return iterateBucket(bucket, c.definition.hashedKeys, c.definition.keyEncoding, c.definition.corruptedHandler, start, reverse, func(k, _ []byte) (bool, error) {
 		key, err := c.definition.keyEncoding.Decode(k)
 		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode key: %w", err)
 		}
 
@@ -242,6 +431,9 @@ func (c *Collection[K, V]) IterateKeys(start *K, reverse bool, f func(K) (bool,
 // the callback function f returns false, the iteration stops and the next can
 // be used to continue the iteration.
 func (c *Collection[K, V]) IterateValues(start *K, reverse bool, f func(V) (bool, error)) (next *K, err error) {
+	if err := c.checkOrder(); err != nil {
+		return nil, err
+	}
 	bucket, err := c.bucket(false)
 	if err != nil {
 		return nil, fmt.Errorf("bucket: %w", err)
@@ -249,9 +441,12 @@ func (c *Collection[K, V]) IterateValues(start *K, reverse bool, f func(V) (bool
 	if bucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(bucket, c.definition.keyEncoding, start, reverse, func(_, v []byte) (bool, error) {
-		value, err := c.definition.valueEncoding.Decode(v)
+	return iterateBucket(bucket, c.definition.hashedKeys, c.definition.keyEncoding, c.definition.corruptedHandler, start, reverse, func(k, v []byte) (bool, error) {
+		value, err := c.decodeValue(v)
 		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode value: %w", err)
 		}
 
@@ -281,6 +476,9 @@ type CollectionElement[K, V any] struct {
 // Page returns at most a limit of elements of key/value pairs at the provided
 // page number.
 func (c *Collection[K, V]) Page(number, limit int, reverse bool) (s []CollectionElement[K, V], totalElements, pages int, err error) {
+	if err := c.checkOrder(); err != nil {
+		return nil, 0, 0, err
+	}
 	bucket, err := c.bucket(false)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("bucket: %w", err)
@@ -288,14 +486,20 @@ func (c *Collection[K, V]) Page(number, limit int, reverse bool) (s []Collection
 	if bucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(bucket, false, number, limit, reverse, func(k, v []byte) (e CollectionElement[K, V], err error) {
+	return pageBucket(bucket, c.definition.hashedKeys, number, limit, reverse, func(k, v []byte) (e CollectionElement[K, V], err error) {
 		key, err := c.definition.keyEncoding.Decode(k)
 		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, k, err) {
+				return e, errSkipElement
+			}
 			return e, fmt.Errorf("key value: %w", err)
 		}
 
-		value, err := c.definition.valueEncoding.Decode(v)
+		value, err := c.decodeValue(v)
 		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, k, err) {
+				return e, errSkipElement
+			}
 			return e, fmt.Errorf("decode value: %w", err)
 		}
 
@@ -308,6 +512,9 @@ func (c *Collection[K, V]) Page(number, limit int, reverse bool) (s []Collection
 
 // PageOfKeys returns at most a limit of keys at the provided page number.
 func (c *Collection[K, V]) PageOfKeys(number, limit int, reverse bool) (s []K, totalElements, pages int, err error) {
+	if err := c.checkOrder(); err != nil {
+		return nil, 0, 0, err
+	}
 	bucket, err := c.bucket(false)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("bucket: %w", err)
@@ -315,13 +522,20 @@ func (c *Collection[K, V]) PageOfKeys(number, limit int, reverse bool) (s []K, t
 	if bucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(bucket, false, number, limit, reverse, func(k, _ []byte) (key K, err error) {
-		return c.definition.keyEncoding.Decode(k)
+	return pageBucket(bucket, c.definition.hashedKeys, number, limit, reverse, func(k, _ []byte) (key K, err error) {
+		key, err = c.definition.keyEncoding.Decode(k)
+		if skipCorrupted(c.definition.corruptedHandler, k, err) {
+			return key, errSkipElement
+		}
+		return key, err
 	})
 }
 
 // PageOfValues returns at most a limit of values at the provided page number.
 func (c *Collection[K, V]) PageOfValues(number, limit int, reverse bool) (s []V, totalElements, pages int, err error) {
+	if err := c.checkOrder(); err != nil {
+		return nil, 0, 0, err
+	}
 	bucket, err := c.bucket(false)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("bucket: %w", err)
@@ -329,7 +543,11 @@ func (c *Collection[K, V]) PageOfValues(number, limit int, reverse bool) (s []V,
 	if bucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(bucket, false, number, limit, reverse, func(_, v []byte) (key V, err error) {
-		return c.definition.valueEncoding.Decode(v)
+	return pageBucket(bucket, c.definition.hashedKeys, number, limit, reverse, func(k, v []byte) (value V, err error) {
+		value, err = c.decodeValue(v)
+		if skipCorrupted(c.definition.corruptedHandler, k, err) {
+			return value, errSkipElement
+		}
+		return value, err
 	})
 }
@@ -873,6 +873,100 @@ func TestCollection_customErrKeyExists(t *testing.T) {
 	})
 }
 
+func TestCollection_contentAddressed(t *testing.T) {
+	db := newDB(t)
+
+	definition := boltron.NewCollectionDefinition(
+		"documents",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		&boltron.CollectionOptions{
+			ContentAddressed: true,
+		},
+	)
+
+	contentSize := func(tx *bolt.Tx) int {
+		b := tx.Bucket([]byte("boltron: collection content: documents"))
+		if b == nil {
+			return 0
+		}
+		var n int
+		_ = b.ForEach(func(_, _ []byte) error {
+			n++
+			return nil
+		})
+		return n
+	}
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		documents := definition.Collection(tx)
+
+		for _, k := range []string{"a", "b", "c"} {
+			_, err := documents.Save(k, "same content", false)
+			assertErrorFail(t, "", err, nil)
+		}
+		_, err := documents.Save("d", "other content", false)
+		assertErrorFail(t, "", err, nil)
+
+		_, err = documents.Save("d", "changed content", false)
+		assertErrorFail(t, "", err, boltron.ErrKeyExists)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		documents := definition.Collection(tx)
+
+		assert(t, "", contentSize(tx), 2)
+
+		v, err := documents.Get("b")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, "same content")
+
+		var values []string
+		_, err = documents.IterateValues(nil, false, func(v string) (bool, error) {
+			values = append(values, v)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []string{"same content", "same content", "same content", "other content"})
+
+		elements, _, _, err := documents.Page(1, 2, true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", elements, []boltron.CollectionElement[string, string]{
+			{Key: "d", Value: "other content"},
+			{Key: "c", Value: "same content"},
+		})
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		documents := definition.Collection(tx)
+
+		overwritten, err := documents.Save("a", "other content", true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", overwritten, true)
+
+		err = documents.Delete("b", true)
+		assertErrorFail(t, "", err, nil)
+
+		assert(t, "", contentSize(tx), 2)
+
+		err = documents.Delete("c", true)
+		assertErrorFail(t, "", err, nil)
+
+		assert(t, "unreferenced content removed", contentSize(tx), 1)
+
+		v, err := documents.Get("a")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, "other content")
+
+		err = documents.Delete("a", true)
+		assertErrorFail(t, "", err, nil)
+		err = documents.Delete("d", true)
+		assertErrorFail(t, "", err, nil)
+
+		assert(t, "", contentSize(tx), 0)
+	})
+}
+
 func newRecordsDB(t testing.TB) *bolt.DB {
 	t.Helper()
 
@@ -917,3 +1011,92 @@ func recordValues(is ...int) []*Record {
 	}
 	return s
 }
+
+func TestCollection_contentAddressedPlainValues(t *testing.T) {
+	db := newDB(t)
+
+	plainDefinition := boltron.NewCollectionDefinition(
+		"documents",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		nil,
+	)
+	definition := boltron.NewCollectionDefinition(
+		"documents",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		&boltron.CollectionOptions{
+			ContentAddressed: true,
+		},
+	)
+	// collection with the name that would collide with the content bucket
+	// name of documents if it would be formed with a suffix
+	contentDefinition := boltron.NewCollectionDefinition(
+		"documents content",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		nil,
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		for _, k := range []string{"a", "b"} {
+			_, err := plainDefinition.Collection(tx).Save(k, "plain "+k, false)
+			assertErrorFail(t, "", err, nil)
+		}
+		// value with the length of the content reference
+		_, err := plainDefinition.Collection(tx).Save("c", "0123456789abcdef0123456789abcdef", false)
+		assertErrorFail(t, "", err, nil)
+
+		_, err = contentDefinition.Collection(tx).Save("x", "unrelated", false)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		documents := definition.Collection(tx)
+
+		v, err := documents.Get("a")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, "plain a")
+
+		v, err = documents.Get("c")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, "0123456789abcdef0123456789abcdef")
+
+		// saving the same value converts it to the content reference
+		overwritten, err := documents.Save("b", "plain b", false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", overwritten, false)
+		assert(t, "", len(tx.Bucket([]byte("boltron: collection: documents")).Get([]byte("b"))), 32)
+
+		_, err = documents.Save("a", "plain b", false)
+		assertError(t, "", err, boltron.ErrKeyExists)
+
+		overwritten, err = documents.Save("a", "plain b", true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", overwritten, true)
+
+		err = documents.Delete("b", true)
+		assertErrorFail(t, "", err, nil)
+
+		err = documents.Delete("c", true)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		v, err := definition.Collection(tx).Get("a")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, "plain b")
+
+		size, err := definition.Collection(tx).Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 1)
+
+		v, err = contentDefinition.Collection(tx).Get("x")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, "unrelated")
+
+		size, err = contentDefinition.Collection(tx).Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 1)
+	})
+}
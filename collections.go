@@ -24,8 +24,10 @@ type CollectionsDefinition[C, K, V any] struct {
 	fillPercent           float64
 	uniqueKeys            bool
 	errCollectionNotFound error
+	errCollectionExists   error
 	errKeyNotFound        error
 	errKeyExists          error
+	corruptedHandler      func(key []byte, err error)
 }
 
 // CollectionsOptions provides additional configuration for a Collections
@@ -39,11 +41,22 @@ type CollectionsOptions struct {
 	// ErrCollectionNotFound is returned if the collection identified by its key
 	// is not found.
 	ErrCollectionNotFound error
+	// ErrCollectionExists is returned if the collection is renamed to the
+	// collection key of an existing collection.
+	ErrCollectionExists error
 	// ErrKeyNotFound is returned if the key is not found.
 	ErrKeyNotFound error
 	// ErrKeyExists is returned if UniqueValues option is set to true and the
 	// key already exists in another collection.
 	ErrKeyExists error
+	// CorruptedHandler is called with the key of every element that is skipped
+	// by iteration and pagination methods of Collections and of every
+	// Collection because it can not be decoded with ErrCorrupted error, as
+	// CollectionOptions CorruptedHandler is for a single Collection. If it is
+	// nil, iteration is aborted. Skipped elements are still counted in total
+	// elements and pages returned by pagination methods, so a page may contain
+	// fewer elements than the limit.
+	CorruptedHandler func(key []byte, err error)
 }
 
 // NewCollectionsDefinition constructs a new CollectionsDefinition with a unique
@@ -67,8 +80,10 @@ func NewCollectionsDefinition[C, K, V any](
 		fillPercent:           o.FillPercent,
 		uniqueKeys:            o.UniqueKeys,
 		errCollectionNotFound: withDefaultError(o.ErrCollectionNotFound, ErrNotFound),
+		errCollectionExists:   withDefaultError(o.ErrCollectionExists, ErrKeyExists),
 		errKeyNotFound:        withDefaultError(o.ErrKeyNotFound, ErrNotFound),
 		errKeyExists:          withDefaultError(o.ErrKeyExists, ErrKeyExists),
+		corruptedHandler:      o.CorruptedHandler,
 	}
 }
 
@@ -131,11 +146,12 @@ func (c *Collections[C, K, V]) Collection(key C) (collection *Collection[K, V],
 	return &Collection[K, V]{
 		tx: c.tx,
 		definition: &CollectionDefinition[K, V]{
-			bucketPath:    [][]byte{c.definition.bucketNameCollections, k},
-			keyEncoding:   c.definition.keyEncoding,
-			valueEncoding: c.definition.valueEncoding,
-			fillPercent:   c.definition.fillPercent,
-			errNotFound:   c.definition.errKeyNotFound,
+			bucketPath:       [][]byte{c.definition.bucketNameCollections, k},
+			keyEncoding:      c.definition.keyEncoding,
+			valueEncoding:    c.definition.valueEncoding,
+			fillPercent:      c.definition.fillPercent,
+			errNotFound:      c.definition.errKeyNotFound,
+			corruptedHandler: c.definition.corruptedHandler,
 			saveCallback: func(key []byte) error {
 				keysBucket, err := c.keysBucket(true)
 				if err != nil {
@@ -351,6 +367,254 @@ func (c *Collections[C, K, V]) DeleteKey(key K, ensure bool) error {
 	return nil
 }
 
+// CollectionsConflict specifies how the key that exists in both source and
+// destination collections is handled when key/value pairs are copied or merged.
+type CollectionsConflict uint8
+
+// Collections conflict policies.
+const (
+	// CollectionsConflictError aborts the operation with configured
+	// ErrKeyExists error.
+	CollectionsConflictError CollectionsConflict = iota
+	// CollectionsConflictSkip keeps the value in the destination collection.
+	CollectionsConflictSkip
+	// CollectionsConflictOverwrite replaces the value in the destination
+	// collection with the one from the source collection.
+	CollectionsConflictOverwrite
+)
+
+// MoveKey moves the key and its value from one collection to another. If the
+// from collection or the key in it do not exist, configured
+// ErrCollectionNotFound or ErrKeyNotFound is returned. If the overwrite flag is
+// set to false and the key already exists in the to collection, configured
+// ErrKeyExists is returned.
+func (c *Collections[C, K, V]) MoveKey(key K, from, to C, overwrite bool) error {
+	k, err := c.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+	fck, err := c.definition.collectionKeyEncoding.Encode(from)
+	if err != nil {
+		return fmt.Errorf("encode from collection key: %w", err)
+	}
+	tck, err := c.definition.collectionKeyEncoding.Encode(to)
+	if err != nil {
+		return fmt.Errorf("encode to collection key: %w", err)
+	}
+
+	collectionsBucket, err := c.collectionsBucket(false)
+	if err != nil {
+		return fmt.Errorf("collections bucket: %w", err)
+	}
+	if collectionsBucket == nil {
+		return c.definition.errCollectionNotFound
+	}
+	fromBucket := collectionsBucket.Bucket(fck)
+	if fromBucket == nil {
+		return c.definition.errCollectionNotFound
+	}
+	v := fromBucket.Get(k)
+	if v == nil {
+		return c.definition.errKeyNotFound
+	}
+	if bytes.Equal(fck, tck) {
+		return nil
+	}
+	v = append([]byte(nil), v...)
+
+	toBucket, err := c.collectionBucket(collectionsBucket, tck)
+	if err != nil {
+		return fmt.Errorf("collection bucket: %w", err)
+	}
+	if toBucket.Get(k) != nil && !overwrite {
+		return c.definition.errKeyExists
+	}
+
+	keysBucket, err := c.keysBucket(true)
+	if err != nil {
+		return fmt.Errorf("keys bucket: %w", err)
+	}
+	if err := c.moveKeyIndex(keysBucket, k, fck, tck); err != nil {
+		return err
+	}
+
+	if err := fromBucket.Delete(k); err != nil {
+		return fmt.Errorf("delete key: %w", err)
+	}
+	if err := toBucket.Put(k, v); err != nil {
+		return fmt.Errorf("put key: %w", err)
+	}
+
+	return nil
+}
+
+// CopyCollection saves all key/value pairs from the src collection to the dst
+// collection, handling keys that exist in both of them by the conflict policy.
+// If the src collection does not exist, configured ErrCollectionNotFound is
+// returned. With UniqueKeys option, configured ErrKeyExists is returned for
+// any copied key.
+func (c *Collections[C, K, V]) CopyCollection(src, dst C, conflict CollectionsConflict) error {
+	return c.transferCollection(src, dst, conflict, false, false)
+}
+
+// RenameCollection changes the collection key of the collection. If the from
+// collection does not exist, configured ErrCollectionNotFound is returned and
+// if the to collection already exists, configured ErrCollectionExists is
+// returned.
+func (c *Collections[C, K, V]) RenameCollection(from, to C) error {
+	return c.transferCollection(from, to, CollectionsConflictError, true, true)
+}
+
+// MergeCollections moves all key/value pairs from the src collection to the dst
+// collection, handling keys that exist in both of them by the conflict policy,
+// and removes the src collection. If the src collection does not exist,
+// configured ErrCollectionNotFound is returned.
+func (c *Collections[C, K, V]) MergeCollections(src, dst C, conflict CollectionsConflict) error {
+	return c.transferCollection(src, dst, conflict, true, false)
+}
+
+// transferCollection puts all key/value pairs from the src to the dst
+// collection, keeping the keys bucket consistent, and removes the src
+// collection if move is true. If rename is true, the dst collection must not
+// exist.
+func (c *Collections[C, K, V]) transferCollection(src, dst C, conflict CollectionsConflict, move, rename bool) error {
+	sck, err := c.definition.collectionKeyEncoding.Encode(src)
+	if err != nil {
+		return fmt.Errorf("encode source collection key: %w", err)
+	}
+	dck, err := c.definition.collectionKeyEncoding.Encode(dst)
+	if err != nil {
+		return fmt.Errorf("encode destination collection key: %w", err)
+	}
+
+	collectionsBucket, err := c.collectionsBucket(false)
+	if err != nil {
+		return fmt.Errorf("collections bucket: %w", err)
+	}
+	if collectionsBucket == nil {
+		return c.definition.errCollectionNotFound
+	}
+	srcBucket := collectionsBucket.Bucket(sck)
+	if srcBucket == nil {
+		return c.definition.errCollectionNotFound
+	}
+	if bytes.Equal(sck, dck) {
+		return nil
+	}
+	if rename {
+		if b := collectionsBucket.Bucket(dck); b != nil {
+			if k, _ := b.Cursor().First(); k != nil {
+				return c.definition.errCollectionExists
+			}
+		}
+	}
+
+	dstBucket, err := c.collectionBucket(collectionsBucket, dck)
+	if err != nil {
+		return fmt.Errorf("collection bucket: %w", err)
+	}
+
+	keysBucket, err := c.keysBucket(true)
+	if err != nil {
+		return fmt.Errorf("keys bucket: %w", err)
+	}
+
+	if err := srcBucket.ForEach(func(k, v []byte) error {
+		k = append([]byte(nil), k...)
+		v = append([]byte(nil), v...)
+
+		from := sck
+		if !move {
+			from = nil
+		}
+		to := dck
+		if dstBucket.Get(k) != nil {
+			switch conflict {
+			case CollectionsConflictSkip:
+				to = nil
+			case CollectionsConflictOverwrite:
+			default:
+				return c.definition.errKeyExists
+			}
+		}
+
+		if err := c.moveKeyIndex(keysBucket, k, from, to); err != nil {
+			return err
+		}
+
+		if to == nil {
+			return nil
+		}
+		if err := dstBucket.Put(k, v); err != nil {
+			return fmt.Errorf("put key: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if move {
+		if err := collectionsBucket.DeleteBucket(sck); err != nil {
+			return fmt.Errorf("delete source collection bucket: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// collectionBucket returns the bucket of the collection with the encoded
+// collection key, creating it if it does not exist.
+func (c *Collections[C, K, V]) collectionBucket(collectionsBucket *bolt.Bucket, ck []byte) (*bolt.Bucket, error) {
+	bucket, err := collectionsBucket.CreateBucketIfNotExists(ck)
+	if err != nil {
+		return nil, err
+	}
+	if c.definition.fillPercent > 0 {
+		bucket.FillPercent = c.definition.fillPercent
+	}
+	return bucket, nil
+}
+
+// moveKeyIndex replaces the encoded from collection key with the to collection
+// key for the encoded key in the keys bucket. Any of the collection keys can be
+// nil to only add or remove the key. If UniqueKeys option is set and the key
+// exists in another collection, configured ErrKeyExists is returned.
+func (c *Collections[C, K, V]) moveKeyIndex(keysBucket *bolt.Bucket, k, from, to []byte) error {
+	keyBucket := keysBucket.Bucket(k)
+	if keyBucket == nil {
+		if to == nil {
+			return nil
+		}
+		b, err := keysBucket.CreateBucket(k)
+		if err != nil {
+			return fmt.Errorf("create key bucket: %w", err)
+		}
+		keyBucket = b
+	}
+	if from != nil {
+		if err := keyBucket.Delete(from); err != nil {
+			return fmt.Errorf("delete collection key from key bucket: %w", err)
+		}
+	}
+	if to != nil {
+		if c.definition.uniqueKeys {
+			firstKey, _ := keyBucket.Cursor().First()
+			if firstKey != nil && !bytes.Equal(firstKey, to) {
+				return c.definition.errKeyExists
+			}
+		}
+		if err := keyBucket.Put(to, nil); err != nil {
+			return fmt.Errorf("put collection key to key bucket: %w", err)
+		}
+	}
+	if firstKey, _ := keyBucket.Cursor().First(); firstKey == nil {
+		if err := keysBucket.DeleteBucket(k); err != nil {
+			return fmt.Errorf("delete empty key bucket: %w", err)
+		}
+	}
+	return nil
+}
+
 // Size returns the number of collections.
 func (c *Collections[C, K, V]) Size() (int, error) {
 	collectionsBucket, err := c.collectionsBucket(false)
@@ -374,9 +638,12 @@ func (c *Collections[C, K, V]) IterateCollections(start *C, reverse bool, f func
 	if collectionsBucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(collectionsBucket, c.definition.collectionKeyEncoding, start, reverse, func(k, _ []byte) (bool, error) {
+	return iterateKeys(collectionsBucket, c.definition.collectionKeyEncoding, c.definition.corruptedHandler, start, reverse, func(k, _ []byte) (bool, error) {
 		key, err := c.definition.collectionKeyEncoding.Decode(k)
 		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode collection key: %w", err)
 		}
 
@@ -394,8 +661,12 @@ func (c *Collections[C, K, V]) PageOfCollections(number, limit int, reverse bool
 	if collectionsBucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(collectionsBucket, true, number, limit, reverse, func(k, _ []byte) (C, error) {
-		return c.definition.collectionKeyEncoding.Decode(k)
+	return page(collectionsBucket, true, number, limit, reverse, func(k, _ []byte) (e C, err error) {
+		e, err = c.definition.collectionKeyEncoding.Decode(k)
+		if skipCorrupted(c.definition.corruptedHandler, k, err) {
+			return e, errSkipElement
+		}
+		return e, err
 	})
 }
 
@@ -419,9 +690,12 @@ func (c *Collections[C, K, V]) IterateCollectionsWithKey(key K, start *C, revers
 	if keyBucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(keyBucket, c.definition.collectionKeyEncoding, start, reverse, func(k, _ []byte) (bool, error) {
+	return iterateKeys(keyBucket, c.definition.collectionKeyEncoding, c.definition.corruptedHandler, start, reverse, func(k, _ []byte) (bool, error) {
 		key, err := c.definition.collectionKeyEncoding.Decode(k)
 		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode collection key: %w", err)
 		}
 
@@ -447,8 +721,12 @@ func (c *Collections[C, K, V]) PageOfCollectionsWithKey(key K, number, limit int
 	if keyBucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(keyBucket, false, number, limit, reverse, func(k, _ []byte) (C, error) {
-		return c.definition.collectionKeyEncoding.Decode(k)
+	return page(keyBucket, false, number, limit, reverse, func(k, _ []byte) (e C, err error) {
+		e, err = c.definition.collectionKeyEncoding.Decode(k)
+		if skipCorrupted(c.definition.corruptedHandler, k, err) {
+			return e, errSkipElement
+		}
+		return e, err
 	})
 }
 
@@ -463,9 +741,12 @@ func (c *Collections[C, K, V]) IterateKeys(start *K, reverse bool, f func(K) (bo
 	if keysBucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(keysBucket, c.definition.keyEncoding, start, reverse, func(k, _ []byte) (bool, error) {
+	return iterateKeys(keysBucket, c.definition.keyEncoding, c.definition.corruptedHandler, start, reverse, func(k, _ []byte) (bool, error) {
 		key, err := c.definition.keyEncoding.Decode(k)
 		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode key: %w", err)
 		}
 
@@ -482,7 +763,266 @@ func (c *Collections[C, K, V]) PageOfKeys(number, limit int, reverse bool) (s []
 	if keysBucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(keysBucket, true, number, limit, reverse, func(k, _ []byte) (K, error) {
-		return c.definition.keyEncoding.Decode(k)
+	return page(keysBucket, true, number, limit, reverse, func(k, _ []byte) (e K, err error) {
+		e, err = c.definition.keyEncoding.Decode(k)
+		if skipCorrupted(c.definition.corruptedHandler, k, err) {
+			return e, errSkipElement
+		}
+		return e, err
 	})
 }
+
+// CollectionsKey identifies a key in a collection. It is used as the start and
+// the next element of the iteration over all collections.
+type CollectionsKey[C, K any] struct {
+	Collection C
+	Key        K
+}
+
+// IterateAll iterates over all keys and values in all collections, in the
+// lexicographical order of collection keys and keys within every collection.
+// If the callback function f returns false, the iteration stops and the next
+// can be used to continue the iteration.
+func (c *Collections[C, K, V]) IterateAll(start *CollectionsKey[C, K], reverse bool, f func(C, K, V) (bool, error)) (next *CollectionsKey[C, K], err error) {
+	collectionsBucket, err := c.collectionsBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("collections bucket: %w", err)
+	}
+	if collectionsBucket == nil {
+		return nil, nil
+	}
+
+	var startCollectionKey, startKey []byte
+	if start != nil {
+		startCollectionKey, err = c.definition.collectionKeyEncoding.Encode(start.Collection)
+		if err != nil {
+			return nil, fmt.Errorf("encode start collection key: %w", err)
+		}
+		startKey, err = c.definition.keyEncoding.Encode(start.Key)
+		if err != nil {
+			return nil, fmt.Errorf("encode start key: %w", err)
+		}
+	}
+
+	var nextCollectionKey, nextKey []byte
+	var stopped bool
+	cursor := collectionsBucket.Cursor()
+	first, nextCollection := cursor.First, cursor.Next
+	if reverse {
+		first, nextCollection = cursor.Last, cursor.Prev
+	}
+	// setNext sets the continuation to the first key from k in the collection
+	// bucket that can be decoded, skipping corrupted collection keys and keys.
+	setNext := func(collectionBucket *bolt.Bucket, ck, k []byte) (bool, error) {
+		if _, err := c.definition.collectionKeyEncoding.Decode(ck); err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, ck, err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("decode next collection key: %w", err)
+		}
+		for ; k != nil; k, _ = adjacent(collectionBucket, k, reverse) {
+			_, err := c.definition.keyEncoding.Decode(k)
+			if err == nil {
+				nextCollectionKey, nextKey = ck, k
+				return true, nil
+			}
+			if !skipCorrupted(c.definition.corruptedHandler, k, err) {
+				return false, fmt.Errorf("decode next key: %w", err)
+			}
+		}
+		return false, nil
+	}
+
+	var ck []byte
+	if startCollectionKey == nil {
+		ck, _ = first()
+	} else {
+		ck, _ = cursor.Seek(startCollectionKey)
+		if reverse && !bytes.Equal(ck, startCollectionKey) {
+			ck, _ = nextCollection()
+		}
+		if !bytes.Equal(ck, startCollectionKey) {
+			startKey = nil
+		}
+	}
+	for ; ck != nil; ck, _ = nextCollection() {
+		collectionBucket := collectionsBucket.Bucket(ck)
+		if collectionBucket == nil {
+			continue
+		}
+		if stopped {
+			// find the first key of the next collection to continue from
+			var k []byte
+			if reverse {
+				k, _ = collectionBucket.Cursor().Last()
+			} else {
+				k, _ = collectionBucket.Cursor().First()
+			}
+			found, err := setNext(collectionBucket, ck, k)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				break
+			}
+			continue
+		}
+
+		collectionKey, err := c.definition.collectionKeyEncoding.Decode(ck)
+		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, ck, err) {
+				startKey = nil
+				continue
+			}
+			return nil, fmt.Errorf("decode collection key: %w", err)
+		}
+		k, _, err := iterate(collectionBucket, startKey, reverse, func(k, v []byte) (bool, error) {
+			key, err := c.definition.keyEncoding.Decode(k)
+			if err != nil {
+				if skipCorrupted(c.definition.corruptedHandler, k, err) {
+					return true, nil
+				}
+				return false, fmt.Errorf("decode key: %w", err)
+			}
+
+			value, err := c.definition.valueEncoding.Decode(v)
+			if err != nil {
+				if skipCorrupted(c.definition.corruptedHandler, k, err) {
+					return true, nil
+				}
+				return false, fmt.Errorf("decode value: %w", err)
+			}
+
+			cont, err := f(collectionKey, key, value)
+			stopped = !cont
+			return cont, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		startKey = nil
+		if k != nil {
+			found, err := setNext(collectionBucket, ck, k)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				break
+			}
+		}
+	}
+
+	if nextCollectionKey == nil {
+		return nil, nil
+	}
+	collectionKey, err := c.definition.collectionKeyEncoding.Decode(nextCollectionKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode next collection key: %w", err)
+	}
+	key, err := c.definition.keyEncoding.Decode(nextKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode next key: %w", err)
+	}
+	return &CollectionsKey[C, K]{
+		Collection: collectionKey,
+		Key:        key,
+	}, nil
+}
+
+// CollectionsElement is the type returned by Collections pagination methods as
+// slice elements that contain collection key, key and value.
+type CollectionsElement[C, K, V any] struct {
+	Collection C
+	Key        K
+	Value      V
+}
+
+// PageOfAll returns at most a limit of keys and values from all collections at
+// the provided page number, in the order of IterateAll.
+func (c *Collections[C, K, V]) PageOfAll(number, limit int, reverse bool) (s []CollectionsElement[C, K, V], totalElements, pages int, err error) {
+	if number <= 0 {
+		return nil, 0, 0, ErrInvalidPageNumber
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	collectionsBucket, err := c.collectionsBucket(false)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("collections bucket: %w", err)
+	}
+	if collectionsBucket == nil {
+		return nil, 0, 0, nil
+	}
+
+	start := (number - 1) * limit
+	end := number * limit
+
+	cursor := collectionsBucket.Cursor()
+	first, next := cursor.First, cursor.Next
+	if reverse {
+		first, next = cursor.Last, cursor.Prev
+	}
+	for ck, _ := first(); ck != nil; ck, _ = next() {
+		collectionBucket := collectionsBucket.Bucket(ck)
+		if collectionBucket == nil {
+			continue
+		}
+		n := size(collectionBucket, false)
+		if totalElements+n <= start || totalElements >= end {
+			// skip collections that are not on the page
+			totalElements += n
+			continue
+		}
+
+		collectionKey, err := c.definition.collectionKeyEncoding.Decode(ck)
+		if err != nil {
+			if skipCorrupted(c.definition.corruptedHandler, ck, err) {
+				totalElements += n
+				continue
+			}
+			return nil, 0, 0, fmt.Errorf("decode collection key: %w", err)
+		}
+		count := totalElements
+		if _, _, err := iterate(collectionBucket, nil, reverse, func(k, v []byte) (bool, error) {
+			count++
+			if count <= start {
+				return true, nil
+			}
+			if count > end {
+				return false, nil
+			}
+
+			key, err := c.definition.keyEncoding.Decode(k)
+			if err != nil {
+				if skipCorrupted(c.definition.corruptedHandler, k, err) {
+					return true, nil
+				}
+				return false, fmt.Errorf("decode key: %w", err)
+			}
+
+			value, err := c.definition.valueEncoding.Decode(v)
+			if err != nil {
+				if skipCorrupted(c.definition.corruptedHandler, k, err) {
+					return true, nil
+				}
+				return false, fmt.Errorf("decode value: %w", err)
+			}
+
+			s = append(s, CollectionsElement[C, K, V]{
+				Collection: collectionKey,
+				Key:        key,
+				Value:      value,
+			})
+			return true, nil
+		}); err != nil {
+			return nil, 0, 0, err
+		}
+		totalElements += n
+	}
+
+	pages = totalElements / limit
+	if totalElements%limit != 0 {
+		pages++
+	}
+	return s, totalElements, pages, nil
+}
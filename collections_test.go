@@ -8,6 +8,7 @@ package boltron_test
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"testing"
 
 	bolt "go.etcd.io/bbolt"
@@ -1084,6 +1085,291 @@ func TestCollections_uniqueKeys_customErrKeyExists(t *testing.T) {
 	})
 }
 
+func TestCollections_moveKey(t *testing.T) {
+	db := electionsDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		elections := electionsDefinition.Collections(tx)
+
+		err := elections.MoveKey("chriss", 0, 7, false)
+		assertErrorFail(t, "", err, nil)
+
+		err = elections.MoveKey("alice", 0, 7, false)
+		assertError(t, "", err, boltron.ErrKeyExists)
+
+		err = elections.MoveKey("alice", 0, 7, true)
+		assertErrorFail(t, "", err, nil)
+
+		err = elections.MoveKey("chriss", 0, 7, false)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		err = elections.MoveKey("chriss", 100, 7, false)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		assert(t, "", electionsVotes(t, elections, 0), map[string]int{"bob": 1, "dave": 0, "edit": 2})
+		assert(t, "", electionsVotes(t, elections, 7), map[string]int{"alice": 1, "chriss": 2, "dave": 0})
+		assert(t, "", electionsCollectionsWithKey(t, elections, "chriss"), []uint64{7})
+		assert(t, "", electionsCollectionsWithKey(t, elections, "alice"), []uint64{5, 7})
+	})
+}
+
+func TestCollections_copyCollection(t *testing.T) {
+	db := electionsDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		elections := electionsDefinition.Collections(tx)
+
+		err := elections.CopyCollection(7, 5, boltron.CollectionsConflictError)
+		assertError(t, "", err, boltron.ErrKeyExists)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		elections := electionsDefinition.Collections(tx)
+
+		err := elections.CopyCollection(100, 5, boltron.CollectionsConflictError)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		err = elections.CopyCollection(7, 5, boltron.CollectionsConflictSkip)
+		assertErrorFail(t, "", err, nil)
+
+		assert(t, "", electionsVotes(t, elections, 5), map[string]int{"alice": 0, "bob": 4, "dave": 2, "mick": 2})
+
+		err = elections.CopyCollection(0, 5, boltron.CollectionsConflictOverwrite)
+		assertErrorFail(t, "", err, nil)
+
+		assert(t, "", electionsVotes(t, elections, 5), map[string]int{"alice": 1, "bob": 1, "chriss": 2, "dave": 0, "edit": 2, "mick": 2})
+		assert(t, "", electionsVotes(t, elections, 0), map[string]int{"alice": 1, "bob": 1, "chriss": 2, "dave": 0, "edit": 2})
+
+		err = elections.CopyCollection(0, 10, boltron.CollectionsConflictError)
+		assertErrorFail(t, "", err, nil)
+
+		assert(t, "", electionsVotes(t, elections, 10), map[string]int{"alice": 1, "bob": 1, "chriss": 2, "dave": 0, "edit": 2})
+		assert(t, "", electionsCollectionsWithKey(t, elections, "chriss"), []uint64{0, 5, 10})
+	})
+}
+
+func TestCollections_renameCollection(t *testing.T) {
+	db := electionsDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		elections := electionsDefinition.Collections(tx)
+
+		err := elections.RenameCollection(7, 5)
+		assertError(t, "", err, boltron.ErrKeyExists)
+
+		err = elections.RenameCollection(100, 10)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		err = elections.RenameCollection(7, 10)
+		assertErrorFail(t, "", err, nil)
+
+		has, err := elections.HasCollection(7)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		assert(t, "", electionsVotes(t, elections, 10), map[string]int{"alice": 0, "dave": 0})
+		assert(t, "", electionsCollectionsWithKey(t, elections, "alice"), []uint64{0, 5, 10})
+		assert(t, "", electionsCollectionsWithKey(t, elections, "dave"), []uint64{0, 5, 6, 10})
+	})
+}
+
+func TestCollections_mergeCollections(t *testing.T) {
+	db := electionsDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		elections := electionsDefinition.Collections(tx)
+
+		err := elections.MergeCollections(5, 0, boltron.CollectionsConflictSkip)
+		assertErrorFail(t, "", err, nil)
+
+		has, err := elections.HasCollection(5)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		assert(t, "", electionsVotes(t, elections, 0), map[string]int{"alice": 1, "bob": 1, "chriss": 2, "dave": 0, "edit": 2, "mick": 2})
+		assert(t, "", electionsCollectionsWithKey(t, elections, "mick"), []uint64{0})
+		assert(t, "", electionsCollectionsWithKey(t, elections, "bob"), []uint64{0, 6})
+
+		err = elections.MergeCollections(6, 0, boltron.CollectionsConflictOverwrite)
+		assertErrorFail(t, "", err, nil)
+
+		assert(t, "", electionsVotes(t, elections, 0), map[string]int{"alice": 1, "bob": 0, "chriss": 2, "dave": 0, "edit": 2, "george": 1, "john": 1, "mick": 2, "paul": 0, "ringo": 2})
+		assert(t, "", electionsCollectionsWithKey(t, elections, "bob"), []uint64{0})
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		size, err := electionsDefinition.Collections(tx).Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 2)
+	})
+}
+
+func TestCollections_mergeCollections_uniqueKeys(t *testing.T) {
+	customElectionsDefinition := boltron.NewCollectionsDefinition(
+		"elections",
+		boltron.Uint64BinaryEncoding,       // election id
+		boltron.StringEncoding,             // voter id
+		boltron.NewJSONEncoding[*ballot](), // ballot with a vote
+		&boltron.CollectionsOptions{
+			UniqueKeys: true,
+		},
+	)
+
+	db := newDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		elections := customElectionsDefinition.Collections(tx)
+
+		for i, voter := range []string{"john", "paul", "george"} {
+			election, _, err := elections.Collection(uint64(i % 2))
+			assertErrorFail(t, "", err, nil)
+
+			_, err = election.Save(voter, newBallot(i), false)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		err := elections.CopyCollection(0, 2, boltron.CollectionsConflictError)
+		assertError(t, "", err, boltron.ErrKeyExists)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		elections := customElectionsDefinition.Collections(tx)
+
+		err := elections.MergeCollections(0, 1, boltron.CollectionsConflictError)
+		assertErrorFail(t, "", err, nil)
+
+		err = elections.MoveKey("john", 1, 2, false)
+		assertErrorFail(t, "", err, nil)
+
+		election0, _, err := elections.Collection(0)
+		assertErrorFail(t, "", err, nil)
+
+		_, err = election0.Save("paul", newBallot(0), false)
+		assertError(t, "", err, boltron.ErrKeyExists)
+
+		_, err = election0.Save("ringo", newBallot(0), false)
+		assertErrorFail(t, "", err, nil)
+
+		assert(t, "", electionsCollectionsWithKey(t, elections, "john"), []uint64{2})
+		assert(t, "", electionsCollectionsWithKey(t, elections, "george"), []uint64{1})
+	})
+}
+
+func electionsVotes(t testing.TB, elections *boltron.Collections[uint64, string, *ballot], election uint64) map[string]int {
+	t.Helper()
+
+	collection, _, err := elections.Collection(election)
+	assertErrorFail(t, "", err, nil)
+
+	votes := make(map[string]int)
+	_, err = collection.Iterate(nil, false, func(voter string, b *ballot) (bool, error) {
+		votes[voter] = b.Vote
+		return true, nil
+	})
+	assertErrorFail(t, "", err, nil)
+	return votes
+}
+
+func electionsCollectionsWithKey(t testing.TB, elections *boltron.Collections[uint64, string, *ballot], voter string) (s []uint64) {
+	t.Helper()
+
+	_, err := elections.IterateCollectionsWithKey(voter, nil, false, func(election uint64) (bool, error) {
+		s = append(s, election)
+		return true, nil
+	})
+	assertErrorFail(t, "", err, nil)
+	return s
+}
+
+func TestCollections_iterateAll(t *testing.T) {
+	db := electionsDB(t)
+
+	want := make([]boltron.CollectionsElement[uint64, string, *ballot], 0, len(testElections))
+	for _, e := range testElections {
+		want = append(want, boltron.CollectionsElement[uint64, string, *ballot]{
+			Collection: e.Election,
+			Key:        e.Voter,
+			Value:      e.Ballot,
+		})
+	}
+	sort.Slice(want, func(i, j int) bool {
+		if want[i].Collection != want[j].Collection {
+			return want[i].Collection < want[j].Collection
+		}
+		return want[i].Key < want[j].Key
+	})
+	wantReverse := make([]boltron.CollectionsElement[uint64, string, *ballot], 0, len(want))
+	for i := len(want) - 1; i >= 0; i-- {
+		wantReverse = append(wantReverse, want[i])
+	}
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		elections := electionsDefinition.Collections(tx)
+
+		for _, reverse := range []bool{false, true} {
+			for _, limit := range []int{1, 4, 5, 100} {
+				var got []boltron.CollectionsElement[uint64, string, *ballot]
+				var next *boltron.CollectionsKey[uint64, string]
+				for {
+					var count int
+					var err error
+					next, err = elections.IterateAll(next, reverse, func(c uint64, k string, v *ballot) (bool, error) {
+						got = append(got, boltron.CollectionsElement[uint64, string, *ballot]{
+							Collection: c,
+							Key:        k,
+							Value:      v,
+						})
+						count++
+						return count < limit, nil
+					})
+					assertErrorFail(t, "", err, nil)
+					if next == nil {
+						break
+					}
+				}
+				if reverse {
+					assert(t, fmt.Sprintf("limit %v", limit), got, wantReverse)
+				} else {
+					assert(t, fmt.Sprintf("limit %v", limit), got, want)
+				}
+			}
+		}
+
+		var got []string
+		_, err := elections.IterateAll(&boltron.CollectionsKey[uint64, string]{Collection: 6, Key: "fred"}, false, func(c uint64, k string, _ *ballot) (bool, error) {
+			got = append(got, fmt.Sprint(c, k))
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", got, []string{"6george", "6john", "6paul", "6ringo", "7alice", "7dave"})
+
+		got = nil
+		_, err = elections.IterateAll(&boltron.CollectionsKey[uint64, string]{Collection: 4, Key: "zed"}, true, func(c uint64, k string, _ *ballot) (bool, error) {
+			got = append(got, fmt.Sprint(c, k))
+			return len(got) < 2, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", got, []string{"0edit", "0dave"})
+
+		page, totalElements, pages, err := elections.PageOfAll(2, 5, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", page, want[5:10])
+		assert(t, "", totalElements, len(want))
+		assert(t, "", pages, 4)
+
+		page, _, _, err = elections.PageOfAll(4, 5, true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", page, wantReverse[15:])
+
+		page, _, _, err = elections.PageOfAll(5, 5, true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(page), 0)
+
+		_, _, _, err = elections.PageOfAll(0, 5, true)
+		assertError(t, "", err, boltron.ErrInvalidPageNumber)
+	})
+}
+
 func electionsDB(t testing.TB) *bolt.DB {
 	t.Helper()
 
@@ -0,0 +1,202 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// compressedEncodingMagic is the first byte of every value encoded by
+// CompressedEncoding. Values without it are considered to be stored before the
+// compression was introduced and they are decoded as they are. The byte never
+// appears in valid UTF-8 text.
+const compressedEncodingMagic = 0xfd
+
+// DefaultCompressedEncodingMaxSize is the maximal length of the encoded value
+// before the compression if it is not set in CompressedEncodingOptions.
+const DefaultCompressedEncodingMaxSize = 64 * 1024 * 1024
+
+// Compression methods stored in the second byte of the compressed value header.
+const (
+	compressionMethodNone byte = iota
+	compressionMethodFlate
+	compressionMethodFlateDictionary
+)
+
+// CompressedEncoding compresses values encoded by another encoding using the
+// DEFLATE algorithm. Values that are not reduced in size are stored
+// uncompressed.
+//
+// Values stored by the wrapped encoding before the compression was introduced
+// are decoded as they are, but only if their first byte is not 0xfd, which
+// marks compressed values. It is safe for encodings of text, like
+// StringEncoding of valid UTF-8 strings or JSON, but not for binary
+// encodings that may produce that first byte, like Uint64BinaryEncoding, whose
+// existing values must be rewritten with the CompressedEncoding instead.
+type CompressedEncoding[T any] struct {
+	encoding   Encoding[T]
+	level      int
+	threshold  int
+	maxSize    int
+	dictionary []byte
+	writers    sync.Pool
+}
+
+// CompressedEncodingOptions provides additional configuration for a
+// CompressedEncoding.
+type CompressedEncodingOptions struct {
+	// Level is the flate compression level. If it is nil, the
+	// flate.DefaultCompression is used. It is a pointer so that the
+	// flate.NoCompression, which is 0, can be selected.
+	Level *int
+	// Threshold is the minimal length of the encoded value that is compressed.
+	// Shorter values are stored uncompressed as the compression would not
+	// reduce their size.
+	Threshold int
+	// MaxSize is the maximal length of the encoded value before the
+	// compression. Longer values are not encoded, and decompression of a
+	// value is stopped when its length exceeds it, which protects from
+	// corrupted or crafted data. If it is not positive,
+	// DefaultCompressedEncodingMaxSize is used.
+	MaxSize int
+	// Dictionary is a preset dictionary shared by all values which improves
+	// the compression ratio of small values with repetitive content. Values
+	// compressed with a dictionary can be decoded only with the same
+	// dictionary.
+	Dictionary []byte
+}
+
+// NewCompressedEncoding constructs a CompressedEncoding that compresses values
+// encoded by the provided encoding.
+func NewCompressedEncoding[T any](encoding Encoding[T], o *CompressedEncodingOptions) *CompressedEncoding[T] {
+	if o == nil {
+		o = new(CompressedEncodingOptions)
+	}
+	level := flate.DefaultCompression
+	if o.Level != nil {
+		level = *o.Level
+	}
+	maxSize := o.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultCompressedEncodingMaxSize
+	}
+	e := &CompressedEncoding[T]{
+		encoding:   encoding,
+		level:      level,
+		threshold:  o.Threshold,
+		maxSize:    maxSize,
+		dictionary: o.Dictionary,
+	}
+	e.writers.New = func() any {
+		w, err := flate.NewWriterDict(nil, e.level, e.dictionary)
+		if err != nil {
+			return err
+		}
+		return w
+	}
+	return e
+}
+
+// Encode serializes the value with the wrapped encoding and compresses it.
+func (e *CompressedEncoding[T]) Encode(t T) ([]byte, error) {
+	b, err := e.encoding.Encode(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > e.maxSize {
+		return nil, fmt.Errorf("encoded value length %v exceeds maximal size %v", len(b), e.maxSize)
+	}
+
+	if len(b) < e.threshold {
+		return uncompressed(b), nil
+	}
+
+	method := compressionMethodFlate
+	if e.dictionary != nil {
+		method = compressionMethodFlateDictionary
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(b)/2+2))
+	buf.WriteByte(compressedEncodingMagic)
+	buf.WriteByte(method)
+
+	w, err := e.writer(buf)
+	if err != nil {
+		return nil, err
+	}
+	defer e.writers.Put(w)
+
+	if _, err := w.Write(b); err != nil {
+		return nil, fmt.Errorf("compress: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("compress: %w", err)
+	}
+	if buf.Len() >= len(b)+2 {
+		return uncompressed(b), nil
+	}
+	return buf.Bytes(), nil
+}
+
+// uncompressed returns the encoded value with the header of values that are
+// stored without compression.
+func uncompressed(b []byte) []byte {
+	return append([]byte{compressedEncodingMagic, compressionMethodNone}, b...)
+}
+
+// Decode decompresses the value and deserializes it with the wrapped encoding.
+func (e *CompressedEncoding[T]) Decode(b []byte) (t T, err error) {
+	if len(b) == 0 || b[0] != compressedEncodingMagic {
+		return e.encoding.Decode(b)
+	}
+	if len(b) < 2 {
+		return t, errors.New("invalid compression header")
+	}
+
+	var dictionary []byte
+	switch method := b[1]; method {
+	case compressionMethodNone:
+		return e.encoding.Decode(b[2:])
+	case compressionMethodFlate:
+	case compressionMethodFlateDictionary:
+		if e.dictionary == nil {
+			return t, errors.New("compression dictionary not configured")
+		}
+		dictionary = e.dictionary
+	default:
+		return t, fmt.Errorf("unknown compression method %v", method)
+	}
+
+	r := flate.NewReaderDict(bytes.NewReader(b[2:]), dictionary)
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, int64(e.maxSize)+1))
+	if err != nil {
+		return t, fmt.Errorf("decompress: %w", err)
+	}
+	if len(data) > e.maxSize {
+		return t, fmt.Errorf("decompressed value exceeds maximal size %v", e.maxSize)
+	}
+	return e.encoding.Decode(data)
+}
+
+func (e *CompressedEncoding[T]) writer(w io.Writer) (*flate.Writer, error) {
+	switch v := e.writers.Get().(type) {
+	case *flate.Writer:
+		v.Reset(w)
+		return v, nil
+	case error:
+		return nil, fmt.Errorf("flate writer: %w", v)
+	default:
+		return nil, fmt.Errorf("unexpected flate writer type %T", v)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"compress/flate"
+	"strings"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+func TestCompressedEncoding(t *testing.T) {
+	long := strings.Repeat("boltron compressed encoding ", 100)
+	bestCompression, noCompression := flate.BestCompression, flate.NoCompression
+
+	for _, tc := range []struct {
+		name    string
+		options *boltron.CompressedEncodingOptions
+	}{
+		{
+			name: "default",
+		},
+		{
+			name: "best compression",
+			options: &boltron.CompressedEncodingOptions{
+				Level: &bestCompression,
+			},
+		},
+		{
+			name: "dictionary",
+			options: &boltron.CompressedEncodingOptions{
+				Dictionary: []byte("boltron compressed encoding"),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			encoding := boltron.NewCompressedEncoding(boltron.StringEncoding, tc.options)
+
+			for _, v := range []string{"", "short", long} {
+				b, err := encoding.Encode(v)
+				assertErrorFail(t, "", err, nil)
+
+				got, err := encoding.Decode(b)
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", got, v)
+			}
+
+			b, err := encoding.Encode(long)
+			assertErrorFail(t, "", err, nil)
+			assert(t, "compressed", len(b) < len(long)/10, true)
+		})
+	}
+
+	t.Run("threshold", func(t *testing.T) {
+		encoding := boltron.NewCompressedEncoding(boltron.StringEncoding, &boltron.CompressedEncodingOptions{
+			Threshold: 10,
+		})
+
+		b, err := encoding.Encode("short")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", b, []byte{0xfd, 0, 's', 'h', 'o', 'r', 't'})
+
+		got, err := encoding.Decode(b)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", got, "short")
+	})
+
+	t.Run("no compression", func(t *testing.T) {
+		encoding := boltron.NewCompressedEncoding(boltron.StringEncoding, &boltron.CompressedEncodingOptions{
+			Level: &noCompression,
+		})
+
+		b, err := encoding.Encode(long)
+		assertErrorFail(t, "", err, nil)
+		// the stored flate block is larger than the value, so it is not used
+		assert(t, "stored", b, append([]byte{0xfd, 0}, long...))
+
+		got, err := encoding.Decode(b)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", got, long)
+	})
+
+	t.Run("incompressible", func(t *testing.T) {
+		encoding := boltron.NewCompressedEncoding(boltron.StringEncoding, nil)
+
+		v := "0123456789abcdefghijklmnopqrstuvwxyz"
+		b, err := encoding.Encode(v)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", b, append([]byte{0xfd, 0}, v...))
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		encoding := boltron.NewCompressedEncoding(boltron.StringEncoding, &boltron.CompressedEncodingOptions{
+			MaxSize: 100,
+		})
+
+		_, err := encoding.Encode(long)
+		assert(t, "", err != nil, true)
+
+		b, err := boltron.NewCompressedEncoding(boltron.StringEncoding, nil).Encode(long)
+		assertErrorFail(t, "", err, nil)
+
+		_, err = encoding.Decode(b)
+		assert(t, "", err != nil, true)
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		encoding := boltron.NewCompressedEncoding(boltron.StringEncoding, nil)
+
+		got, err := encoding.Decode([]byte("uncompressed"))
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", got, "uncompressed")
+	})
+
+	t.Run("missing dictionary", func(t *testing.T) {
+		b, err := boltron.NewCompressedEncoding(boltron.StringEncoding, &boltron.CompressedEncodingOptions{
+			Dictionary: []byte("boltron"),
+		}).Encode(long)
+		assertErrorFail(t, "", err, nil)
+
+		_, err = boltron.NewCompressedEncoding(boltron.StringEncoding, nil).Decode(b)
+		assert(t, "", err != nil, true)
+	})
+}
+
+func TestCompressedEncoding_collection(t *testing.T) {
+	db := newDB(t)
+
+	type document struct {
+		Title string
+		Body  string
+	}
+
+	definition := boltron.NewCollectionDefinition(
+		"documents",
+		boltron.StringEncoding,
+		boltron.Encoding[*document](boltron.NewCompressedEncoding(boltron.NewJSONEncoding[*document](), &boltron.CompressedEncodingOptions{
+			Threshold: 64,
+		})),
+		nil,
+	)
+
+	doc := &document{
+		Title: "Lorem ipsum",
+		Body:  strings.Repeat("Lorem ipsum dolor sit amet. ", 50),
+	}
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		_, err := definition.Collection(tx).Save("lorem", doc, false)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		got, err := definition.Collection(tx).Get("lorem")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", got, doc)
+	})
+}
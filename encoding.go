@@ -47,6 +47,41 @@ func (e *EncodingFunc[T]) Decode(b []byte) (T, error) {
 	return e.decodeFunc(b)
 }
 
+// NumericEncoding is an Encoding of a numeric type that is able to add two
+// numbers. It is used by List IncrementOrderBy method.
+type NumericEncoding[T any] interface {
+	Encoding[T]
+	Add(a, b T) (T, error)
+}
+
+// NumericEncodingFunc is a helper type to construct NumericEncoding from
+// existing functions.
+type NumericEncodingFunc[T any] struct {
+	EncodingFunc[T]
+	addFunc func(a, b T) (T, error)
+}
+
+// NewNumericEncoding returns Encoding from functions that define it, which
+// also implements NumericEncoding.
+func NewNumericEncoding[T any](
+	encode func(T) ([]byte, error),
+	decode func([]byte) (T, error),
+	add func(a, b T) (T, error),
+) Encoding[T] {
+	return &NumericEncodingFunc[T]{
+		EncodingFunc: EncodingFunc[T]{
+			encodeFunc: encode,
+			decodeFunc: decode,
+		},
+		addFunc: add,
+	}
+}
+
+// Add returns the sum of two numbers.
+func (e *NumericEncodingFunc[T]) Add(a, b T) (T, error) {
+	return e.addFunc(a, b)
+}
+
 var (
 	// StringEncoding encodes string by a simple type conversion to byte slice.
 	StringEncoding = NewEncoding(
@@ -73,7 +108,7 @@ var (
 
 	// Uint64BinaryEncoding encodes uint64 number as big endian 8 byte array. It
 	// is suitable to be used as OrderBy encoding in lists.
-	Uint64BinaryEncoding = NewEncoding(
+	Uint64BinaryEncoding = NewNumericEncoding(
 		func(v uint64) ([]byte, error) {
 			b := make([]byte, 8)
 			binary.BigEndian.PutUint64(b, v)
@@ -85,39 +120,55 @@ var (
 			}
 			return binary.BigEndian.Uint64(b), nil
 		},
+		addUint64,
 	)
 
 	// IntBase10Encoding encodes integer using strconv.Itoa and strconv.Atoi
 	// functions.
-	IntBase10Encoding = NewEncoding(
+	IntBase10Encoding = NewNumericEncoding(
 		func(i int) ([]byte, error) {
 			return []byte(strconv.Itoa(i)), nil
 		},
 		func(b []byte) (int, error) {
 			return strconv.Atoi(string(b))
 		},
+		func(a, b int) (int, error) {
+			s := a + b
+			if (s > a) != (b > 0) {
+				return 0, ErrOverflow
+			}
+			return s, nil
+		},
 	)
 
 	// Int64Base36Encoding encodes int64 using strconv.FormatInt with 36 base
 	// string representation.
-	Int64Base36Encoding = NewEncoding(
+	Int64Base36Encoding = NewNumericEncoding(
 		func(i int64) ([]byte, error) {
 			return []byte(strconv.FormatInt(i, 36)), nil
 		},
 		func(b []byte) (int64, error) {
 			return strconv.ParseInt(string(b), 36, 64)
 		},
+		func(a, b int64) (int64, error) {
+			s := a + b
+			if (s > a) != (b > 0) {
+				return 0, ErrOverflow
+			}
+			return s, nil
+		},
 	)
 
 	// Uint64Base36Encoding encodes uint64 using strconv.FormatInt with 36 base
 	// string representation.
-	Uint64Base36Encoding = NewEncoding(
+	Uint64Base36Encoding = NewNumericEncoding(
 		func(i uint64) ([]byte, error) {
 			return []byte(strconv.FormatUint(i, 36)), nil
 		},
 		func(b []byte) (uint64, error) {
 			return strconv.ParseUint(string(b), 36, 64)
 		},
+		addUint64,
 	)
 
 	// TimeEncoding encodes time using EncodeTime and DecodeTime functions. It
@@ -144,6 +195,14 @@ var (
 	)
 )
 
+func addUint64(a, b uint64) (uint64, error) {
+	s := a + b
+	if s < a {
+		return 0, ErrOverflow
+	}
+	return s, nil
+}
+
 // NewJSONEncoding uses JSON to encode any JSON-serializable type.
 func NewJSONEncoding[T any]() Encoding[T] {
 	return NewEncoding(
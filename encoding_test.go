@@ -111,6 +111,32 @@ func TestUint64Base36Encoding(t *testing.T) {
 	})
 }
 
+func TestNumericEncoding(t *testing.T) {
+	uint64Encoding, ok := boltron.Uint64BinaryEncoding.(boltron.NumericEncoding[uint64])
+	if !ok {
+		t.Fatal("uint64 binary encoding is not numeric")
+	}
+	v, err := uint64Encoding.Add(40, 2)
+	assertErrorFail(t, "", err, nil)
+	assert(t, "", v, uint64(42))
+	_, err = uint64Encoding.Add(math.MaxUint64, 1)
+	assertError(t, "", err, boltron.ErrOverflow)
+
+	int64Encoding, ok := boltron.Int64Base36Encoding.(boltron.NumericEncoding[int64])
+	if !ok {
+		t.Fatal("int64 base36 encoding is not numeric")
+	}
+	i, err := int64Encoding.Add(2, -5)
+	assertErrorFail(t, "", err, nil)
+	assert(t, "", i, int64(-3))
+	_, err = int64Encoding.Add(math.MinInt64, -1)
+	assertError(t, "", err, boltron.ErrOverflow)
+
+	if _, ok := boltron.StringEncoding.(boltron.NumericEncoding[string]); ok {
+		t.Fatal("string encoding is numeric")
+	}
+}
+
 func TestTimeEncoding(t *testing.T) {
 	tableTestEncoding(t, boltron.TimeEncoding, []struct {
 		value   time.Time
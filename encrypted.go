@@ -0,0 +1,897 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrKeyNotFound is returned by KeyProvider if the encryption key with the
+// requested identifier does not exist.
+var ErrKeyNotFound = errors.New("boltron: encryption key not found")
+
+// encryptedEncodingMagic is the first byte of every value encoded by
+// EncryptedEncoding.
+const encryptedEncodingMagic = 0xfc
+
+// Encryption modes stored in the second byte of the encrypted value header.
+const (
+	encryptionModeRandom byte = iota
+	encryptionModeDeterministic
+)
+
+// encryptedHeaderLen is the length of the magic byte, mode and the key
+// identifier.
+const encryptedHeaderLen = 1 + 1 + 4
+
+// KeyProvider provides keys for EncryptedEncoding. Keys must be 16, 24 or 32
+// bytes long to select AES-128, AES-192 or AES-256.
+type KeyProvider interface {
+	// CurrentKey returns the key and its identifier that is used to encrypt
+	// values.
+	CurrentKey() (id uint32, key []byte, err error)
+	// Key returns the key with the provided identifier that is used to decrypt
+	// values. If the key does not exist, ErrKeyNotFound should be returned.
+	Key(id uint32) (key []byte, err error)
+}
+
+// StaticKeyProvider is a KeyProvider with a fixed set of keys.
+type StaticKeyProvider struct {
+	currentID uint32
+	keys      map[uint32][]byte
+}
+
+// NewStaticKeyProvider constructs a new StaticKeyProvider with the keys
+// identified by the map keys and the current key identifier.
+func NewStaticKeyProvider(currentID uint32, keys map[uint32][]byte) *StaticKeyProvider {
+	return &StaticKeyProvider{
+		currentID: currentID,
+		keys:      keys,
+	}
+}
+
+// CurrentKey returns the current key and its identifier.
+func (p *StaticKeyProvider) CurrentKey() (id uint32, key []byte, err error) {
+	key, err = p.Key(p.currentID)
+	return p.currentID, key, err
+}
+
+// Key returns the key with the provided identifier.
+func (p *StaticKeyProvider) Key(id uint32) (key []byte, err error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("key %v: %w", id, ErrKeyNotFound)
+	}
+	return key, nil
+}
+
+// EncryptedEncoding encrypts values encoded by another encoding using AES-GCM.
+// Encrypted values contain the identifier of the key that was used, so that
+// keys can be rotated without making older values unreadable.
+type EncryptedEncoding[T any] struct {
+	encoding      Encoding[T]
+	keyProvider   KeyProvider
+	deterministic bool
+}
+
+// EncryptedEncodingOptions provides additional configuration for an
+// EncryptedEncoding.
+type EncryptedEncodingOptions struct {
+	// Deterministic mode derives the nonce from the value itself, in the SIV
+	// manner, so that the same value is always encrypted to the same bytes
+	// with the same key. It is required for encoding of Collection keys and
+	// Association sides that need exact match lookups, but it reveals which
+	// values are equal. The lexicographical order of encrypted values is not
+	// related to the order of the original values. Lookups encrypt the value
+	// with the current key, so values encrypted with any previous key are not
+	// found after the current key is changed. Key rotation is supported only
+	// by re-encrypting all data that uses the encoding with ReencryptCollection,
+	// ReencryptCollections, ReencryptList, ReencryptLists, ReencryptAssociation
+	// or ReencryptAssociations, in the same transaction before any lookups.
+	Deterministic bool
+}
+
+// NewEncryptedEncoding constructs an EncryptedEncoding that encrypts values
+// encoded by the provided encoding with keys from the key provider.
+func NewEncryptedEncoding[T any](encoding Encoding[T], keyProvider KeyProvider, o *EncryptedEncodingOptions) *EncryptedEncoding[T] {
+	if o == nil {
+		o = new(EncryptedEncodingOptions)
+	}
+	return &EncryptedEncoding[T]{
+		encoding:      encoding,
+		keyProvider:   keyProvider,
+		deterministic: o.Deterministic,
+	}
+}
+
+// Encode serializes the value with the wrapped encoding and encrypts it with
+// the current key.
+func (e *EncryptedEncoding[T]) Encode(t T) ([]byte, error) {
+	b, err := e.encoding.Encode(t)
+	if err != nil {
+		return nil, err
+	}
+	return e.encrypt(b)
+}
+
+// Decode decrypts the value with the key that it was encrypted with and
+// deserializes it with the wrapped encoding.
+func (e *EncryptedEncoding[T]) Decode(b []byte) (t T, err error) {
+	data, _, err := e.decrypt(b)
+	if err != nil {
+		return t, err
+	}
+	return e.encoding.Decode(data)
+}
+
+// KeyID returns the identifier of the key that the value is encrypted with.
+func (e *EncryptedEncoding[T]) KeyID(b []byte) (uint32, error) {
+	if len(b) < encryptedHeaderLen || b[0] != encryptedEncodingMagic {
+		return 0, errors.New("invalid encryption header")
+	}
+	return binary.BigEndian.Uint32(b[2:encryptedHeaderLen]), nil
+}
+
+// Reencrypt decrypts the value and encrypts it with the current key if it is
+// not already encrypted with it. The returned changed flag reports if the
+// value is re-encrypted.
+func (e *EncryptedEncoding[T]) Reencrypt(b []byte) (r []byte, changed bool, err error) {
+	currentID, _, err := e.keyProvider.CurrentKey()
+	if err != nil {
+		return nil, false, fmt.Errorf("current key: %w", err)
+	}
+	data, id, err := e.decrypt(b)
+	if err != nil {
+		return nil, false, err
+	}
+	if id == currentID {
+		return b, false, nil
+	}
+	r, err = e.encrypt(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return r, true, nil
+}
+
+func (e *EncryptedEncoding[T]) encrypt(data []byte) ([]byte, error) {
+	id, key, err := e.keyProvider.CurrentKey()
+	if err != nil {
+		return nil, fmt.Errorf("current key: %w", err)
+	}
+
+	mode := encryptionModeRandom
+	if e.deterministic {
+		mode = encryptionModeDeterministic
+	}
+
+	aead, nonce, err := newCipher(mode, key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, encryptedHeaderLen, encryptedHeaderLen+len(nonce)+len(data)+aead.Overhead())
+	out[0] = encryptedEncodingMagic
+	out[1] = mode
+	binary.BigEndian.PutUint32(out[2:encryptedHeaderLen], id)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, data, out[:encryptedHeaderLen]), nil
+}
+
+func (e *EncryptedEncoding[T]) decrypt(b []byte) (data []byte, id uint32, err error) {
+	id, err = e.KeyID(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	key, err := e.keyProvider.Key(id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("key %v: %w", id, err)
+	}
+
+	mode := b[1]
+	aead, _, err := newCipher(mode, key, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	nonceSize := aead.NonceSize()
+	if len(b) < encryptedHeaderLen+nonceSize {
+		return nil, 0, errors.New("encrypted value too short")
+	}
+	nonce := b[encryptedHeaderLen : encryptedHeaderLen+nonceSize]
+	data, err = aead.Open(nil, nonce, b[encryptedHeaderLen+nonceSize:], b[:encryptedHeaderLen])
+	if err != nil {
+		return nil, 0, fmt.Errorf("decrypt: %w", err)
+	}
+
+	if mode == encryptionModeDeterministic && !hmac.Equal(nonce, syntheticNonce(key, data, nonceSize)) {
+		return nil, 0, errors.New("decrypt: synthetic nonce mismatch")
+	}
+
+	return data, id, nil
+}
+
+// newCipher returns AES-GCM cipher for the encryption mode and the nonce for
+// encryption of the provided data. In deterministic mode, the encryption key is
+// derived from the provided key and the nonce is synthesized from the data.
+func newCipher(mode byte, key, data []byte) (aead cipher.AEAD, nonce []byte, err error) {
+	encryptionKey := key
+	switch mode {
+	case encryptionModeRandom:
+	case encryptionModeDeterministic:
+		encryptionKey = deriveKey(key, "boltron: encryption key", len(key))
+	default:
+		return nil, nil, fmt.Errorf("unknown encryption mode %v", mode)
+	}
+
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("aes cipher: %w", err)
+	}
+	aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gcm: %w", err)
+	}
+
+	if data == nil {
+		return aead, nil, nil
+	}
+
+	if mode == encryptionModeDeterministic {
+		return aead, syntheticNonce(key, data, aead.NonceSize()), nil
+	}
+
+	nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, fmt.Errorf("nonce: %w", err)
+	}
+	return aead, nonce, nil
+}
+
+func syntheticNonce(key, data []byte, size int) []byte {
+	mac := hmac.New(sha256.New, deriveKey(key, "boltron: synthetic nonce key", sha256.Size))
+	mac.Write(data)
+	return mac.Sum(nil)[:size]
+}
+
+func deriveKey(key []byte, label string, size int) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)[:size]
+}
+
+// ReencryptCollection encrypts all keys and values in the Collection with the
+// current key of their encodings if they are encrypted with any other key.
+// Only the key and value encodings of the definition that are EncryptedEncoding
+// are re-encrypted. It returns the number of re-encrypted key/value pairs.
+// Collections with content addressed values or hashed keys are not supported.
+func ReencryptCollection[K, V any](tx *bolt.Tx, d *CollectionDefinition[K, V]) (count int, err error) {
+	if d.bucketPathContent != nil {
+		return 0, errors.New("content addressed collection values can not be re-encrypted")
+	}
+	if d.hashedKeys {
+		return 0, errors.New("collection hashed keys can not be re-encrypted")
+	}
+	keyEncoding, _ := d.keyEncoding.(*EncryptedEncoding[K])
+	valueEncoding, _ := d.valueEncoding.(*EncryptedEncoding[V])
+	if keyEncoding == nil && valueEncoding == nil {
+		return 0, errors.New("collection does not have encrypted encodings")
+	}
+
+	bucket, err := deepBucket(tx, false, d.bucketPath...)
+	if err != nil {
+		return 0, fmt.Errorf("bucket: %w", err)
+	}
+	if bucket == nil {
+		return 0, nil
+	}
+
+	type pair struct {
+		oldKey, key, value []byte
+	}
+	var pairs []pair
+
+	if err := bucket.ForEach(func(k, v []byte) error {
+		var keyChanged, valueChanged bool
+		newKey, newValue := k, v
+		if keyEncoding != nil {
+			newKey, keyChanged, err = keyEncoding.Reencrypt(k)
+			if err != nil {
+				return fmt.Errorf("reencrypt key: %w", err)
+			}
+		}
+		if valueEncoding != nil {
+			newValue, valueChanged, err = valueEncoding.Reencrypt(v)
+			if err != nil {
+				return fmt.Errorf("reencrypt value: %w", err)
+			}
+		}
+		if keyChanged || valueChanged {
+			pairs = append(pairs, pair{
+				oldKey: append([]byte(nil), k...),
+				key:    append([]byte(nil), newKey...),
+				value:  append([]byte(nil), newValue...),
+			})
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	if d.fillPercent > 0 {
+		bucket.FillPercent = d.fillPercent
+	}
+
+	for _, p := range pairs {
+		if err := bucket.Delete(p.oldKey); err != nil {
+			return count, fmt.Errorf("delete: %w", err)
+		}
+		if err := bucket.Put(p.key, p.value); err != nil {
+			return count, fmt.Errorf("put: %w", err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// ReencryptList encrypts all values and order by values in the List with the
+// current key of their encodings if any of them is encrypted with any other
+// key. Only the encodings of the definition that are EncryptedEncoding are
+// re-encrypted. Elements are updated in place, keeping their insertion
+// sequences, without calling eviction handlers. It returns the number of
+// elements that were encrypted with other keys.
+func ReencryptList[V, O any](tx *bolt.Tx, d *ListDefinition[V, O]) (count int, err error) {
+	if !isEncrypted(d.valueEncoding) && !isEncrypted(d.orderByEncoding) {
+		return 0, errors.New("list does not have encrypted encodings")
+	}
+
+	listBucket, err := deepBucket(tx, false, d.bucketPath...)
+	if err != nil {
+		return 0, fmt.Errorf("bucket: %w", err)
+	}
+	if listBucket == nil {
+		return 0, nil
+	}
+	indexBucket, err := deepBucket(tx, false, d.bucketPathIndex...)
+	if err != nil {
+		return 0, fmt.Errorf("index bucket: %w", err)
+	}
+	ranksBucket, err := deepBucket(tx, false, d.bucketPathRanks...)
+	if err != nil {
+		return 0, fmt.Errorf("ranks bucket: %w", err)
+	}
+
+	r := listReencryptor{
+		keys:    d.keys,
+		values:  newReencryptor(d.valueEncoding),
+		orderBy: newReencryptor(d.orderByEncoding),
+	}
+	return r.reencrypt(listBucket, indexBucket, ranksBucket, d.rankBlockSize)
+}
+
+// ReencryptLists encrypts all list keys, values and order by values in Lists
+// with the current key of their encodings if any of them is encrypted with any
+// other key. Only the encodings of the definition that are EncryptedEncoding
+// are re-encrypted. Lists and their elements are updated in place, keeping
+// insertion sequences, without calling eviction handlers. It returns the
+// number of elements that were encrypted with other keys, counting all
+// elements of a list with a re-encrypted key.
+func ReencryptLists[K, V, O any](tx *bolt.Tx, d *ListsDefinition[K, V, O]) (count int, err error) {
+	if !isEncrypted(d.keyEncoding) && !isEncrypted(d.valueEncoding) && !isEncrypted(d.orderByEncoding) {
+		return 0, errors.New("lists do not have encrypted encodings")
+	}
+
+	listsBucket := tx.Bucket(d.bucketNameLists)
+	if listsBucket == nil {
+		return 0, nil
+	}
+	indexesBucket := tx.Bucket(d.bucketNameIndexes)
+	ranksBuckets := tx.Bucket(d.bucketNameRanks)
+
+	keys := newReencryptor(d.keyEncoding)
+	r := listReencryptor{
+		keys: listKeys{
+			multiset: d.multiset,
+			tieBreak: d.tieBreak,
+		},
+		values:  newReencryptor(d.valueEncoding),
+		orderBy: newReencryptor(d.orderByEncoding),
+	}
+
+	count, err = reencryptBuckets(listsBucket, keys, func(k []byte, listBucket *bolt.Bucket) (int, error) {
+		var indexBucket, ranksBucket *bolt.Bucket
+		if indexesBucket != nil {
+			indexBucket = indexesBucket.Bucket(k)
+		}
+		if ranksBuckets != nil {
+			ranksBucket = ranksBuckets.Bucket(k)
+		}
+		c, err := r.reencrypt(listBucket, indexBucket, ranksBucket, d.rankBlockSize)
+		if err != nil {
+			return 0, err
+		}
+		_, keyChanged, err := keys.reencrypt(k)
+		if err != nil {
+			return 0, fmt.Errorf("list key: %w", err)
+		}
+		if keyChanged {
+			c = bucketKeyCount(listBucket)
+		}
+		return c, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if indexesBucket != nil {
+		if _, err := reencryptBuckets(indexesBucket, keys, nil); err != nil {
+			return 0, fmt.Errorf("indexes: %w", err)
+		}
+	}
+	if ranksBuckets != nil {
+		if _, err := reencryptBuckets(ranksBuckets, keys, nil); err != nil {
+			return 0, fmt.Errorf("ranks: %w", err)
+		}
+	}
+	if valuesBucket := tx.Bucket(d.bucketNameValues); valuesBucket != nil {
+		if _, err := reencryptBuckets(valuesBucket, r.values, func(_ []byte, valueBucket *bolt.Bucket) (int, error) {
+			return reencryptEntries(valueBucket, keys, r.orderBy)
+		}); err != nil {
+			return 0, fmt.Errorf("values: %w", err)
+		}
+	}
+
+	return count, nil
+}
+
+// listReencryptor re-encrypts elements of a single list in the list bucket and
+// the matching entries in its index bucket.
+type listReencryptor struct {
+	keys    listKeys
+	values  *reencryptor
+	orderBy *reencryptor
+}
+
+// reencrypt re-encrypts elements of the list and rebuilds its order statistics
+// if any element is changed, as the re-encrypted keys are not in the same
+// order. It returns the number of re-encrypted elements.
+func (r listReencryptor) reencrypt(listBucket, indexBucket, ranksBucket *bolt.Bucket, rankBlockSize int) (count int, err error) {
+	count, err = reencryptBucket(listBucket, func(k, v []byte) (newKey, newValue []byte, changed bool, err error) {
+		newValue, valueChanged, err := r.values.reencrypt(v)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("value: %w", err)
+		}
+		o, orderByChanged, err := r.orderBy.reencrypt(r.keys.orderBy(k, v))
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("order by: %w", err)
+		}
+		if !valueChanged && !orderByChanged {
+			return k, v, false, nil
+		}
+		return r.keys.key(o, newValue, r.keys.sequence(k, v)), newValue, true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if indexBucket != nil {
+		if r.keys.multiset {
+			_, err = reencryptBuckets(indexBucket, r.values, func(_ []byte, occurrences *bolt.Bucket) (int, error) {
+				return reencryptEntries(occurrences, nil, r.orderBy)
+			})
+		} else {
+			_, err = reencryptBucket(indexBucket, func(v, i []byte) (newKey, newValue []byte, changed bool, err error) {
+				newKey, valueChanged, err := r.values.reencrypt(v)
+				if err != nil {
+					return nil, nil, false, fmt.Errorf("value: %w", err)
+				}
+				o, seq := r.keys.splitIndex(i)
+				o, orderByChanged, err := r.orderBy.reencrypt(o)
+				if err != nil {
+					return nil, nil, false, fmt.Errorf("order by: %w", err)
+				}
+				return newKey, append(o[:len(o):len(o)], seq...), valueChanged || orderByChanged, nil
+			})
+		}
+		if err != nil {
+			return 0, fmt.Errorf("index: %w", err)
+		}
+	}
+
+	if ranksBucket != nil && count > 0 {
+		if err := clearBucket(ranksBucket); err != nil {
+			return 0, fmt.Errorf("clear ranks: %w", err)
+		}
+		if err := rankBuild(ranksBucket, listBucket, rankBlockSize); err != nil {
+			return 0, fmt.Errorf("build ranks: %w", err)
+		}
+	}
+
+	return count, nil
+}
+
+// ReencryptAssociation encrypts all left and right values in the Association
+// with the current key of their encodings if any of them is encrypted with any
+// other key. Only the encodings of the definition that are EncryptedEncoding
+// are re-encrypted. Values are updated in place. It returns the number of
+// re-encrypted associations. Associations with hashed left or right values are
+// not supported.
+func ReencryptAssociation[L, R any](tx *bolt.Tx, d *AssociationDefinition[L, R]) (count int, err error) {
+	if d.hashedLeft || d.hashedRight {
+		return 0, errors.New("association hashed values can not be re-encrypted")
+	}
+	if !isEncrypted(d.leftEncoding) && !isEncrypted(d.rightEncoding) {
+		return 0, errors.New("association does not have encrypted encodings")
+	}
+
+	leftBucket, err := deepBucket(tx, false, d.bucketPathLeft...)
+	if err != nil {
+		return 0, fmt.Errorf("bucket: %w", err)
+	}
+	if leftBucket == nil {
+		return 0, nil
+	}
+	rightBucket, err := deepBucket(tx, false, d.bucketPathRight...)
+	if err != nil {
+		return 0, fmt.Errorf("right bucket: %w", err)
+	}
+
+	left := newReencryptor(d.leftEncoding)
+	right := newReencryptor(d.rightEncoding)
+
+	count, err = reencryptEntries(leftBucket, left, right)
+	if err != nil {
+		return 0, fmt.Errorf("left: %w", err)
+	}
+	if rightBucket != nil {
+		if _, err := reencryptEntries(rightBucket, right, left); err != nil {
+			return 0, fmt.Errorf("right: %w", err)
+		}
+	}
+
+	return count, nil
+}
+
+// ReencryptAssociations encrypts all association keys, left and right values
+// in Associations with the current key of their encodings if any of them is
+// encrypted with any other key. Only the encodings of the definition that are
+// EncryptedEncoding are re-encrypted. Associations and their values are
+// updated in place, keeping associations without values. It returns the
+// number of re-encrypted left and right value pairs, counting all pairs of an
+// association with a re-encrypted key.
+func ReencryptAssociations[A, L, R any](tx *bolt.Tx, d *AssociationsDefinition[A, L, R]) (count int, err error) {
+	if !isEncrypted(d.associationKeyEncoding) && !isEncrypted(d.leftEncoding) && !isEncrypted(d.rightEncoding) {
+		return 0, errors.New("associations do not have encrypted encodings")
+	}
+
+	leftBuckets := tx.Bucket(d.bucketNameLeft)
+	if leftBuckets == nil {
+		return 0, nil
+	}
+
+	keys := newReencryptor(d.associationKeyEncoding)
+	left := newReencryptor(d.leftEncoding)
+	right := newReencryptor(d.rightEncoding)
+
+	count, err = reencryptBuckets(leftBuckets, keys, func(k []byte, leftBucket *bolt.Bucket) (int, error) {
+		c, err := reencryptEntries(leftBucket, left, right)
+		if err != nil {
+			return 0, err
+		}
+		_, keyChanged, err := keys.reencrypt(k)
+		if err != nil {
+			return 0, fmt.Errorf("association key: %w", err)
+		}
+		if keyChanged {
+			c = bucketKeyCount(leftBucket)
+		}
+		return c, nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("left: %w", err)
+	}
+
+	for _, b := range []struct {
+		name       []byte
+		bucketKeys *reencryptor
+		keys       *reencryptor
+		values     *reencryptor
+	}{
+		{name: d.bucketNameRight, bucketKeys: keys, keys: right, values: left},
+		{name: d.bucketNameLeftIndex, bucketKeys: left, keys: keys},
+		{name: d.bucketNameRightIndex, bucketKeys: right, keys: keys},
+	} {
+		bucket := tx.Bucket(b.name)
+		if bucket == nil {
+			continue
+		}
+		if _, err := reencryptBuckets(bucket, b.bucketKeys, func(_ []byte, nested *bolt.Bucket) (int, error) {
+			return reencryptEntries(nested, b.keys, b.values)
+		}); err != nil {
+			return 0, fmt.Errorf("%s: %w", b.name, err)
+		}
+	}
+
+	return count, nil
+}
+
+// ReencryptCollections encrypts all collection keys, keys and values in
+// Collections with the current key of their encodings if any of them is
+// encrypted with any other key. Only the encodings of the definition that are
+// EncryptedEncoding are re-encrypted. Collections and their keys and values
+// are updated in place, keeping collections without keys. It returns the
+// number of re-encrypted key/value pairs, counting all pairs of a collection
+// with a re-encrypted key.
+func ReencryptCollections[C, K, V any](tx *bolt.Tx, d *CollectionsDefinition[C, K, V]) (count int, err error) {
+	if !isEncrypted(d.collectionKeyEncoding) && !isEncrypted(d.keyEncoding) && !isEncrypted(d.valueEncoding) {
+		return 0, errors.New("collections do not have encrypted encodings")
+	}
+
+	collectionsBucket := tx.Bucket(d.bucketNameCollections)
+	if collectionsBucket == nil {
+		return 0, nil
+	}
+
+	collectionKeys := newReencryptor(d.collectionKeyEncoding)
+	keys := newReencryptor(d.keyEncoding)
+	values := newReencryptor(d.valueEncoding)
+
+	count, err = reencryptBuckets(collectionsBucket, collectionKeys, func(ck []byte, collectionBucket *bolt.Bucket) (int, error) {
+		c, err := reencryptEntries(collectionBucket, keys, values)
+		if err != nil {
+			return 0, err
+		}
+		_, keyChanged, err := collectionKeys.reencrypt(ck)
+		if err != nil {
+			return 0, fmt.Errorf("collection key: %w", err)
+		}
+		if keyChanged {
+			c = bucketKeyCount(collectionBucket)
+		}
+		return c, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if keysBucket := tx.Bucket(d.bucketNameKeys); keysBucket != nil {
+		if _, err := reencryptBuckets(keysBucket, keys, func(_ []byte, keyBucket *bolt.Bucket) (int, error) {
+			return reencryptEntries(keyBucket, collectionKeys, nil)
+		}); err != nil {
+			return 0, fmt.Errorf("keys: %w", err)
+		}
+	}
+
+	return count, nil
+}
+
+// isEncrypted returns true if the encoding is EncryptedEncoding.
+func isEncrypted[T any](e Encoding[T]) bool {
+	_, ok := e.(*EncryptedEncoding[T])
+	return ok
+}
+
+// reencryptor re-encrypts encoded values with the current key if the encoding
+// is EncryptedEncoding. Values encrypted in the random mode are re-encrypted to
+// the same bytes within a single re-encryption, so that values stored in
+// different buckets, like in lists and their indexes, remain equal.
+type reencryptor struct {
+	encrypt func(b []byte) (r []byte, changed bool, err error)
+	cache   map[string][]byte // nil in the deterministic mode
+}
+
+// newReencryptor returns a reencryptor for the encoding.
+func newReencryptor[T any](e Encoding[T]) *reencryptor {
+	encoding, ok := e.(*EncryptedEncoding[T])
+	if !ok {
+		return new(reencryptor)
+	}
+	r := &reencryptor{
+		encrypt: encoding.Reencrypt,
+	}
+	if !encoding.deterministic {
+		r.cache = make(map[string][]byte)
+	}
+	return r
+}
+
+// reencrypt returns the value encrypted with the current key and true if it
+// was encrypted with any other key.
+func (r *reencryptor) reencrypt(b []byte) ([]byte, bool, error) {
+	if r == nil || r.encrypt == nil {
+		return b, false, nil
+	}
+	if v, ok := r.cache[string(b)]; ok {
+		return v, true, nil
+	}
+	v, changed, err := r.encrypt(b)
+	if err != nil {
+		return nil, false, err
+	}
+	if changed && r.cache != nil {
+		r.cache[string(b)] = v
+	}
+	return v, changed, nil
+}
+
+// reencryptEntries re-encrypts keys and values of the bucket that are not
+// nested buckets. A nil reencryptor leaves keys or values unchanged. It returns
+// the number of changed entries.
+func reencryptEntries(bucket *bolt.Bucket, keys, values *reencryptor) (count int, err error) {
+	return reencryptBucket(bucket, func(k, v []byte) (newKey, newValue []byte, changed bool, err error) {
+		newKey, keyChanged, err := keys.reencrypt(k)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("key: %w", err)
+		}
+		newValue = v
+		var valueChanged bool
+		if len(v) > 0 {
+			newValue, valueChanged, err = values.reencrypt(v)
+			if err != nil {
+				return nil, nil, false, fmt.Errorf("value: %w", err)
+			}
+		}
+		return newKey, newValue, keyChanged || valueChanged, nil
+	})
+}
+
+// reencryptBucket replaces keys and values of the bucket that are not nested
+// buckets with the ones returned by the function f, if they are changed. Like
+// in ReencryptCollection, changed entries are collected first and then
+// replaced one key at a time. It returns the number of changed entries.
+func reencryptBucket(bucket *bolt.Bucket, f func(k, v []byte) (newKey, newValue []byte, changed bool, err error)) (count int, err error) {
+	type pair struct {
+		oldKey, key, value []byte
+	}
+	var pairs []pair
+
+	if err := bucket.ForEach(func(k, v []byte) error {
+		if v == nil && bucket.Bucket(k) != nil {
+			return nil
+		}
+		newKey, newValue, changed, err := f(k, v)
+		if err != nil {
+			return err
+		}
+		if changed {
+			pairs = append(pairs, pair{
+				oldKey: append([]byte(nil), k...),
+				key:    append([]byte(nil), newKey...),
+				value:  append([]byte(nil), newValue...),
+			})
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	for _, p := range pairs {
+		if err := bucket.Delete(p.oldKey); err != nil {
+			return 0, fmt.Errorf("delete: %w", err)
+		}
+	}
+	for _, p := range pairs {
+		if err := bucket.Put(p.key, p.value); err != nil {
+			return 0, fmt.Errorf("put: %w", err)
+		}
+	}
+
+	return len(pairs), nil
+}
+
+// reencryptBuckets calls the function f, if it is not nil, for every nested
+// bucket and moves the nested bucket under its key re-encrypted with the
+// reencryptor, if it is changed. It returns the sum of counts returned by f.
+func reencryptBuckets(bucket *bolt.Bucket, keys *reencryptor, f func(k []byte, nested *bolt.Bucket) (int, error)) (count int, err error) {
+	var names [][]byte
+	if err := bucket.ForEach(func(k, v []byte) error {
+		if v == nil && bucket.Bucket(k) != nil {
+			names = append(names, append([]byte(nil), k...))
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	for _, k := range names {
+		if f != nil {
+			c, err := f(k, bucket.Bucket(k))
+			if err != nil {
+				return 0, err
+			}
+			count += c
+		}
+		newKey, changed, err := keys.reencrypt(k)
+		if err != nil {
+			return 0, fmt.Errorf("bucket key: %w", err)
+		}
+		if changed {
+			if err := moveBucket(bucket, k, newKey); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return count, nil
+}
+
+// moveBucket moves the content and the sequence of the nested bucket to a new
+// nested bucket under a different key.
+func moveBucket(parent *bolt.Bucket, oldKey, newKey []byte) error {
+	dst, err := parent.CreateBucket(newKey)
+	if err != nil {
+		return fmt.Errorf("create bucket: %w", err)
+	}
+	if err := copyBucket(dst, parent.Bucket(oldKey)); err != nil {
+		return err
+	}
+	if err := parent.DeleteBucket(oldKey); err != nil {
+		return fmt.Errorf("delete bucket: %w", err)
+	}
+	return nil
+}
+
+// copyBucket copies all keys, values, nested buckets and sequences from the
+// source bucket to the destination bucket.
+func copyBucket(dst, src *bolt.Bucket) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return fmt.Errorf("set sequence: %w", err)
+	}
+	return src.ForEach(func(k, v []byte) error {
+		if nested := src.Bucket(k); v == nil && nested != nil {
+			b, err := dst.CreateBucket(k)
+			if err != nil {
+				return fmt.Errorf("create bucket: %w", err)
+			}
+			return copyBucket(b, nested)
+		}
+		return dst.Put(k, append([]byte(nil), v...))
+	})
+}
+
+// clearBucket deletes all keys and nested buckets from the bucket.
+func clearBucket(bucket *bolt.Bucket) error {
+	var keys [][]byte
+	if err := bucket.ForEach(func(k, _ []byte) error {
+		keys = append(keys, append([]byte(nil), k...))
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if bucket.Bucket(k) != nil {
+			if err := bucket.DeleteBucket(k); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bucketKeyCount returns the number of keys in the bucket, including the ones
+// that are not committed, unlike bucket statistics.
+func bucketKeyCount(bucket *bolt.Bucket) (count int) {
+	c := bucket.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,535 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"bytes"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+var (
+	testEncryptionKey1 = bytes.Repeat([]byte{1}, 32)
+	testEncryptionKey2 = bytes.Repeat([]byte{2}, 16)
+)
+
+func TestEncryptedEncoding(t *testing.T) {
+	keyProvider := boltron.NewStaticKeyProvider(1, map[uint32][]byte{
+		1: testEncryptionKey1,
+	})
+
+	t.Run("random", func(t *testing.T) {
+		encoding := boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, nil)
+
+		b1, err := encoding.Encode("secret")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", bytes.Contains(b1, []byte("secret")), false)
+
+		b2, err := encoding.Encode("secret")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", bytes.Equal(b1, b2), false)
+
+		for _, b := range [][]byte{b1, b2} {
+			v, err := encoding.Decode(b)
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", v, "secret")
+
+			id, err := encoding.KeyID(b)
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", id, uint32(1))
+		}
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		encoding := boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, &boltron.EncryptedEncodingOptions{
+			Deterministic: true,
+		})
+
+		b1, err := encoding.Encode("secret")
+		assertErrorFail(t, "", err, nil)
+
+		b2, err := encoding.Encode("secret")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", b1, b2)
+
+		b3, err := encoding.Encode("another secret")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", bytes.Equal(b1, b3), false)
+
+		v, err := encoding.Decode(b1)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, "secret")
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		encoding := boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, nil)
+
+		b, err := encoding.Encode("secret")
+		assertErrorFail(t, "", err, nil)
+
+		b[len(b)-1] ^= 0xff
+
+		_, err = encoding.Decode(b)
+		assert(t, "", err != nil, true)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		b, err := boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, nil).Encode("secret")
+		assertErrorFail(t, "", err, nil)
+
+		_, err = boltron.NewEncryptedEncoding(boltron.StringEncoding, boltron.NewStaticKeyProvider(2, map[uint32][]byte{
+			2: testEncryptionKey2,
+		}), nil).Decode(b)
+		assertError(t, "", err, boltron.ErrKeyNotFound)
+	})
+}
+
+func TestReencryptCollection(t *testing.T) {
+	db := newDB(t)
+
+	newDefinition := func(keyProvider boltron.KeyProvider) *boltron.CollectionDefinition[string, string] {
+		return boltron.NewCollectionDefinition(
+			"patients",
+			boltron.Encoding[string](boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, &boltron.EncryptedEncodingOptions{
+				Deterministic: true,
+			})),
+			boltron.Encoding[string](boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, nil)),
+			nil,
+		)
+	}
+
+	oldDefinition := newDefinition(boltron.NewStaticKeyProvider(1, map[uint32][]byte{
+		1: testEncryptionKey1,
+	}))
+
+	newKeyProvider := boltron.NewStaticKeyProvider(2, map[uint32][]byte{
+		1: testEncryptionKey1,
+		2: testEncryptionKey2,
+	})
+	definition := newDefinition(newKeyProvider)
+
+	patients := map[string]string{
+		"alice": "diagnosis a",
+		"bob":   "diagnosis b",
+		"carol": "diagnosis c",
+	}
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		c := oldDefinition.Collection(tx)
+		for k, v := range patients {
+			_, err := c.Save(k, v, false)
+			assertErrorFail(t, "", err, nil)
+		}
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		c := definition.Collection(tx)
+
+		// lookups by deterministically encrypted keys require re-encryption
+		has, err := c.Has("alice")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		count, err := boltron.ReencryptCollection(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, len(patients))
+
+		count, err = boltron.ReencryptCollection(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 0)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		c := definition.Collection(tx)
+
+		for k, v := range patients {
+			got, err := c.Get(k)
+			assertErrorFail(t, k, err, nil)
+			assert(t, k, got, v)
+		}
+
+		size, err := c.Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, len(patients))
+
+		encoding := boltron.NewEncryptedEncoding(boltron.StringEncoding, newKeyProvider, nil)
+		bucket := tx.Bucket([]byte("boltron: collection: patients"))
+		err = bucket.ForEach(func(k, v []byte) error {
+			id, err := encoding.KeyID(v)
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", id, uint32(2))
+			return nil
+		})
+		assertErrorFail(t, "", err, nil)
+	})
+}
+
+func TestReencryptList(t *testing.T) {
+	db := newDB(t)
+
+	var evictions int
+	newDefinition := func(keyProvider boltron.KeyProvider) *boltron.ListDefinition[string, uint64] {
+		return boltron.NewListDefinition(
+			"visits",
+			boltron.Encoding[string](boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, &boltron.EncryptedEncodingOptions{
+				Deterministic: true,
+			})),
+			boltron.Uint64BinaryEncoding,
+			&boltron.ListOptions{
+				TieBreak: boltron.ListTieBreakInsertionReverse,
+				MaxSize:  3,
+				EvictionHandler: func(_, _ []byte) {
+					evictions++
+				},
+			},
+		)
+	}
+
+	oldDefinition := newDefinition(boltron.NewStaticKeyProvider(1, map[uint32][]byte{
+		1: testEncryptionKey1,
+	}))
+	definition := newDefinition(boltron.NewStaticKeyProvider(2, map[uint32][]byte{
+		1: testEncryptionKey1,
+		2: testEncryptionKey2,
+	}))
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		l := oldDefinition.List(tx)
+		for _, v := range []string{"alice", "bob", "carol"} {
+			err := l.Add(v, 1)
+			assertErrorFail(t, "", err, nil)
+		}
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		has, err := definition.List(tx).Has("alice")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		count, err := boltron.ReencryptList(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 3)
+
+		count, err = boltron.ReencryptList(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 0)
+
+		// elements are not added again
+		assert(t, "", evictions, 0)
+
+		size, err := definition.List(tx).Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 3)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		l := definition.List(tx)
+
+		has, err := l.Has("alice")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		// insertion order of elements with equal order by is preserved
+		var values []string
+		_, err = l.IterateValues(nil, false, func(v string) (bool, error) {
+			values = append(values, v)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []string{"carol", "bob", "alice"})
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		l := definition.List(tx)
+
+		// insertion sequence continues, so the new element is the first one
+		// and it is evicted as the list is full
+		err := l.Add("dave", 1)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", evictions, 1)
+
+		has, err := l.Has("dave")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		rank, err := l.Rank("alice", false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", rank, 2)
+	})
+}
+
+func TestReencryptLists(t *testing.T) {
+	db := newDB(t)
+
+	newDefinition := func(keyProvider boltron.KeyProvider) *boltron.ListsDefinition[string, string, uint64] {
+		return boltron.NewListsDefinition(
+			"visits",
+			boltron.Encoding[string](boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, &boltron.EncryptedEncodingOptions{
+				Deterministic: true,
+			})),
+			boltron.Encoding[string](boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, &boltron.EncryptedEncodingOptions{
+				Deterministic: true,
+			})),
+			boltron.Uint64BinaryEncoding,
+			nil,
+		)
+	}
+
+	oldDefinition := newDefinition(boltron.NewStaticKeyProvider(1, map[uint32][]byte{
+		1: testEncryptionKey1,
+	}))
+	definition := newDefinition(boltron.NewStaticKeyProvider(2, map[uint32][]byte{
+		1: testEncryptionKey1,
+		2: testEncryptionKey2,
+	}))
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		lists := oldDefinition.Lists(tx)
+		for i, k := range []string{"monday", "tuesday"} {
+			l, _, err := lists.List(k)
+			assertErrorFail(t, "", err, nil)
+			for j, v := range []string{"alice", "bob"} {
+				err := l.Add(v, uint64(i+j))
+				assertErrorFail(t, "", err, nil)
+			}
+		}
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		count, err := boltron.ReencryptLists(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 4)
+
+		count, err = boltron.ReencryptLists(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 0)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		lists := definition.Lists(tx)
+
+		has, err := lists.HasValue("bob")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		l, exists, err := lists.List("tuesday")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", exists, true)
+
+		orderBy, err := l.OrderBy("bob")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", orderBy, uint64(2))
+	})
+}
+
+func TestReencryptAssociation(t *testing.T) {
+	db := newDB(t)
+
+	newDefinition := func(keyProvider boltron.KeyProvider) *boltron.AssociationDefinition[string, uint64] {
+		return boltron.NewAssociationDefinition(
+			"badges",
+			boltron.Encoding[string](boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, &boltron.EncryptedEncodingOptions{
+				Deterministic: true,
+			})),
+			boltron.Uint64BinaryEncoding,
+			nil,
+		)
+	}
+
+	oldDefinition := newDefinition(boltron.NewStaticKeyProvider(1, map[uint32][]byte{
+		1: testEncryptionKey1,
+	}))
+	definition := newDefinition(boltron.NewStaticKeyProvider(2, map[uint32][]byte{
+		1: testEncryptionKey1,
+		2: testEncryptionKey2,
+	}))
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		a := oldDefinition.Association(tx)
+		for i, l := range []string{"alice", "bob", "carol"} {
+			err := a.Set(l, uint64(i))
+			assertErrorFail(t, "", err, nil)
+		}
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		count, err := boltron.ReencryptAssociation(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 3)
+
+		count, err = boltron.ReencryptAssociation(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 0)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		a := definition.Association(tx)
+
+		right, err := a.Right("bob")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", right, uint64(1))
+
+		left, err := a.Left(2)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", left, "carol")
+	})
+}
+
+func TestReencryptAssociations(t *testing.T) {
+	db := newDB(t)
+
+	newDefinition := func(keyProvider boltron.KeyProvider) *boltron.AssociationsDefinition[string, string, uint64] {
+		return boltron.NewAssociationsDefinition(
+			"badges",
+			boltron.Encoding[string](boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, &boltron.EncryptedEncodingOptions{
+				Deterministic: true,
+			})),
+			boltron.StringEncoding,
+			boltron.Uint64BinaryEncoding,
+			nil,
+		)
+	}
+
+	oldDefinition := newDefinition(boltron.NewStaticKeyProvider(1, map[uint32][]byte{
+		1: testEncryptionKey1,
+	}))
+	definition := newDefinition(boltron.NewStaticKeyProvider(2, map[uint32][]byte{
+		1: testEncryptionKey1,
+		2: testEncryptionKey2,
+	}))
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		associations := oldDefinition.Associations(tx)
+		for _, k := range []string{"gold", "silver"} {
+			a, _, err := associations.Association(k)
+			assertErrorFail(t, "", err, nil)
+			for i, l := range []string{"alice", "bob"} {
+				err := a.Set(l, uint64(i))
+				assertErrorFail(t, "", err, nil)
+			}
+		}
+
+		a, _, err := associations.Association("bronze")
+		assertErrorFail(t, "", err, nil)
+		err = a.Set("carol", 3)
+		assertErrorFail(t, "", err, nil)
+		err = a.DeleteByLeft("carol", true)
+		assertErrorFail(t, "", err, nil)
+
+		_, exists, err := associations.Association("bronze")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", exists, true)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		count, err := boltron.ReencryptAssociations(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 4)
+
+		count, err = boltron.ReencryptAssociations(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 0)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		associations := definition.Associations(tx)
+
+		has, err := associations.HasAssociation("silver")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		// associations without values are preserved
+		_, exists, err := associations.Association("bronze")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", exists, true)
+
+		var keys []string
+		_, err = associations.IterateAssociationsWithRightValue(1, nil, false, func(k string) (bool, error) {
+			keys = append(keys, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(keys), 2)
+	})
+}
+
+func TestReencryptCollections(t *testing.T) {
+	db := newDB(t)
+
+	newDefinition := func(keyProvider boltron.KeyProvider) *boltron.CollectionsDefinition[string, string, string] {
+		return boltron.NewCollectionsDefinition(
+			"records",
+			boltron.StringEncoding,
+			boltron.Encoding[string](boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, &boltron.EncryptedEncodingOptions{
+				Deterministic: true,
+			})),
+			boltron.Encoding[string](boltron.NewEncryptedEncoding(boltron.StringEncoding, keyProvider, nil)),
+			nil,
+		)
+	}
+
+	oldDefinition := newDefinition(boltron.NewStaticKeyProvider(1, map[uint32][]byte{
+		1: testEncryptionKey1,
+	}))
+	definition := newDefinition(boltron.NewStaticKeyProvider(2, map[uint32][]byte{
+		1: testEncryptionKey1,
+		2: testEncryptionKey2,
+	}))
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		collections := oldDefinition.Collections(tx)
+		for _, ck := range []string{"clinic a", "clinic b"} {
+			c, _, err := collections.Collection(ck)
+			assertErrorFail(t, "", err, nil)
+			for _, k := range []string{"alice", "bob"} {
+				_, err := c.Save(k, ck+" "+k, false)
+				assertErrorFail(t, "", err, nil)
+			}
+		}
+
+		c, _, err := collections.Collection("clinic c")
+		assertErrorFail(t, "", err, nil)
+		_, err = c.Save("carol", "clinic c carol", false)
+		assertErrorFail(t, "", err, nil)
+		err = c.Delete("carol", true)
+		assertErrorFail(t, "", err, nil)
+
+		_, exists, err := collections.Collection("clinic c")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", exists, true)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		count, err := boltron.ReencryptCollections(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 4)
+
+		count, err = boltron.ReencryptCollections(tx, definition)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 0)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		collections := definition.Collections(tx)
+
+		has, err := collections.HasKey("bob")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		c, _, err := collections.Collection("clinic b")
+		assertErrorFail(t, "", err, nil)
+		v, err := c.Get("alice")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, "clinic b alice")
+
+		// collections without keys are preserved
+		_, exists, err := collections.Collection("clinic c")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", exists, true)
+	})
+}
@@ -18,4 +18,21 @@ var (
 	// ErrInvalidPageNumber is returned on on pagination methods where page
 	// number is less than 1.
 	ErrInvalidPageNumber = errors.New("boltron: invalid page number")
+	// ErrCorrupted is returned when the stored data does not match its
+	// checksum.
+	ErrCorrupted = errors.New("boltron: corrupted")
+	// ErrKeysNotOrdered is returned by iteration and pagination methods if
+	// keys are hashed and the order of iteration is not the order of keys.
+	ErrKeysNotOrdered = errors.New("boltron: keys not ordered")
+	// ErrNotNumeric is returned by methods that require an encoding to
+	// implement NumericEncoding interface if it does not.
+	ErrNotNumeric = errors.New("boltron: encoding is not numeric")
+	// ErrOverflow is returned by numeric encodings if the result of an
+	// operation does not fit into the type.
+	ErrOverflow = errors.New("boltron: integer overflow")
+	// ErrIndexNotFound is returned by read methods in read-only transactions
+	// if data is stored before the index that they require was introduced. The
+	// index is created by the first write, or by an explicit rebuild method,
+	// like Associations RebuildRightIndex.
+	ErrIndexNotFound = errors.New("boltron: index not found")
 )
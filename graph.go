@@ -0,0 +1,971 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrPathNotFound is returned by Graph ShortestPath method if there is no path
+// between the nodes.
+var ErrPathNotFound = errors.New("boltron: path not found")
+
+// GraphDirection specifies which edges are followed by Graph traversal methods.
+type GraphDirection uint8
+
+// Graph directions.
+const (
+	// GraphOutgoing follows edges from the node to its targets.
+	GraphOutgoing GraphDirection = 1 << iota
+	// GraphIncoming follows edges from the node to its sources.
+	GraphIncoming
+	// GraphBoth follows both outgoing and incoming edges, treating the graph
+	// as undirected.
+	GraphBoth = GraphOutgoing | GraphIncoming
+)
+
+// GraphDefinition defines a directed graph of nodes connected by edges that
+// hold edge values. There can be at most one edge from one node to another.
+type GraphDefinition[N, E any] struct {
+	bucketNameNodes         []byte
+	bucketNameOutgoing      []byte
+	bucketNameIncoming      []byte
+	bucketNameOutgoingOrder []byte
+	bucketNameIncomingOrder []byte
+	nodeEncoding            Encoding[N]
+	edgeEncoding            Encoding[E]
+	orderByEdge             bool
+	fillPercent             float64
+	errNodeNotFound         error
+	errEdgeNotFound         error
+	corruptedHandler        func(key []byte, err error)
+}
+
+// GraphOptions provides additional configuration for a Graph.
+type GraphOptions struct {
+	// FillPercent is the value for the bolt bucket fill percent.
+	FillPercent float64
+	// OrderByEdge marks if adjacent nodes are ordered by encoded edge values,
+	// in the same way as List values are ordered by their order by values,
+	// instead of by encoded nodes. It is useful when edge values are weights
+	// or times. Traversal methods follow edges in this order.
+	OrderByEdge bool
+	// ErrNodeNotFound is returned if the node is not found.
+	ErrNodeNotFound error
+	// ErrEdgeNotFound is returned if the edge is not found.
+	ErrEdgeNotFound error
+	// CorruptedHandler is called with the encoded node of every node or edge
+	// that is skipped by iteration and pagination methods because it can not
+	// be decoded with ErrCorrupted error. For edges, it is the encoded
+	// adjacent node. If it is nil, iteration is aborted. Skipped elements are
+	// still counted in total elements and pages returned by pagination
+	// methods, so a page may contain fewer elements than the limit.
+	CorruptedHandler func(key []byte, err error)
+}
+
+// NewGraphDefinition constructs a new GraphDefinition with a unique name and
+// node and edge encodings.
+func NewGraphDefinition[N, E any](
+	name string,
+	nodeEncoding Encoding[N],
+	edgeEncoding Encoding[E],
+	o *GraphOptions,
+) *GraphDefinition[N, E] {
+	if o == nil {
+		o = new(GraphOptions)
+	}
+	return &GraphDefinition[N, E]{
+		bucketNameNodes:         []byte("boltron: graph: " + name + " nodes"),
+		bucketNameOutgoing:      []byte("boltron: graph: " + name + " outgoing"),
+		bucketNameIncoming:      []byte("boltron: graph: " + name + " incoming"),
+		bucketNameOutgoingOrder: []byte("boltron: graph: " + name + " outgoing order"),
+		bucketNameIncomingOrder: []byte("boltron: graph: " + name + " incoming order"),
+		nodeEncoding:            nodeEncoding,
+		edgeEncoding:            edgeEncoding,
+		orderByEdge:             o.OrderByEdge,
+		fillPercent:             o.FillPercent,
+		errNodeNotFound:         withDefaultError(o.ErrNodeNotFound, ErrNotFound),
+		errEdgeNotFound:         withDefaultError(o.ErrEdgeNotFound, ErrNotFound),
+		corruptedHandler:        o.CorruptedHandler,
+	}
+}
+
+// Graph returns a Graph that has access to the stored data through the bolt
+// transaction.
+func (d *GraphDefinition[N, E]) Graph(tx *bolt.Tx) *Graph[N, E] {
+	return &Graph[N, E]{
+		tx:         tx,
+		definition: d,
+	}
+}
+
+// Graph provides methods to access and change graph nodes and edges and to
+// traverse them within a single transaction.
+type Graph[N, E any] struct {
+	tx               *bolt.Tx
+	nodesBucketCache *bolt.Bucket
+	bucketsCache     map[string]*bolt.Bucket
+	definition       *GraphDefinition[N, E]
+}
+
+func (g *Graph[N, E]) nodesBucket(create bool) (*bolt.Bucket, error) {
+	if g.nodesBucketCache != nil {
+		return g.nodesBucketCache, nil
+	}
+	bucket, err := rootBucket(g.tx, create, g.definition.bucketNameNodes)
+	if err != nil {
+		return nil, err
+	}
+	if g.definition.fillPercent > 0 && bucket != nil {
+		bucket.FillPercent = g.definition.fillPercent
+	}
+	g.nodesBucketCache = bucket
+	return bucket, nil
+}
+
+func (g *Graph[N, E]) edgesBucket(outgoing, ordered, create bool) (*bolt.Bucket, error) {
+	var name []byte
+	switch {
+	case outgoing && !ordered:
+		name = g.definition.bucketNameOutgoing
+	case outgoing && ordered:
+		name = g.definition.bucketNameOutgoingOrder
+	case !outgoing && !ordered:
+		name = g.definition.bucketNameIncoming
+	default:
+		name = g.definition.bucketNameIncomingOrder
+	}
+	if bucket, ok := g.bucketsCache[string(name)]; ok {
+		return bucket, nil
+	}
+	bucket, err := rootBucket(g.tx, create, name)
+	if err != nil {
+		return nil, err
+	}
+	if bucket != nil {
+		if g.bucketsCache == nil {
+			g.bucketsCache = make(map[string]*bolt.Bucket)
+		}
+		g.bucketsCache[string(name)] = bucket
+	}
+	return bucket, nil
+}
+
+// adjacencyBucket returns the nested bucket with edges of the encoded node n.
+// Non-ordered buckets map adjacent nodes to edge values, while ordered buckets
+// map edge values concatenated with adjacent nodes to adjacent nodes.
+func (g *Graph[N, E]) adjacencyBucket(outgoing, ordered bool, n []byte, create bool) (*bolt.Bucket, error) {
+	edgesBucket, err := g.edgesBucket(outgoing, ordered, create)
+	if err != nil {
+		return nil, fmt.Errorf("edges bucket: %w", err)
+	}
+	if edgesBucket == nil {
+		return nil, nil
+	}
+	bucket, err := nestedBucket(edgesBucket, create, n)
+	if err != nil {
+		return nil, fmt.Errorf("adjacency bucket: %w", err)
+	}
+	if g.definition.fillPercent > 0 && bucket != nil {
+		bucket.FillPercent = g.definition.fillPercent
+	}
+	return bucket, nil
+}
+
+func (g *Graph[N, E]) hasNode(n []byte) (bool, error) {
+	nodesBucket, err := g.nodesBucket(false)
+	if err != nil {
+		return false, fmt.Errorf("nodes bucket: %w", err)
+	}
+	if nodesBucket == nil {
+		return false, nil
+	}
+	_, exists := bucketValue(nodesBucket, n)
+	return exists, nil
+}
+
+func (g *Graph[N, E]) putNode(n []byte) error {
+	nodesBucket, err := g.nodesBucket(true)
+	if err != nil {
+		return fmt.Errorf("nodes bucket: %w", err)
+	}
+	if err := nodesBucket.Put(n, []byte{}); err != nil {
+		return fmt.Errorf("put node: %w", err)
+	}
+	return nil
+}
+
+// AddNode saves the node without any edges. If the node already exists, it is
+// not changed.
+func (g *Graph[N, E]) AddNode(node N) error {
+	n, err := g.definition.nodeEncoding.Encode(node)
+	if err != nil {
+		return fmt.Errorf("encode node: %w", err)
+	}
+	return g.putNode(n)
+}
+
+// HasNode returns true if the node exists in the graph.
+func (g *Graph[N, E]) HasNode(node N) (bool, error) {
+	n, err := g.definition.nodeEncoding.Encode(node)
+	if err != nil {
+		return false, fmt.Errorf("encode node: %w", err)
+	}
+	return g.hasNode(n)
+}
+
+// DeleteNode removes the node and all of its outgoing and incoming edges. If
+// ensure flag is set to true and the node does not exist, configured
+// ErrNodeNotFound is returned.
+func (g *Graph[N, E]) DeleteNode(node N, ensure bool) error {
+	n, err := g.definition.nodeEncoding.Encode(node)
+	if err != nil {
+		return fmt.Errorf("encode node: %w", err)
+	}
+	has, err := g.hasNode(n)
+	if err != nil {
+		return err
+	}
+	if !has {
+		if ensure {
+			return g.definition.errNodeNotFound
+		}
+		return nil
+	}
+	for _, outgoing := range []bool{true, false} {
+		adjacent, err := g.adjacent(n, outgoing)
+		if err != nil {
+			return err
+		}
+		for _, a := range adjacent {
+			if outgoing {
+				err = g.deleteEdge(n, a)
+			} else {
+				err = g.deleteEdge(a, n)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	nodesBucket, err := g.nodesBucket(false)
+	if err != nil {
+		return fmt.Errorf("nodes bucket: %w", err)
+	}
+	if err := nodesBucket.Delete(n); err != nil {
+		return fmt.Errorf("delete node: %w", err)
+	}
+	return nil
+}
+
+// SetEdge saves the edge from one node to another with the edge value. Nodes
+// are added if they do not exist. If the edge already exists, its value is
+// replaced.
+func (g *Graph[N, E]) SetEdge(from, to N, edge E) error {
+	f, err := g.definition.nodeEncoding.Encode(from)
+	if err != nil {
+		return fmt.Errorf("encode from node: %w", err)
+	}
+	t, err := g.definition.nodeEncoding.Encode(to)
+	if err != nil {
+		return fmt.Errorf("encode to node: %w", err)
+	}
+	e, err := g.definition.edgeEncoding.Encode(edge)
+	if err != nil {
+		return fmt.Errorf("encode edge: %w", err)
+	}
+	if err := g.putNode(f); err != nil {
+		return err
+	}
+	if err := g.putNode(t); err != nil {
+		return err
+	}
+	if err := g.deleteEdge(f, t); err != nil {
+		return err
+	}
+	for _, outgoing := range []bool{true, false} {
+		n, a := f, t
+		if !outgoing {
+			n, a = t, f
+		}
+		bucket, err := g.adjacencyBucket(outgoing, false, n, true)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(a, e); err != nil {
+			return fmt.Errorf("put edge: %w", err)
+		}
+		if !g.definition.orderByEdge {
+			continue
+		}
+		bucket, err = g.adjacencyBucket(outgoing, true, n, true)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(append(append(make([]byte, 0, len(e)+len(a)), e...), a...), a); err != nil {
+			return fmt.Errorf("put edge order: %w", err)
+		}
+	}
+	return nil
+}
+
+// HasEdge returns true if the edge from one node to another exists.
+func (g *Graph[N, E]) HasEdge(from, to N) (bool, error) {
+	_, exists, err := g.edge(from, to)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// Edge returns the edge value of the edge from one node to another. If the edge
+// does not exist, configured ErrEdgeNotFound is returned.
+func (g *Graph[N, E]) Edge(from, to N) (edge E, err error) {
+	e, exists, err := g.edge(from, to)
+	if err != nil {
+		return edge, err
+	}
+	if !exists {
+		return edge, g.definition.errEdgeNotFound
+	}
+	edge, err = g.definition.edgeEncoding.Decode(e)
+	if err != nil {
+		return edge, fmt.Errorf("decode edge: %w", err)
+	}
+	return edge, nil
+}
+
+func (g *Graph[N, E]) edge(from, to N) (e []byte, exists bool, err error) {
+	f, err := g.definition.nodeEncoding.Encode(from)
+	if err != nil {
+		return nil, false, fmt.Errorf("encode from node: %w", err)
+	}
+	t, err := g.definition.nodeEncoding.Encode(to)
+	if err != nil {
+		return nil, false, fmt.Errorf("encode to node: %w", err)
+	}
+	bucket, err := g.adjacencyBucket(true, false, f, false)
+	if err != nil {
+		return nil, false, err
+	}
+	if bucket == nil {
+		return nil, false, nil
+	}
+	e, exists = bucketValue(bucket, t)
+	return e, exists, nil
+}
+
+// DeleteEdge removes the edge from one node to another, leaving nodes in the
+// graph. If ensure flag is set to true and the edge does not exist, configured
+// ErrEdgeNotFound is returned.
+func (g *Graph[N, E]) DeleteEdge(from, to N, ensure bool) error {
+	_, exists, err := g.edge(from, to)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if ensure {
+			return g.definition.errEdgeNotFound
+		}
+		return nil
+	}
+	f, err := g.definition.nodeEncoding.Encode(from)
+	if err != nil {
+		return fmt.Errorf("encode from node: %w", err)
+	}
+	t, err := g.definition.nodeEncoding.Encode(to)
+	if err != nil {
+		return fmt.Errorf("encode to node: %w", err)
+	}
+	return g.deleteEdge(f, t)
+}
+
+// deleteEdge removes the edge between encoded nodes from all adjacency buckets
+// if it exists.
+func (g *Graph[N, E]) deleteEdge(f, t []byte) error {
+	bucket, err := g.adjacencyBucket(true, false, f, false)
+	if err != nil {
+		return err
+	}
+	if bucket == nil {
+		return nil
+	}
+	e, exists := bucketValue(bucket, t)
+	if !exists {
+		return nil
+	}
+	e = append([]byte(nil), e...)
+	for _, outgoing := range []bool{true, false} {
+		n, a := f, t
+		if !outgoing {
+			n, a = t, f
+		}
+		if err := g.deleteAdjacent(outgoing, false, n, a); err != nil {
+			return err
+		}
+		if !g.definition.orderByEdge {
+			continue
+		}
+		if err := g.deleteAdjacent(outgoing, true, n, append(append(make([]byte, 0, len(e)+len(a)), e...), a...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteAdjacent removes the key k from the adjacency bucket of the encoded
+// node n and removes the adjacency bucket if it is empty.
+func (g *Graph[N, E]) deleteAdjacent(outgoing, ordered bool, n, k []byte) error {
+	edgesBucket, err := g.edgesBucket(outgoing, ordered, false)
+	if err != nil {
+		return fmt.Errorf("edges bucket: %w", err)
+	}
+	if edgesBucket == nil {
+		return errors.New("edges bucket does not exist")
+	}
+	bucket := edgesBucket.Bucket(n)
+	if bucket == nil {
+		return errors.New("adjacency bucket does not exist")
+	}
+	if err := bucket.Delete(k); err != nil {
+		return fmt.Errorf("delete edge: %w", err)
+	}
+	if f, _ := bucket.Cursor().First(); f == nil {
+		if err := edgesBucket.DeleteBucket(n); err != nil {
+			return fmt.Errorf("delete empty adjacency bucket: %w", err)
+		}
+	}
+	return nil
+}
+
+// forEachAdjacent calls f for every encoded adjacent node and edge value of the
+// encoded node n in the order of adjacency.
+func (g *Graph[N, E]) forEachAdjacent(n []byte, outgoing, reverse bool, f func(a, e []byte) (bool, error)) error {
+	ordered := g.definition.orderByEdge
+	bucket, err := g.adjacencyBucket(outgoing, ordered, n, false)
+	if err != nil {
+		return err
+	}
+	if bucket == nil {
+		return nil
+	}
+	_, _, err = iterate(bucket, nil, reverse, func(k, v []byte) (bool, error) {
+		if ordered {
+			return f(v, k[:len(k)-len(v)])
+		}
+		return f(k, v)
+	})
+	return err
+}
+
+// adjacent returns encoded adjacent nodes of the encoded node n.
+func (g *Graph[N, E]) adjacent(n []byte, outgoing bool) (s [][]byte, err error) {
+	err = g.forEachAdjacent(n, outgoing, false, func(a, _ []byte) (bool, error) {
+		s = append(s, append([]byte(nil), a...))
+		return true, nil
+	})
+	return s, err
+}
+
+// neighbors returns encoded adjacent nodes of the encoded node n in the
+// direction without duplicates.
+func (g *Graph[N, E]) neighbors(n []byte, direction GraphDirection, f func(a, e []byte) (bool, error)) error {
+	seen := make(map[string]struct{})
+	for _, outgoing := range []bool{true, false} {
+		if outgoing && direction&GraphOutgoing == 0 || !outgoing && direction&GraphIncoming == 0 {
+			continue
+		}
+		stop := false
+		if err := g.forEachAdjacent(n, outgoing, false, func(a, e []byte) (bool, error) {
+			if _, ok := seen[string(a)]; ok {
+				return true, nil
+			}
+			seen[string(a)] = struct{}{}
+			cont, err := f(a, e)
+			if err != nil {
+				return false, err
+			}
+			stop = !cont
+			return cont, nil
+		}); err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Size returns the number of nodes in the graph.
+func (g *Graph[N, E]) Size() (int, error) {
+	nodesBucket, err := g.nodesBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("nodes bucket: %w", err)
+	}
+	if nodesBucket == nil {
+		return 0, nil
+	}
+	return size(nodesBucket, false), nil
+}
+
+// IterateNodes iterates over nodes in the lexicographical order of encoded
+// nodes. If the callback function f returns false, the iteration stops and the
+// next can be used to continue the iteration.
+func (g *Graph[N, E]) IterateNodes(start *N, reverse bool, f func(N) (bool, error)) (next *N, err error) {
+	nodesBucket, err := g.nodesBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("nodes bucket: %w", err)
+	}
+	if nodesBucket == nil {
+		return nil, nil
+	}
+	return iterateKeys(nodesBucket, g.definition.nodeEncoding, g.definition.corruptedHandler, start, reverse, func(n, _ []byte) (bool, error) {
+		node, err := g.definition.nodeEncoding.Decode(n)
+		if err != nil {
+			if skipCorrupted(g.definition.corruptedHandler, n, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode node: %w", err)
+		}
+
+		return f(node)
+	})
+}
+
+// PageOfNodes returns at most a limit of nodes at the provided page number.
+func (g *Graph[N, E]) PageOfNodes(number, limit int, reverse bool) (s []N, totalElements, pages int, err error) {
+	nodesBucket, err := g.nodesBucket(false)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("nodes bucket: %w", err)
+	}
+	if nodesBucket == nil {
+		return nil, 0, 0, nil
+	}
+	return page(nodesBucket, false, number, limit, reverse, func(n, _ []byte) (node N, err error) {
+		node, err = g.definition.nodeEncoding.Decode(n)
+		if skipCorrupted(g.definition.corruptedHandler, n, err) {
+			return node, errSkipElement
+		}
+		return node, err
+	})
+}
+
+// GraphEdge is the type returned by Graph pagination methods as slice elements
+// that contain both nodes and the edge value.
+type GraphEdge[N, E any] struct {
+	From N
+	To   N
+	Edge E
+}
+
+// IterateOutgoing iterates over outgoing edges of the node in the order of
+// target nodes, or edge values if OrderByEdge option is set. If the callback
+// function f returns false, the iteration stops.
+func (g *Graph[N, E]) IterateOutgoing(node N, reverse bool, f func(to N, edge E) (bool, error)) error {
+	return g.iterateAdjacent(node, true, reverse, f)
+}
+
+// IterateIncoming iterates over incoming edges of the node in the order of
+// source nodes, or edge values if OrderByEdge option is set. If the callback
+// function f returns false, the iteration stops.
+func (g *Graph[N, E]) IterateIncoming(node N, reverse bool, f func(from N, edge E) (bool, error)) error {
+	return g.iterateAdjacent(node, false, reverse, f)
+}
+
+func (g *Graph[N, E]) iterateAdjacent(node N, outgoing, reverse bool, f func(N, E) (bool, error)) error {
+	n, err := g.definition.nodeEncoding.Encode(node)
+	if err != nil {
+		return fmt.Errorf("encode node: %w", err)
+	}
+	return g.forEachAdjacent(n, outgoing, reverse, func(a, e []byte) (bool, error) {
+		adjacent, err := g.definition.nodeEncoding.Decode(a)
+		if err != nil {
+			if skipCorrupted(g.definition.corruptedHandler, a, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode node: %w", err)
+		}
+
+		edge, err := g.definition.edgeEncoding.Decode(e)
+		if err != nil {
+			if skipCorrupted(g.definition.corruptedHandler, a, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode edge: %w", err)
+		}
+
+		return f(adjacent, edge)
+	})
+}
+
+// PageOfOutgoing returns at most a limit of outgoing edges of the node at the
+// provided page number.
+func (g *Graph[N, E]) PageOfOutgoing(node N, number, limit int, reverse bool) (s []GraphEdge[N, E], totalElements, pages int, err error) {
+	return g.pageAdjacent(node, true, number, limit, reverse)
+}
+
+// PageOfIncoming returns at most a limit of incoming edges of the node at the
+// provided page number.
+func (g *Graph[N, E]) PageOfIncoming(node N, number, limit int, reverse bool) (s []GraphEdge[N, E], totalElements, pages int, err error) {
+	return g.pageAdjacent(node, false, number, limit, reverse)
+}
+
+func (g *Graph[N, E]) pageAdjacent(node N, outgoing bool, number, limit int, reverse bool) (s []GraphEdge[N, E], totalElements, pages int, err error) {
+	n, err := g.definition.nodeEncoding.Encode(node)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("encode node: %w", err)
+	}
+	ordered := g.definition.orderByEdge
+	bucket, err := g.adjacencyBucket(outgoing, ordered, n, false)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if bucket == nil {
+		return nil, 0, 0, nil
+	}
+	return page(bucket, false, number, limit, reverse, func(k, v []byte) (ge GraphEdge[N, E], err error) {
+		a, e := k, v
+		if ordered {
+			a, e = v, k[:len(k)-len(v)]
+		}
+
+		adjacent, err := g.definition.nodeEncoding.Decode(a)
+		if err != nil {
+			if skipCorrupted(g.definition.corruptedHandler, a, err) {
+				return ge, errSkipElement
+			}
+			return ge, fmt.Errorf("decode node: %w", err)
+		}
+
+		edge, err := g.definition.edgeEncoding.Decode(e)
+		if err != nil {
+			if skipCorrupted(g.definition.corruptedHandler, a, err) {
+				return ge, errSkipElement
+			}
+			return ge, fmt.Errorf("decode edge: %w", err)
+		}
+
+		if outgoing {
+			return GraphEdge[N, E]{From: node, To: adjacent, Edge: edge}, nil
+		}
+		return GraphEdge[N, E]{From: adjacent, To: node, Edge: edge}, nil
+	})
+}
+
+// CountOutgoing returns the number of outgoing edges of the node.
+func (g *Graph[N, E]) CountOutgoing(node N) (int, error) {
+	return g.countAdjacent(node, true)
+}
+
+// CountIncoming returns the number of incoming edges of the node.
+func (g *Graph[N, E]) CountIncoming(node N) (int, error) {
+	return g.countAdjacent(node, false)
+}
+
+func (g *Graph[N, E]) countAdjacent(node N, outgoing bool) (int, error) {
+	n, err := g.definition.nodeEncoding.Encode(node)
+	if err != nil {
+		return 0, fmt.Errorf("encode node: %w", err)
+	}
+	bucket, err := g.adjacencyBucket(outgoing, false, n, false)
+	if err != nil {
+		return 0, err
+	}
+	if bucket == nil {
+		return 0, nil
+	}
+	return size(bucket, false), nil
+}
+
+// Neighbors returns nodes that are connected to the node by edges in the
+// provided direction, without duplicates.
+func (g *Graph[N, E]) Neighbors(node N, direction GraphDirection) (s []N, err error) {
+	n, err := g.definition.nodeEncoding.Encode(node)
+	if err != nil {
+		return nil, fmt.Errorf("encode node: %w", err)
+	}
+	err = g.neighbors(n, direction, func(a, _ []byte) (bool, error) {
+		neighbor, err := g.definition.nodeEncoding.Decode(a)
+		if err != nil {
+			return false, fmt.Errorf("decode node: %w", err)
+		}
+		s = append(s, neighbor)
+		return true, nil
+	})
+	return s, err
+}
+
+// BFS traverses the graph breadth-first from the start node, following edges in
+// the provided direction, and calls f for every reachable node with its depth.
+// The start node has depth 0. Nodes deeper than maxDepth are not visited,
+// unless maxDepth is not positive. If the callback function f returns false,
+// the traversal stops. If the start node does not exist, configured
+// ErrNodeNotFound is returned.
+func (g *Graph[N, E]) BFS(start N, direction GraphDirection, maxDepth int, f func(node N, depth int) (bool, error)) error {
+	return g.traverse(start, direction, maxDepth, false, f)
+}
+
+// DFS traverses the graph depth-first from the start node, in the same way as
+// BFS does breadth-first.
+func (g *Graph[N, E]) DFS(start N, direction GraphDirection, maxDepth int, f func(node N, depth int) (bool, error)) error {
+	return g.traverse(start, direction, maxDepth, true, f)
+}
+
+type graphVisit struct {
+	node  []byte
+	depth int
+}
+
+func (g *Graph[N, E]) traverse(start N, direction GraphDirection, maxDepth int, depthFirst bool, f func(N, int) (bool, error)) error {
+	s, err := g.definition.nodeEncoding.Encode(start)
+	if err != nil {
+		return fmt.Errorf("encode node: %w", err)
+	}
+	has, err := g.hasNode(s)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return g.definition.errNodeNotFound
+	}
+
+	visited := make(map[string]struct{})
+	pending := []graphVisit{{node: s}}
+	for len(pending) > 0 {
+		var v graphVisit
+		if depthFirst {
+			v = pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+		} else {
+			v = pending[0]
+			pending = pending[1:]
+		}
+		if _, ok := visited[string(v.node)]; ok {
+			continue
+		}
+		visited[string(v.node)] = struct{}{}
+
+		node, err := g.definition.nodeEncoding.Decode(v.node)
+		if err != nil {
+			return fmt.Errorf("decode node: %w", err)
+		}
+		cont, err := f(node, v.depth)
+		if err != nil {
+			return err
+		}
+		if !cont {
+			return nil
+		}
+
+		if maxDepth > 0 && v.depth >= maxDepth {
+			continue
+		}
+		var next []graphVisit
+		if err := g.neighbors(v.node, direction, func(a, _ []byte) (bool, error) {
+			if _, ok := visited[string(a)]; !ok {
+				next = append(next, graphVisit{node: append([]byte(nil), a...), depth: v.depth + 1})
+			}
+			return true, nil
+		}); err != nil {
+			return err
+		}
+		if depthFirst {
+			// push in reverse to visit neighbors in their adjacency order
+			for i := len(next) - 1; i >= 0; i-- {
+				pending = append(pending, next[i])
+			}
+		} else {
+			pending = append(pending, next...)
+		}
+	}
+	return nil
+}
+
+// ShortestPath returns the path of nodes from one node to another, following
+// edges in the provided direction, and its cost. If weight function is nil,
+// the path with the least number of edges is returned and the cost is the
+// number of edges. Otherwise, the cost is the sum of weights of edge values,
+// which must not be negative. If there is no path, ErrPathNotFound is
+// returned.
+func (g *Graph[N, E]) ShortestPath(from, to N, direction GraphDirection, weight func(E) (float64, error)) (path []N, cost float64, err error) {
+	f, err := g.definition.nodeEncoding.Encode(from)
+	if err != nil {
+		return nil, 0, fmt.Errorf("encode from node: %w", err)
+	}
+	t, err := g.definition.nodeEncoding.Encode(to)
+	if err != nil {
+		return nil, 0, fmt.Errorf("encode to node: %w", err)
+	}
+	for _, n := range [][]byte{f, t} {
+		has, err := g.hasNode(n)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !has {
+			return nil, 0, g.definition.errNodeNotFound
+		}
+	}
+
+	previous := map[string][]byte{string(f): nil}
+	costs := map[string]float64{string(f): 0}
+	done := make(map[string]struct{})
+	queue := &graphQueue{{node: f}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(graphQueueItem)
+		if _, ok := done[string(item.node)]; ok {
+			continue
+		}
+		done[string(item.node)] = struct{}{}
+		if string(item.node) == string(t) {
+			break
+		}
+		if err := g.neighbors(item.node, direction, func(a, e []byte) (bool, error) {
+			if _, ok := done[string(a)]; ok {
+				return true, nil
+			}
+			w := float64(1)
+			if weight != nil {
+				edge, err := g.definition.edgeEncoding.Decode(e)
+				if err != nil {
+					return false, fmt.Errorf("decode edge: %w", err)
+				}
+				w, err = weight(edge)
+				if err != nil {
+					return false, err
+				}
+				if w < 0 {
+					return false, errors.New("negative edge weight")
+				}
+			}
+			c := item.cost + w
+			if current, ok := costs[string(a)]; ok && current <= c {
+				return true, nil
+			}
+			a = append([]byte(nil), a...)
+			costs[string(a)] = c
+			previous[string(a)] = item.node
+			heap.Push(queue, graphQueueItem{node: a, cost: c})
+			return true, nil
+		}); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if _, ok := done[string(t)]; !ok {
+		return nil, 0, ErrPathNotFound
+	}
+	for n := t; n != nil; n = previous[string(n)] {
+		node, err := g.definition.nodeEncoding.Decode(n)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decode node: %w", err)
+		}
+		path = append(path, node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, costs[string(t)], nil
+}
+
+type graphQueueItem struct {
+	node []byte
+	cost float64
+}
+
+// graphQueue is a priority queue of nodes ordered by their path costs.
+type graphQueue []graphQueueItem
+
+func (q graphQueue) Len() int            { return len(q) }
+func (q graphQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q graphQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *graphQueue) Push(x interface{}) { *q = append(*q, x.(graphQueueItem)) }
+func (q *graphQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// FindCycle returns nodes of a directed cycle in the graph, with the first
+// node repeated at the end, or nil if the graph is acyclic.
+func (g *Graph[N, E]) FindCycle() (cycle []N, err error) {
+	nodesBucket, err := g.nodesBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("nodes bucket: %w", err)
+	}
+	if nodesBucket == nil {
+		return nil, nil
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var stack [][]byte
+	var found [][]byte
+
+	var visit func(n []byte) error
+	visit = func(n []byte) error {
+		state[string(n)] = visiting
+		stack = append(stack, n)
+		if err := g.forEachAdjacent(n, true, false, func(a, _ []byte) (bool, error) {
+			switch state[string(a)] {
+			case visiting:
+				for i := len(stack) - 1; i >= 0; i-- {
+					if string(stack[i]) == string(a) {
+						found = append(append(found, stack[i:]...), a)
+						break
+					}
+				}
+				return false, nil
+			case visited:
+				return true, nil
+			}
+			if err := visit(append([]byte(nil), a...)); err != nil {
+				return false, err
+			}
+			return found == nil, nil
+		}); err != nil {
+			return err
+		}
+		stack = stack[:len(stack)-1]
+		state[string(n)] = visited
+		return nil
+	}
+
+	if err := nodesBucket.ForEach(func(n, _ []byte) error {
+		if found != nil || state[string(n)] != 0 {
+			return nil
+		}
+		return visit(append([]byte(nil), n...))
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, n := range found {
+		node, err := g.definition.nodeEncoding.Decode(n)
+		if err != nil {
+			return nil, fmt.Errorf("decode node: %w", err)
+		}
+		cycle = append(cycle, node)
+	}
+	return cycle, nil
+}
+
+// HasCycle returns true if the graph contains a directed cycle.
+func (g *Graph[N, E]) HasCycle() (bool, error) {
+	cycle, err := g.FindCycle()
+	if err != nil {
+		return false, err
+	}
+	return cycle != nil, nil
+}
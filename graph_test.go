@@ -0,0 +1,296 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+var roadsDefinition = boltron.NewGraphDefinition(
+	"roads",
+	boltron.StringEncoding,
+	boltron.Uint64BinaryEncoding,
+	&boltron.GraphOptions{
+		OrderByEdge: true,
+	},
+)
+
+func TestGraph(t *testing.T) {
+	db := newDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		roads := roadsDefinition.Graph(tx)
+
+		for _, e := range []boltron.GraphEdge[string, uint64]{
+			{From: "a", To: "b", Edge: 7},
+			{From: "a", To: "c", Edge: 2},
+			{From: "c", To: "b", Edge: 3},
+			{From: "b", To: "d", Edge: 1},
+			{From: "c", To: "e", Edge: 10},
+			{From: "d", To: "e", Edge: 1},
+		} {
+			err := roads.SetEdge(e.From, e.To, e.Edge)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		err := roads.AddNode("island")
+		assertErrorFail(t, "", err, nil)
+
+		err = roads.SetEdge("a", "b", 8)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		roads := roadsDefinition.Graph(tx)
+
+		size, err := roads.Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 6)
+
+		edge, err := roads.Edge("a", "b")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", edge, uint64(8))
+
+		_, err = roads.Edge("b", "a")
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		edges, totalElements, _, err := roads.PageOfOutgoing("a", 1, 10, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", totalElements, 2)
+		assert(t, "ordered by edge", edges, []boltron.GraphEdge[string, uint64]{
+			{From: "a", To: "c", Edge: 2},
+			{From: "a", To: "b", Edge: 8},
+		})
+
+		edges, _, _, err = roads.PageOfIncoming("e", 1, 10, true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", edges, []boltron.GraphEdge[string, uint64]{
+			{From: "c", To: "e", Edge: 10},
+			{From: "d", To: "e", Edge: 1},
+		})
+
+		neighbors, err := roads.Neighbors("b", boltron.GraphBoth)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", neighbors, []string{"d", "c", "a"})
+
+		type visit struct {
+			Node  string
+			Depth int
+		}
+		var visits []visit
+		err = roads.BFS("a", boltron.GraphOutgoing, 2, func(n string, depth int) (bool, error) {
+			visits = append(visits, visit{n, depth})
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", visits, []visit{{"a", 0}, {"c", 1}, {"b", 1}, {"e", 2}, {"d", 2}})
+
+		visits = nil
+		err = roads.DFS("a", boltron.GraphOutgoing, 0, func(n string, depth int) (bool, error) {
+			visits = append(visits, visit{n, depth})
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", visits, []visit{{"a", 0}, {"c", 1}, {"b", 2}, {"d", 3}, {"e", 4}})
+
+		path, cost, err := roads.ShortestPath("a", "e", boltron.GraphOutgoing, nil)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", path, []string{"a", "c", "e"})
+		assert(t, "", cost, float64(2))
+
+		path, cost, err = roads.ShortestPath("a", "e", boltron.GraphOutgoing, func(e uint64) (float64, error) {
+			return float64(e), nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", path, []string{"a", "c", "b", "d", "e"})
+		assert(t, "", cost, float64(7))
+
+		_, _, err = roads.ShortestPath("e", "a", boltron.GraphOutgoing, nil)
+		assertError(t, "", err, boltron.ErrPathNotFound)
+
+		_, _, err = roads.ShortestPath("a", "missing", boltron.GraphOutgoing, nil)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		hasCycle, err := roads.HasCycle()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", hasCycle, false)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		roads := roadsDefinition.Graph(tx)
+
+		err := roads.SetEdge("e", "c", 4)
+		assertErrorFail(t, "", err, nil)
+
+		cycle, err := roads.FindCycle()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", cycle, []string{"c", "b", "d", "e", "c"})
+
+		err = roads.DeleteNode("e", true)
+		assertErrorFail(t, "", err, nil)
+
+		hasCycle, err := roads.HasCycle()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", hasCycle, false)
+
+		count, err := roads.CountIncoming("c")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 1)
+
+		err = roads.DeleteEdge("a", "c", true)
+		assertErrorFail(t, "", err, nil)
+
+		err = roads.DeleteEdge("a", "c", true)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		has, err := roads.HasNode("c")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		var outgoing []string
+		err = roads.IterateOutgoing("a", false, func(to string, _ uint64) (bool, error) {
+			outgoing = append(outgoing, to)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", outgoing, []string{"b"})
+	})
+}
+
+func TestGraph_nullEdge(t *testing.T) {
+	db := newDB(t)
+
+	for _, orderByEdge := range []bool{false, true} {
+		followsDefinition := boltron.NewGraphDefinition(
+			"follows",
+			boltron.StringEncoding,
+			boltron.NullEncoding,
+			&boltron.GraphOptions{
+				OrderByEdge: orderByEdge,
+			},
+		)
+
+		dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+			follows := followsDefinition.Graph(tx)
+
+			err := follows.SetEdge("alice", "bob", nil)
+			assertErrorFail(t, "", err, nil)
+
+			has, err := follows.HasEdge("alice", "bob")
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", has, true)
+
+			_, err = follows.Edge("alice", "bob")
+			assertErrorFail(t, "", err, nil)
+
+			_, err = follows.Edge("bob", "alice")
+			assertError(t, "", err, boltron.ErrNotFound)
+
+			// replacing the edge must not leave stale adjacent nodes
+			err = follows.SetEdge("alice", "bob", nil)
+			assertErrorFail(t, "", err, nil)
+
+			var incoming []string
+			err = follows.IterateIncoming("bob", false, func(from string, _ *struct{}) (bool, error) {
+				incoming = append(incoming, from)
+				return true, nil
+			})
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", incoming, []string{"alice"})
+
+			err = follows.DeleteEdge("alice", "bob", true)
+			assertErrorFail(t, "", err, nil)
+
+			err = follows.DeleteEdge("alice", "bob", true)
+			assertError(t, "", err, boltron.ErrNotFound)
+
+			count, err := follows.CountIncoming("bob")
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", count, 0)
+
+			count, err = follows.CountOutgoing("alice")
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", count, 0)
+
+			err = follows.SetEdge("alice", "carol", nil)
+			assertErrorFail(t, "", err, nil)
+
+			err = follows.DeleteNode("carol", true)
+			assertErrorFail(t, "", err, nil)
+
+			count, err = follows.CountOutgoing("alice")
+			assertErrorFail(t, "", err, nil)
+			assert(t, "", count, 0)
+		})
+	}
+}
+
+func TestGraph_skipCorrupted(t *testing.T) {
+	db := newDB(t)
+
+	var corrupted []string
+
+	definition := boltron.NewGraphDefinition(
+		"checksums",
+		boltron.StringEncoding,
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		&boltron.GraphOptions{
+			CorruptedHandler: func(key []byte, err error) {
+				assertError(t, "", err, boltron.ErrCorrupted)
+				corrupted = append(corrupted, string(key))
+			},
+		},
+	)
+
+	strictDefinition := boltron.NewGraphDefinition(
+		"checksums",
+		boltron.StringEncoding,
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		nil,
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		g := definition.Graph(tx)
+		for _, to := range []string{"a", "b", "c"} {
+			err := g.SetEdge("x", to, "edge "+to)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		// corrupt the edge value between x and b
+		bucket := tx.Bucket([]byte("boltron: graph: checksums outgoing")).Bucket([]byte("x"))
+		v := append([]byte(nil), bucket.Get([]byte("b"))...)
+		v[0] ^= 0xff
+		err := bucket.Put([]byte("b"), v)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		var outgoing []string
+		err := definition.Graph(tx).IterateOutgoing("x", false, func(to, _ string) (bool, error) {
+			outgoing = append(outgoing, to)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", outgoing, []string{"a", "c"})
+		assert(t, "", corrupted, []string{"b"})
+
+		elements, totalElements, pages, err := definition.Graph(tx).PageOfOutgoing("x", 1, 2, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(elements), 1)
+		assert(t, "", elements[0].To, "a")
+		assert(t, "", totalElements, 3)
+		assert(t, "", pages, 2)
+		assert(t, "", corrupted, []string{"b", "b"})
+
+		err = strictDefinition.Graph(tx).IterateOutgoing("x", false, func(_, _ string) (bool, error) {
+			return true, nil
+		})
+		assertError(t, "", err, boltron.ErrCorrupted)
+	})
+}
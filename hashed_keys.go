@@ -0,0 +1,198 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Hashed keys are stored in bolt buckets as a fixed size key that consists of
+// the prefix of the SHA-256 hash of the encoded key and a collision sequence
+// number. The original encoded key is stored as the prefix of the bolt value,
+// preceded by its length as uvarint. Keys with the same hash prefix are
+// distinguished by comparing their original values.
+const (
+	hashedKeyPrefixLen = 8
+	hashedKeyLen       = hashedKeyPrefixLen + 2
+)
+
+func hashedKeyPrefix(k []byte) []byte {
+	h := sha256.Sum256(k)
+	return h[:hashedKeyPrefixLen]
+}
+
+func encodeHashedEntry(k, v []byte) []byte {
+	b := make([]byte, 0, binary.MaxVarintLen64+len(k)+len(v))
+	b = binary.AppendUvarint(b, uint64(len(k)))
+	b = append(b, k...)
+	return append(b, v...)
+}
+
+func decodeHashedEntry(b []byte) (k, v []byte, err error) {
+	l, n := binary.Uvarint(b)
+	if n <= 0 || uint64(len(b)-n) < l {
+		return nil, nil, errors.New("invalid hashed key entry")
+	}
+	return b[n : n+int(l)], b[n+int(l):], nil
+}
+
+// hashedGet returns the bolt key and the value of the original key k.
+func hashedGet(bucket *bolt.Bucket, k []byte) (boltKey, v []byte) {
+	prefix := hashedKeyPrefix(k)
+	c := bucket.Cursor()
+	for bk, bv := c.Seek(prefix); bk != nil && bytes.HasPrefix(bk, prefix); bk, bv = c.Next() {
+		ek, ev, err := decodeHashedEntry(bv)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(ek, k) {
+			return bk, ev
+		}
+	}
+	return nil, nil
+}
+
+func hashedPut(bucket *bolt.Bucket, k, v []byte) error {
+	prefix := hashedKeyPrefix(k)
+	var boltKey []byte
+	var sequence uint64
+	c := bucket.Cursor()
+	for bk, bv := c.Seek(prefix); bk != nil && bytes.HasPrefix(bk, prefix); bk, bv = c.Next() {
+		ek, _, err := decodeHashedEntry(bv)
+		if err == nil && bytes.Equal(ek, k) {
+			boltKey = bk
+			break
+		}
+		sequence = uint64(binary.BigEndian.Uint16(bk[hashedKeyPrefixLen:])) + 1
+	}
+	if boltKey == nil {
+		if sequence > math.MaxUint16 {
+			return errors.New("too many hashed key collisions")
+		}
+		boltKey = make([]byte, hashedKeyLen)
+		copy(boltKey, prefix)
+		binary.BigEndian.PutUint16(boltKey[hashedKeyPrefixLen:], uint16(sequence))
+	} else {
+		boltKey = append([]byte(nil), boltKey...)
+	}
+	return bucket.Put(boltKey, encodeHashedEntry(k, v))
+}
+
+func hashedDelete(bucket *bolt.Bucket, k []byte) error {
+	boltKey, _ := hashedGet(bucket, k)
+	if boltKey == nil {
+		return nil
+	}
+	return bucket.Delete(append([]byte(nil), boltKey...))
+}
+
+// bucketGet returns the value for the encoded key from the bucket that
+// optionally stores hashed keys.
+func bucketGet(bucket *bolt.Bucket, hashed bool, k []byte) []byte {
+	if hashed {
+		_, v := hashedGet(bucket, k)
+		return v
+	}
+	return bucket.Get(k)
+}
+
+// bucketPut saves the value for the encoded key in the bucket that optionally
+// stores hashed keys.
+func bucketPut(bucket *bolt.Bucket, hashed bool, k, v []byte) error {
+	if hashed {
+		return hashedPut(bucket, k, v)
+	}
+	return bucket.Put(k, v)
+}
+
+// bucketDelete removes the encoded key from the bucket that optionally stores
+// hashed keys.
+func bucketDelete(bucket *bolt.Bucket, hashed bool, k []byte) error {
+	if hashed {
+		return hashedDelete(bucket, k)
+	}
+	return bucket.Delete(k)
+}
+
+// bucketEntry returns the original encoded key and value from the bolt key and
+// value.
+func bucketEntry(hashed bool, boltKey, boltValue []byte) (k, v []byte, err error) {
+	if hashed {
+		return decodeHashedEntry(boltValue)
+	}
+	return boltKey, boltValue, nil
+}
+
+// iterateBucket iterates over the bucket that optionally stores hashed keys.
+// Hashed keys are iterated in the order of their hashes, which is stable, but
+// not related to the order of the original keys. Callback function f receives
+// the original encoded keys.
+func iterateBucket[K any](bucket *bolt.Bucket, hashed bool, keyEncoding Encoding[K], corruptedHandler func(key []byte, err error), start *K, reverse bool, f func(k, v []byte) (bool, error)) (next *K, err error) {
+	if !hashed {
+		return iterateKeys(bucket, keyEncoding, corruptedHandler, start, reverse, f)
+	}
+
+	var startKey []byte
+	if start != nil {
+		k, err := keyEncoding.Encode(*start)
+		if err != nil {
+			return nil, fmt.Errorf("encode start key: %w", err)
+		}
+		startKey, _ = hashedGet(bucket, k)
+		if startKey == nil {
+			startKey = hashedKeyPrefix(k)
+		}
+	}
+
+	nextKey, nextValue, err := iterate(bucket, startKey, reverse, func(bk, bv []byte) (bool, error) {
+		k, v, err := decodeHashedEntry(bv)
+		if err != nil {
+			return false, err
+		}
+		return f(k, v)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for nextKey != nil {
+		k, _, err := decodeHashedEntry(nextValue)
+		if err != nil {
+			return nil, fmt.Errorf("decode start key: %w", err)
+		}
+		n, err := keyEncoding.Decode(k)
+		if err != nil {
+			if skipCorrupted(corruptedHandler, k, err) {
+				nextKey, nextValue = adjacent(bucket, nextKey, reverse)
+				continue
+			}
+			return nil, fmt.Errorf("decode start key: %w", err)
+		}
+		next = &n
+		break
+	}
+
+	return next, nil
+}
+
+// pageBucket returns a page of elements from the bucket that optionally stores
+// hashed keys. Callback function f receives the original encoded keys.
+func pageBucket[E any](bucket *bolt.Bucket, hashed bool, number, limit int, reverse bool, f func(k, v []byte) (E, error)) (s []E, totalElements, pages int, err error) {
+	return page(bucket, false, number, limit, reverse, func(bk, bv []byte) (e E, err error) {
+		k, v, err := bucketEntry(hashed, bk, bv)
+		if err != nil {
+			return e, err
+		}
+		return f(k, v)
+	})
+}
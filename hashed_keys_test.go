@@ -0,0 +1,214 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+func TestCollection_hashedKeys(t *testing.T) {
+	db := newDB(t)
+
+	newDefinition := func(unordered bool) *boltron.CollectionDefinition[string, int] {
+		return boltron.NewCollectionDefinition(
+			"urls",
+			boltron.StringEncoding,
+			boltron.IntBase10Encoding,
+			&boltron.CollectionOptions{
+				HashedKeys:         true,
+				UnorderedIteration: unordered,
+			},
+		)
+	}
+	definition := newDefinition(true)
+
+	longKey := "https://example.com/" + strings.Repeat("path/", 10000)
+	keys := []string{"https://example.com/a", "https://example.com/b", longKey}
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		urls := definition.Collection(tx)
+
+		for i, k := range keys {
+			_, err := urls.Save(k, i, false)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		_, err := urls.Save(longKey, 100, false)
+		assertErrorFail(t, "", err, boltron.ErrKeyExists)
+
+		overwritten, err := urls.Save(longKey, 100, true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", overwritten, true)
+
+		bucket := tx.Bucket([]byte("boltron: collection: urls"))
+		err = bucket.ForEach(func(k, _ []byte) error {
+			assert(t, "bolt key length", len(k), 10)
+			return nil
+		})
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		urls := definition.Collection(tx)
+
+		v, err := urls.Get(longKey)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, 100)
+
+		has, err := urls.Has("https://example.com/b")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		has, err = urls.Has("https://example.com/c")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		size, err := urls.Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 3)
+
+		var got []string
+		next, err := urls.IterateKeys(nil, false, func(k string) (bool, error) {
+			got = append(got, k)
+			return len(got) < 2, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		_, err = urls.IterateKeys(next, false, func(k string) (bool, error) {
+			got = append(got, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		sort.Strings(got)
+		assert(t, "", got, keys)
+
+		elements, totalElements, _, err := urls.Page(1, 10, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", totalElements, 3)
+		assert(t, "", len(elements), 3)
+
+		_, err = newDefinition(false).Collection(tx).IterateKeys(nil, false, func(string) (bool, error) {
+			return true, nil
+		})
+		assertError(t, "", err, boltron.ErrKeysNotOrdered)
+
+		_, _, _, err = newDefinition(false).Collection(tx).Page(1, 10, false)
+		assertError(t, "", err, boltron.ErrKeysNotOrdered)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		urls := definition.Collection(tx)
+
+		err := urls.Delete(longKey, true)
+		assertErrorFail(t, "", err, nil)
+
+		err = urls.Delete(longKey, true)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		has, err := urls.Has(longKey)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+	})
+}
+
+func TestAssociation_hashedLeftAndRight(t *testing.T) {
+	db := newDB(t)
+
+	definition := boltron.NewAssociationDefinition(
+		"paths",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		&boltron.AssociationOptions{
+			HashedLeft:         true,
+			HashedRight:        true,
+			UnorderedIteration: true,
+		},
+	)
+
+	longLeft := strings.Repeat("left/", 8000)
+	longRight := strings.Repeat("right/", 8000)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		paths := definition.Association(tx)
+
+		err := paths.Set(longLeft, longRight)
+		assertErrorFail(t, "", err, nil)
+
+		err = paths.Set("short", "value")
+		assertErrorFail(t, "", err, nil)
+
+		err = paths.Set(longLeft, "other")
+		assertError(t, "", err, boltron.ErrLeftExists)
+
+		err = paths.Set("other", longRight)
+		assertError(t, "", err, boltron.ErrRightExists)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		paths := definition.Association(tx)
+
+		r, err := paths.Right(longLeft)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", r, longRight)
+
+		l, err := paths.Left(longRight)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", l, longLeft)
+
+		got := make(map[string]string)
+		_, err = paths.Iterate(nil, false, func(l, r string) (bool, error) {
+			got[l] = r
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", got, map[string]string{longLeft: longRight, "short": "value"})
+
+		rights, _, _, err := paths.PageOfRightValues(1, 10, false)
+		assertErrorFail(t, "", err, nil)
+		sort.Strings(rights)
+		assert(t, "", rights, []string{longRight, "value"})
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		paths := definition.Association(tx)
+
+		err := paths.DeleteByRight(longRight, true)
+		assertErrorFail(t, "", err, nil)
+
+		has, err := paths.HasLeft(longLeft)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		has, err = paths.HasRight(longRight)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		err = paths.Replace(longLeft, "value")
+		assertErrorFail(t, "", err, nil)
+
+		has, err = paths.HasLeft("short")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		err = paths.Set("short", longRight)
+		assertErrorFail(t, "", err, nil)
+
+		err = paths.Swap(longLeft, "short")
+		assertErrorFail(t, "", err, nil)
+
+		r, err := paths.Right(longLeft)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", r, longRight)
+
+		l, err := paths.Left("value")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", l, "short")
+	})
+}
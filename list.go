@@ -6,23 +6,51 @@
 package boltron
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 
 	bolt "go.etcd.io/bbolt"
 )
 
+// ListTieBreak specifies the order of list elements with equal order by
+// values.
+type ListTieBreak uint8
+
+// List tie breaks.
+const (
+	// ListTieBreakValue orders elements with equal order by values by their
+	// encoded values, or by the time of addition for occurrences of the same
+	// value in multiset lists.
+	ListTieBreakValue ListTieBreak = iota
+	// ListTieBreakInsertion orders elements with equal order by values by the
+	// time of their addition, the first added being the first.
+	ListTieBreakInsertion
+	// ListTieBreakInsertionReverse orders elements with equal order by values
+	// by the time of their addition, the last added being the first.
+	ListTieBreakInsertionReverse
+)
+
 // ListDefinition defines a list of values, ordered by the provided order type.
-// List values are unique, but the order by values are not. If the order is
-// defined by the values encoding, or it is not important, order by encoding
-// should be set to NullEncoding.
+// List values are unique, unless the list is a multiset, but the order by
+// values are not. If the order is defined by the values encoding, or it is not
+// important, order by encoding should be set to NullEncoding.
 type ListDefinition[V, O any] struct {
 	bucketPath       [][]byte
 	bucketPathIndex  [][]byte
+	bucketPathRanks  [][]byte
 	valueEncoding    Encoding[V]
 	orderByEncoding  Encoding[O]
 	fillPercent      float64
 	errValueNotFound error
+	corruptedHandler func(key []byte, err error)
+	orderStatistics  bool
+	rankBlockSize    int
+	maxSize          int
+	evictHighest     bool
+	evictionHandler  func(value, orderBy []byte)
+	keys             listKeys
 	addCallback      func(value, orderBy []byte) error // used by Lists
 	removeCallback   func(value, orderBy []byte) error // used by Lists
 }
@@ -33,6 +61,56 @@ type ListOptions struct {
 	FillPercent float64
 	// ErrValueNotFound is returned if the value is not found.
 	ErrValueNotFound error
+	// CorruptedHandler is called with the bolt key of every element that is
+	// skipped by iteration and pagination methods because its value or order
+	// by can not be decoded with ErrCorrupted error. If it is nil, iteration
+	// is aborted. Skipped elements are still counted in total elements and
+	// pages returned by pagination methods, so a page may contain fewer
+	// elements than the limit.
+	CorruptedHandler func(key []byte, err error)
+	// OrderStatistics marks if the number of elements in blocks of the list
+	// is maintained, making Rank and At methods efficient for large lists at
+	// the cost of additional writes on every change. If the option is set on
+	// an existing list, statistics are created on the first change. Rank and
+	// At read the number of elements of every block before the requested
+	// one, which is much less than reading all elements before it. Once
+	// created, statistics are maintained on every change, even through a
+	// definition without this option, so that they are never stale.
+	OrderStatistics bool
+	// OrderStatisticsBlockSize is the number of list elements in a block of
+	// order statistics. Smaller blocks make Rank and At iterate over fewer
+	// elements, but they increase the number of blocks that are summed. If it
+	// is 0, DefaultOrderStatisticsBlockSize is used. Changing it affects only
+	// blocks that are split or created afterwards.
+	OrderStatisticsBlockSize int
+	// MaxSize is the maximal number of elements in the list. If it is greater
+	// than zero, elements with the lowest order by values are removed when the
+	// list grows beyond it on addition. Removed elements are returned by the
+	// AddAndEvict method. Order statistics are maintained for lists with the
+	// maximal size, as with the OrderStatistics option, so that the list size
+	// is known without iterating over the list.
+	MaxSize int
+	// EvictHighest marks that elements with the highest order by values are
+	// removed instead of the lowest ones when the list grows beyond MaxSize.
+	EvictHighest bool
+	// EvictionHandler is called with the encoded value and order by of every
+	// element that is removed because the list grew beyond MaxSize, by any
+	// method that adds to the list, including UpdateOrderBy and
+	// IncrementOrderBy.
+	EvictionHandler func(value, orderBy []byte)
+	// Multiset marks that the same value can be added to the list multiple
+	// times, every time as a new occurrence with its own order by. Methods
+	// that operate on a single occurrence, like OrderBy, Rank and
+	// UpdateOrderBy, use the first added occurrence, while Remove removes all
+	// of them. The option must not be changed for an existing list.
+	Multiset bool
+	// TieBreak specifies the order of elements with equal order by values. By
+	// default, they are ordered by their encoded values. When ordering by the
+	// time of addition, a value gets a new position among equal ones every time
+	// its order by is changed, except for occurrences in multiset lists that
+	// keep their positions. The option must not be changed for an existing
+	// list.
+	TieBreak ListTieBreak
 }
 
 // NewListDefinition constructs a new ListDefinition with a unique name and key
@@ -46,13 +124,28 @@ func NewListDefinition[V, O any](
 	if o == nil {
 		o = new(ListOptions)
 	}
+	rankBlockSize := o.OrderStatisticsBlockSize
+	if rankBlockSize <= 0 {
+		rankBlockSize = DefaultOrderStatisticsBlockSize
+	}
 	return &ListDefinition[V, O]{
 		bucketPath:       bucketPath("boltron: list: " + name + " values"),
 		bucketPathIndex:  bucketPath("boltron: list: " + name + " index"),
+		bucketPathRanks:  bucketPath("boltron: list: " + name + " ranks"),
 		valueEncoding:    valueEncoding,
 		orderByEncoding:  orderByEncoding,
 		fillPercent:      o.FillPercent,
 		errValueNotFound: withDefaultError(o.ErrValueNotFound, ErrNotFound),
+		corruptedHandler: o.CorruptedHandler,
+		orderStatistics:  o.OrderStatistics || o.MaxSize > 0,
+		rankBlockSize:    rankBlockSize,
+		maxSize:          o.MaxSize,
+		evictHighest:     o.EvictHighest,
+		evictionHandler:  o.EvictionHandler,
+		keys: listKeys{
+			multiset: o.Multiset,
+			tieBreak: o.TieBreak,
+		},
 	}
 }
 
@@ -70,6 +163,7 @@ type List[V, O any] struct {
 	tx               *bolt.Tx
 	listBucketCache  *bolt.Bucket
 	indexBucketCache *bolt.Bucket
+	ranksBucketCache *bolt.Bucket
 	definition       *ListDefinition[V, O]
 }
 
@@ -103,20 +197,84 @@ func (l *List[V, O]) indexBucket(create bool) (*bolt.Bucket, error) {
 	return bucket, nil
 }
 
+// ranksBucket returns the bucket with order statistics, or nil if they are not
+// maintained. The existing bucket is returned even if the definition does not
+// require order statistics, so that they are updated on every change. If
+// create is true, the definition requires order statistics and the bucket does
+// not exist, it is created from the current elements in the list bucket.
+func (l *List[V, O]) ranksBucket(listBucket *bolt.Bucket, create bool) (*bolt.Bucket, error) {
+	if l.ranksBucketCache != nil {
+		return l.ranksBucketCache, nil
+	}
+	bucket, err := deepBucket(l.tx, false, l.definition.bucketPathRanks...)
+	if err != nil {
+		return nil, err
+	}
+	if bucket == nil && create && l.definition.orderStatistics {
+		bucket, err = deepBucket(l.tx, true, l.definition.bucketPathRanks...)
+		if err != nil {
+			return nil, err
+		}
+		if listBucket != nil {
+			if err := rankBuild(bucket, listBucket, l.definition.rankBlockSize); err != nil {
+				return nil, fmt.Errorf("build order statistics: %w", err)
+			}
+		}
+	}
+	l.ranksBucketCache = bucket
+	return bucket, nil
+}
+
+// listPut puts the key and value to the list bucket and updates order
+// statistics.
+func (l *List[V, O]) listPut(listBucket *bolt.Bucket, k, v []byte) error {
+	ranksBucket, err := l.ranksBucket(listBucket, true)
+	if err != nil {
+		return fmt.Errorf("ranks bucket: %w", err)
+	}
+	if err := listBucket.Put(k, v); err != nil {
+		return err
+	}
+	if ranksBucket != nil {
+		if err := rankInsert(ranksBucket, listBucket, k, l.definition.rankBlockSize); err != nil {
+			return fmt.Errorf("order statistics: %w", err)
+		}
+	}
+	return nil
+}
+
+// listDelete deletes the key from the list bucket and updates order
+// statistics.
+func (l *List[V, O]) listDelete(listBucket *bolt.Bucket, k []byte) error {
+	ranksBucket, err := l.ranksBucket(listBucket, true)
+	if err != nil {
+		return fmt.Errorf("ranks bucket: %w", err)
+	}
+	if err := listBucket.Delete(k); err != nil {
+		return err
+	}
+	if ranksBucket != nil {
+		if err := rankDelete(ranksBucket, k); err != nil {
+			return fmt.Errorf("order statistics: %w", err)
+		}
+	}
+	return nil
+}
+
 // Has returns true if the value already exists in the database.
-func (o *List[V, O]) Has(value V) (bool, error) {
-	v, err := o.definition.valueEncoding.Encode(value)
+func (l *List[V, O]) Has(value V) (bool, error) {
+	v, err := l.definition.valueEncoding.Encode(value)
 	if err != nil {
 		return false, fmt.Errorf("encode value: %w", err)
 	}
-	indexBucket, err := o.indexBucket(false)
+	indexBucket, err := l.indexBucket(false)
 	if err != nil {
 		return false, fmt.Errorf("index bucket: %w", err)
 	}
 	if indexBucket == nil {
 		return false, nil
 	}
-	return indexBucket.Get(v) != nil, nil
+	return l.firstKey(indexBucket, v) != nil, nil
 }
 
 // OrderBy returns the saved order by instance for the provided value.
@@ -134,12 +292,12 @@ func (l *List[V, O]) OrderBy(value V) (orderBy O, err error) {
 		return orderBy, l.definition.errValueNotFound
 	}
 
-	o := indexBucket.Get(v)
-	if o == nil {
+	k := l.firstKey(indexBucket, v)
+	if k == nil {
 		return orderBy, l.definition.errValueNotFound
 	}
 
-	orderBy, err = l.definition.orderByEncoding.Decode(o)
+	orderBy, err = l.definition.orderByEncoding.Decode(l.definition.keys.orderBy(k, v))
 	if err != nil {
 		return orderBy, fmt.Errorf("decode order by: %w", err)
 	}
@@ -147,6 +305,105 @@ func (l *List[V, O]) OrderBy(value V) (orderBy O, err error) {
 	return orderBy, nil
 }
 
+// firstKey returns the list bucket key of the value, or of its first added
+// occurrence in multiset lists, or nil if the value does not exist.
+func (l *List[V, O]) firstKey(indexBucket *bolt.Bucket, v []byte) []byte {
+	if !l.definition.keys.multiset {
+		o, seq := l.definition.keys.splitIndex(indexBucket.Get(v))
+		if o == nil {
+			return nil
+		}
+		return l.definition.keys.key(o, v, seq)
+	}
+	occurrences := indexBucket.Bucket(v)
+	if occurrences == nil {
+		return nil
+	}
+	seq, o := occurrences.Cursor().First()
+	if seq == nil {
+		return nil
+	}
+	return l.definition.keys.key(o, v, seq)
+}
+
+// Count returns the number of occurrences of the value in the list. For lists
+// that are not multisets, it is either 0 or 1.
+func (l *List[V, O]) Count(value V) (count int, err error) {
+	v, err := l.definition.valueEncoding.Encode(value)
+	if err != nil {
+		return 0, fmt.Errorf("encode value: %w", err)
+	}
+	indexBucket, err := l.indexBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("index bucket: %w", err)
+	}
+	if indexBucket == nil {
+		return 0, nil
+	}
+	if !l.definition.keys.multiset {
+		if indexBucket.Get(v) == nil {
+			return 0, nil
+		}
+		return 1, nil
+	}
+	occurrences := indexBucket.Bucket(v)
+	if occurrences == nil {
+		return 0, nil
+	}
+	c := occurrences.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		count++
+	}
+	return count, nil
+}
+
+// IterateOccurrences iterates over order by values of all occurrences of the
+// value in the order in which they are added. For lists that are not
+// multisets, there is at most one occurrence. If the callback function f
+// returns false, the iteration stops.
+func (l *List[V, O]) IterateOccurrences(value V, f func(O) (bool, error)) error {
+	v, err := l.definition.valueEncoding.Encode(value)
+	if err != nil {
+		return fmt.Errorf("encode value: %w", err)
+	}
+	indexBucket, err := l.indexBucket(false)
+	if err != nil {
+		return fmt.Errorf("index bucket: %w", err)
+	}
+	if indexBucket == nil {
+		return nil
+	}
+	decode := func(o []byte) (bool, error) {
+		orderBy, err := l.definition.orderByEncoding.Decode(o)
+		if err != nil {
+			return false, fmt.Errorf("decode order by: %w", err)
+		}
+		return f(orderBy)
+	}
+	if !l.definition.keys.multiset {
+		if o, _ := l.definition.keys.splitIndex(indexBucket.Get(v)); o != nil {
+			_, err := decode(o)
+			return err
+		}
+		return nil
+	}
+	occurrences := indexBucket.Bucket(v)
+	if occurrences == nil {
+		return nil
+	}
+	c := occurrences.Cursor()
+	for k, o := c.First(); k != nil; k, o = c.Next() {
+		cont, err := decode(o)
+		if err != nil {
+			return err
+		}
+		if !cont {
+			break
+		}
+	}
+	return nil
+}
+
 // Add adds a value to the list with an order by instance.
 func (l *List[V, O]) Add(value V, orderBy O) error {
 	v, err := l.definition.valueEncoding.Encode(value)
@@ -158,88 +415,348 @@ func (l *List[V, O]) Add(value V, orderBy O) error {
 		return fmt.Errorf("encode order by: %w", err)
 	}
 
+	_, err = l.add(v, o, false)
+	return err
+}
+
+// AddAndEvict adds a value to the list with an order by instance, as Add does,
+// and returns elements that are removed from the list because it grew beyond
+// the MaxSize option. Evicted elements are returned in the order in which they
+// are removed from the end of the list, which may include the added value.
+func (l *List[V, O]) AddAndEvict(value V, orderBy O) (evicted []ListElement[V, O], err error) {
+	v, err := l.definition.valueEncoding.Encode(value)
+	if err != nil {
+		return nil, fmt.Errorf("encode value: %w", err)
+	}
+	o, err := l.definition.orderByEncoding.Encode(orderBy)
+	if err != nil {
+		return nil, fmt.Errorf("encode order by: %w", err)
+	}
+
+	encoded, err := l.add(v, o, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range encoded {
+		element, err := l.decodeElement(e.key, e.value)
+		if err != nil {
+			return nil, err
+		}
+		evicted = append(evicted, element)
+	}
+
+	return evicted, nil
+}
+
+// encodedListElement holds the list bucket key and the encoded value of a list
+// element.
+type encodedListElement struct {
+	key, value []byte
+}
+
+// add puts the encoded value with the encoded order by to both list and index
+// buckets, replacing the previous order by of the value, and returns elements
+// evicted because of the MaxSize option. In multiset lists, a new occurrence is
+// added, unless replace is true when the first added occurrence is replaced.
+func (l *List[V, O]) add(v, o []byte, replace bool) ([]encodedListElement, error) {
 	indexBucket, err := l.indexBucket(true)
 	if err != nil {
-		return fmt.Errorf("index bucket: %w", err)
+		return nil, fmt.Errorf("index bucket: %w", err)
 	}
 
 	listBucket, err := l.listBucket(true)
 	if err != nil {
-		return fmt.Errorf("list bucket: %w", err)
+		return nil, fmt.Errorf("list bucket: %w", err)
 	}
 
-	if previous := indexBucket.Get(v); previous != nil {
-		previousValue := append(previous, v...)
+	var seq []byte
+	if l.definition.keys.sequenced() {
+		if replace && l.definition.keys.multiset {
+			seq = l.definition.keys.sequence(l.firstKey(indexBucket, v), v)
+		}
+		if seq == nil {
+			n, err := listBucket.NextSequence()
+			if err != nil {
+				return nil, fmt.Errorf("next sequence: %w", err)
+			}
+			seq = encodeListSequence(n)
+		}
+	}
+
+	if previous := l.previousKey(indexBucket, v, seq); previous != nil {
 		// ensure the deletion for data consistency
-		if listBucket.Get(previousValue) == nil {
-			return errors.New("previous value not found")
+		if listBucket.Get(previous) == nil {
+			return nil, errors.New("previous value not found")
 		}
-		if err := listBucket.Delete(previousValue); err != nil {
-			return fmt.Errorf("delete previous value: %w", err)
+		if err := l.listDelete(listBucket, previous); err != nil {
+			return nil, fmt.Errorf("delete previous value: %w", err)
 		}
 	}
 
-	if err := listBucket.Put(append(o, v...), v); err != nil {
-		return fmt.Errorf("put to list bucket: %w", err)
+	if err := l.listPut(listBucket, l.definition.keys.key(o, v, seq), v); err != nil {
+		return nil, fmt.Errorf("put to list bucket: %w", err)
 	}
-	if err := indexBucket.Put(v, o); err != nil {
-		return fmt.Errorf("put to index bucket: %w", err)
+	if err := l.indexPut(indexBucket, v, o, seq); err != nil {
+		return nil, fmt.Errorf("put to index bucket: %w", err)
 	}
 
 	if l.definition.addCallback != nil {
 		if err := l.definition.addCallback(v, o); err != nil {
-			return fmt.Errorf("add callback: %w", err)
+			return nil, fmt.Errorf("add callback: %w", err)
 		}
 	}
 
-	return nil
+	evicted, err := l.evict(listBucket, indexBucket)
+	if err != nil {
+		return nil, fmt.Errorf("evict: %w", err)
+	}
+
+	return evicted, nil
 }
 
-// Remove removes the value and its associated order by from the database. If
-// ensure flag is set to true and the value does not exist, ErrNotFound is
-// returned.
-func (l *List[V, O]) Remove(value V, ensure bool) error {
+// previousKey returns the list bucket key of the value, or of its occurrence
+// with the sequence in multiset lists, that is replaced by adding it.
+func (l *List[V, O]) previousKey(indexBucket *bolt.Bucket, v, seq []byte) []byte {
+	if !l.definition.keys.multiset {
+		return l.firstKey(indexBucket, v)
+	}
+	occurrences := indexBucket.Bucket(v)
+	if occurrences == nil {
+		return nil
+	}
+	o := occurrences.Get(seq)
+	if o == nil {
+		return nil
+	}
+	return l.definition.keys.key(o, v, seq)
+}
+
+// indexPut associates the encoded order by with the value, or with its
+// occurrence with the sequence in multiset lists, in the index bucket. If the
+// list is not a multiset, the sequence is stored after the order by.
+func (l *List[V, O]) indexPut(indexBucket *bolt.Bucket, v, o, seq []byte) error {
+	if !l.definition.keys.multiset {
+		return indexBucket.Put(v, append(o[:len(o):len(o)], seq...))
+	}
+	occurrences, err := indexBucket.CreateBucketIfNotExists(v)
+	if err != nil {
+		return err
+	}
+	return occurrences.Put(seq, o)
+}
+
+// evict removes elements from the lowest or the highest end of the list until
+// it has no more than MaxSize elements. The number of elements to remove is
+// known from order statistics, so only the evicted elements are iterated.
+func (l *List[V, O]) evict(listBucket, indexBucket *bolt.Bucket) ([]encodedListElement, error) {
+	maxSize := l.definition.maxSize
+	if maxSize <= 0 {
+		return nil, nil
+	}
+
+	ranksBucket, err := l.ranksBucket(listBucket, true)
+	if err != nil {
+		return nil, fmt.Errorf("ranks bucket: %w", err)
+	}
+	if ranksBucket == nil {
+		return nil, errors.New("order statistics are not maintained")
+	}
+	n := rankTotal(ranksBucket) - maxSize
+	if n <= 0 {
+		return nil, nil
+	}
+
+	c := listBucket.Cursor()
+	ov, v := c.First()
+	next := c.Next
+	if l.definition.evictHighest {
+		ov, v = c.Last()
+		next = c.Prev
+	}
+	evicted := make([]encodedListElement, 0, n)
+	for ; ov != nil && len(evicted) < n; ov, v = next() {
+		evicted = append(evicted, encodedListElement{
+			key:   append([]byte(nil), ov...),
+			value: append([]byte(nil), v...),
+		})
+	}
+
+	for _, e := range evicted {
+		if err := l.remove(listBucket, indexBucket, e.key, e.value); err != nil {
+			return nil, err
+		}
+		if l.definition.evictionHandler != nil {
+			l.definition.evictionHandler(e.value, l.definition.keys.orderBy(e.key, e.value))
+		}
+	}
+
+	return evicted, nil
+}
+
+// IncrementOrderBy adds the delta to the order by of the value and returns the
+// new order by. If the value does not exist, it is added with the delta as its
+// order by. Order by encoding must implement NumericEncoding interface,
+// otherwise ErrNotNumeric is returned.
+func (l *List[V, O]) IncrementOrderBy(value V, delta O) (O, error) {
+	numeric, ok := l.definition.orderByEncoding.(NumericEncoding[O])
+	if !ok {
+		var orderBy O
+		return orderBy, ErrNotNumeric
+	}
+	return l.UpdateOrderBy(value, func(old O, _ bool) (O, error) {
+		return numeric.Add(old, delta)
+	})
+}
+
+// UpdateOrderBy sets the order by of the value to the one returned by the
+// function f that receives the current order by and false if the value does
+// not exist. The value is added if it does not exist. The new order by is
+// returned. In multiset lists, the first added occurrence is updated.
+func (l *List[V, O]) UpdateOrderBy(value V, f func(old O, exists bool) (O, error)) (orderBy O, err error) {
 	v, err := l.definition.valueEncoding.Encode(value)
 	if err != nil {
-		return fmt.Errorf("encode value: %w", err)
+		return orderBy, fmt.Errorf("encode value: %w", err)
 	}
 
 	indexBucket, err := l.indexBucket(false)
 	if err != nil {
-		return fmt.Errorf("index bucket: %w", err)
+		return orderBy, fmt.Errorf("index bucket: %w", err)
 	}
 
-	if indexBucket == nil {
-		if ensure {
-			return l.definition.errValueNotFound
+	var old O
+	var exists bool
+	if indexBucket != nil {
+		if k := l.firstKey(indexBucket, v); k != nil {
+			old, err = l.definition.orderByEncoding.Decode(l.definition.keys.orderBy(k, v))
+			if err != nil {
+				return orderBy, fmt.Errorf("decode order by: %w", err)
+			}
+			exists = true
 		}
-		return nil
 	}
 
-	o := indexBucket.Get(v)
-	if o == nil {
-		if ensure {
-			return l.definition.errValueNotFound
-		}
-		return nil
+	orderBy, err = f(old, exists)
+	if err != nil {
+		return orderBy, err
 	}
 
-	listBucket, err := l.listBucket(false)
+	o, err := l.definition.orderByEncoding.Encode(orderBy)
 	if err != nil {
-		return fmt.Errorf("list bucket: %w", err)
+		return orderBy, fmt.Errorf("encode order by: %w", err)
+	}
+
+	if _, err := l.add(v, o, true); err != nil {
+		return orderBy, err
 	}
 
+	return orderBy, nil
+}
+
+// Remove removes the value and its associated order by from the database. If
+// ensure flag is set to true and the value does not exist, ErrNotFound is
+// returned. In multiset lists, all occurrences of the value are removed.
+func (l *List[V, O]) Remove(value V, ensure bool) error {
+	count, err := l.removeOccurrences(value, true)
+	if err != nil {
+		return err
+	}
+	if count == 0 && ensure {
+		return l.definition.errValueNotFound
+	}
+	return nil
+}
+
+// RemoveOne removes the first added occurrence of the value from the database.
+// For lists that are not multisets, it is the same as Remove. If ensure flag is
+// set to true and the value does not exist, ErrNotFound is returned.
+func (l *List[V, O]) RemoveOne(value V, ensure bool) error {
+	count, err := l.removeOccurrences(value, false)
+	if err != nil {
+		return err
+	}
+	if count == 0 && ensure {
+		return l.definition.errValueNotFound
+	}
+	return nil
+}
+
+// RemoveAll removes all occurrences of the value from the database and returns
+// the number of removed occurrences.
+func (l *List[V, O]) RemoveAll(value V) (count int, err error) {
+	return l.removeOccurrences(value, true)
+}
+
+func (l *List[V, O]) removeOccurrences(value V, all bool) (count int, err error) {
+	v, err := l.definition.valueEncoding.Encode(value)
+	if err != nil {
+		return 0, fmt.Errorf("encode value: %w", err)
+	}
+
+	indexBucket, err := l.indexBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("index bucket: %w", err)
+	}
+	if indexBucket == nil {
+		return 0, nil
+	}
+
+	listBucket, err := l.listBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("list bucket: %w", err)
+	}
 	if listBucket == nil {
-		if ensure {
-			return l.definition.errValueNotFound
+		return 0, nil
+	}
+
+	for {
+		k := l.firstKey(indexBucket, v)
+		if k == nil {
+			break
+		}
+		if err := l.remove(listBucket, indexBucket, k, v); err != nil {
+			return count, err
+		}
+		count++
+		if !all {
+			break
 		}
-		return nil
 	}
 
-	if err := listBucket.Delete(append(o, v...)); err != nil {
+	return count, nil
+}
+
+// remove deletes the list bucket key of the encoded value from both list and
+// index buckets.
+func (l *List[V, O]) remove(listBucket, indexBucket *bolt.Bucket, k, v []byte) error {
+	o := append([]byte(nil), l.definition.keys.orderBy(k, v)...)
+
+	if err := l.listDelete(listBucket, k); err != nil {
 		return fmt.Errorf("delete from list bucket: %w", err)
 	}
-	if err := indexBucket.Delete(v); err != nil {
+
+	if l.definition.keys.multiset {
+		occurrences := indexBucket.Bucket(v)
+		if occurrences == nil {
+			return errors.New("value occurrences not found")
+		}
+		if err := occurrences.Delete(l.definition.keys.sequence(k, v)); err != nil {
+			return fmt.Errorf("delete from index bucket: %w", err)
+		}
+		if _, last := occurrences.Cursor().Last(); last != nil {
+			// other occurrences remain, update the order by in callback to
+			// the last added one
+			if l.definition.addCallback != nil {
+				if err := l.definition.addCallback(v, append([]byte(nil), last...)); err != nil {
+					return fmt.Errorf("add callback: %w", err)
+				}
+			}
+			return nil
+		}
+		if err := indexBucket.DeleteBucket(v); err != nil {
+			return fmt.Errorf("delete from index bucket: %w", err)
+		}
+	} else if err := indexBucket.Delete(v); err != nil {
 		return fmt.Errorf("delete from index bucket: %w", err)
 	}
 
@@ -252,6 +769,118 @@ func (l *List[V, O]) Remove(value V, ensure bool) error {
 	return nil
 }
 
+// First returns the element with the lowest order by, without removing it. If
+// the list is empty, nil is returned.
+func (l *List[V, O]) First() (*ListElement[V, O], error) {
+	return l.peek(false)
+}
+
+// Last returns the element with the highest order by, without removing it. If
+// the list is empty, nil is returned.
+func (l *List[V, O]) Last() (*ListElement[V, O], error) {
+	return l.peek(true)
+}
+
+func (l *List[V, O]) peek(reverse bool) (*ListElement[V, O], error) {
+	listBucket, err := l.listBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("list bucket: %w", err)
+	}
+	if listBucket == nil {
+		return nil, nil
+	}
+	var ov, v []byte
+	if reverse {
+		ov, v = listBucket.Cursor().Last()
+	} else {
+		ov, v = listBucket.Cursor().First()
+	}
+	if ov == nil {
+		return nil, nil
+	}
+	e, err := l.decodeElement(ov, v)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// PopFirst removes at most n elements with the lowest order by values and
+// returns them in the ascending order.
+func (l *List[V, O]) PopFirst(n int) ([]ListElement[V, O], error) {
+	return l.pop(n, false)
+}
+
+// PopLast removes at most n elements with the highest order by values and
+// returns them in the descending order.
+func (l *List[V, O]) PopLast(n int) ([]ListElement[V, O], error) {
+	return l.pop(n, true)
+}
+
+func (l *List[V, O]) pop(n int, reverse bool) (s []ListElement[V, O], err error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	listBucket, err := l.listBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("list bucket: %w", err)
+	}
+	if listBucket == nil {
+		return nil, nil
+	}
+	indexBucket, err := l.indexBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("index bucket: %w", err)
+	}
+	if indexBucket == nil {
+		return nil, errors.New("index bucket does not exist")
+	}
+
+	var encoded []encodedListElement
+	_, _, err = iterate(listBucket, nil, reverse, func(ov, v []byte) (bool, error) {
+		e, err := l.decodeElement(ov, v)
+		if err != nil {
+			return false, err
+		}
+		s = append(s, e)
+		encoded = append(encoded, encodedListElement{
+			key:   append([]byte(nil), ov...),
+			value: append([]byte(nil), v...),
+		})
+		return len(s) < n, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range encoded {
+		if err := l.remove(listBucket, indexBucket, e.key, e.value); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// decodeElement decodes the list bucket key and value into the list element.
+func (l *List[V, O]) decodeElement(ov, v []byte) (e ListElement[V, O], err error) {
+	value, err := l.definition.valueEncoding.Decode(v)
+	if err != nil {
+		return e, fmt.Errorf("decode value: %w", err)
+	}
+
+	orderBy, err := l.definition.orderByEncoding.Decode(l.definition.keys.orderBy(ov, v))
+	if err != nil {
+		return e, fmt.Errorf("decode order by: %w", err)
+	}
+
+	return ListElement[V, O]{
+		Value:    value,
+		OrderBy:  orderBy,
+		Sequence: decodeListSequence(l.definition.keys.sequence(ov, v)),
+	}, nil
+}
+
 // Iterate iterates over keys and values in the lexicographical order of keys.
 // If the callback function f returns false, the iteration stops and the next
 // can be used to continue the iteration.
@@ -263,14 +892,20 @@ func (l *List[V, O]) Iterate(start *ListElement[V, O], reverse bool, f func(V, O
 	if listBucket == nil {
 		return nil, nil
 	}
-	return iterateList(listBucket, l.definition.valueEncoding, l.definition.orderByEncoding, start, reverse, func(ov, v []byte) (bool, error) {
+	return iterateList(listBucket, l.definition.valueEncoding, l.definition.orderByEncoding, l.definition.keys, l.definition.corruptedHandler, start, reverse, func(ov, v []byte) (bool, error) {
 		value, err := l.definition.valueEncoding.Decode(v)
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, ov, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode value: %w", err)
 		}
 
-		orderBy, err := l.definition.orderByEncoding.Decode(ov[:len(ov)-len(v)])
+		orderBy, err := l.definition.orderByEncoding.Decode(l.definition.keys.orderBy(ov, v))
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, ov, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode order by: %w", err)
 		}
 
@@ -289,9 +924,12 @@ func (l *List[V, O]) IterateValues(start *ListElement[V, O], reverse bool, f fun
 	if listBucket == nil {
 		return nil, nil
 	}
-	return iterateList(listBucket, l.definition.valueEncoding, l.definition.orderByEncoding, start, reverse, func(_, v []byte) (bool, error) {
+	return iterateList(listBucket, l.definition.valueEncoding, l.definition.orderByEncoding, l.definition.keys, l.definition.corruptedHandler, start, reverse, func(ov, v []byte) (bool, error) {
 		value, err := l.definition.valueEncoding.Decode(v)
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, ov, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode value: %w", err)
 		}
 
@@ -316,6 +954,11 @@ func (l *List[V, O]) Size() (int, error) {
 type ListElement[V, O any] struct {
 	Value   V
 	OrderBy O
+	// Sequence is the insertion sequence of the element in multiset lists
+	// and lists with tie breaks by insertion, used to continue iteration from
+	// the exact element among the ones with the same order by. It is zero for
+	// other lists.
+	Sequence uint64
 }
 
 // Page returns at most a limit of elements of values and order by instances at
@@ -331,17 +974,24 @@ func (l *List[V, O]) Page(number, limit int, reverse bool) (s []ListElement[V, O
 	return page(listBucket, false, number, limit, reverse, func(ov, v []byte) (e ListElement[V, O], err error) {
 		value, err := l.definition.valueEncoding.Decode(v)
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, ov, err) {
+				return e, errSkipElement
+			}
 			return e, fmt.Errorf("decode value: %w", err)
 		}
 
-		orderBy, err := l.definition.orderByEncoding.Decode(ov[:len(ov)-len(v)])
+		orderBy, err := l.definition.orderByEncoding.Decode(l.definition.keys.orderBy(ov, v))
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, ov, err) {
+				return e, errSkipElement
+			}
 			return e, fmt.Errorf("decode order by: %w", err)
 		}
 
 		return ListElement[V, O]{
-			Value:   value,
-			OrderBy: orderBy,
+			Value:    value,
+			OrderBy:  orderBy,
+			Sequence: decodeListSequence(l.definition.keys.sequence(ov, v)),
 		}, nil
 	})
 }
@@ -356,7 +1006,385 @@ func (l *List[V, O]) PageOfValues(number, limit int, reverse bool) (s []V, total
 	if listBucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(listBucket, false, number, limit, reverse, func(_, v []byte) (value V, err error) {
-		return l.definition.valueEncoding.Decode(v)
+	return page(listBucket, false, number, limit, reverse, func(ov, v []byte) (value V, err error) {
+		value, err = l.definition.valueEncoding.Decode(v)
+		if skipCorrupted(l.definition.corruptedHandler, ov, err) {
+			return value, errSkipElement
+		}
+		return value, err
+	})
+}
+
+// IterateOrderByRange iterates over elements with order by values between min
+// and max, inclusive, in the lexicographical order of order by. If min or max
+// is nil, the range is not limited on that side. If the callback function f
+// returns false, the iteration stops.
+func (l *List[V, O]) IterateOrderByRange(min, max *O, reverse bool, f func(V, O) (bool, error)) error {
+	listBucket, err := l.listBucket(false)
+	if err != nil {
+		return fmt.Errorf("list bucket: %w", err)
+	}
+	if listBucket == nil {
+		return nil
+	}
+	minOrderBy, maxOrderBy, err := l.encodeOrderByRange(min, max)
+	if err != nil {
+		return err
+	}
+	return iterateOrderByRange(listBucket, l.definition.keys, minOrderBy, maxOrderBy, reverse, func(ov, v []byte) (bool, error) {
+		e, err := l.decodeElement(ov, v)
+		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, ov, err) {
+				return true, nil
+			}
+			return false, err
+		}
+
+		return f(e.Value, e.OrderBy)
+	})
+}
+
+// CountOrderByRange returns the number of elements with order by values
+// between min and max, inclusive. If min or max is nil, the range is not
+// limited on that side.
+func (l *List[V, O]) CountOrderByRange(min, max *O) (count int, err error) {
+	listBucket, err := l.listBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("list bucket: %w", err)
+	}
+	if listBucket == nil {
+		return 0, nil
+	}
+	minOrderBy, maxOrderBy, err := l.encodeOrderByRange(min, max)
+	if err != nil {
+		return 0, err
+	}
+	err = iterateOrderByRange(listBucket, l.definition.keys, minOrderBy, maxOrderBy, false, func(_, _ []byte) (bool, error) {
+		count++
+		return true, nil
+	})
+	return count, err
+}
+
+// PageOrderByRange returns at most a limit of elements with order by values
+// between min and max, inclusive, at the provided page number. If min or max is
+// nil, the range is not limited on that side.
+func (l *List[V, O]) PageOrderByRange(min, max *O, number, limit int, reverse bool) (s []ListElement[V, O], totalElements, pages int, err error) {
+	if number <= 0 {
+		return nil, 0, 0, ErrInvalidPageNumber
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	listBucket, err := l.listBucket(false)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("list bucket: %w", err)
+	}
+	if listBucket == nil {
+		return nil, 0, 0, nil
+	}
+	minOrderBy, maxOrderBy, err := l.encodeOrderByRange(min, max)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	start := (number - 1) * limit
+	end := number * limit
+	if err := iterateOrderByRange(listBucket, l.definition.keys, minOrderBy, maxOrderBy, reverse, func(ov, v []byte) (bool, error) {
+		totalElements++
+		if totalElements <= start || totalElements > end {
+			return true, nil
+		}
+		e, err := l.decodeElement(ov, v)
+		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, ov, err) {
+				return true, nil
+			}
+			return false, err
+		}
+		s = append(s, e)
+		return true, nil
+	}); err != nil {
+		return nil, 0, 0, err
+	}
+	pages = totalElements / limit
+	if totalElements%limit != 0 {
+		pages++
+	}
+	return s, totalElements, pages, nil
+}
+
+func (l *List[V, O]) encodeOrderByRange(min, max *O) (minOrderBy, maxOrderBy []byte, err error) {
+	if min != nil {
+		minOrderBy, err = l.definition.orderByEncoding.Encode(*min)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encode min order by: %w", err)
+		}
+	}
+	if max != nil {
+		maxOrderBy, err = l.definition.orderByEncoding.Encode(*max)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encode max order by: %w", err)
+		}
+	}
+	return minOrderBy, maxOrderBy, nil
+}
+
+// iterateOrderByRange iterates over list bucket elements with encoded order by
+// values between min and max, inclusive, seeking by the encoded order by
+// prefix. Nil min or max does not limit the range on that side.
+func iterateOrderByRange(bucket *bolt.Bucket, keys listKeys, min, max []byte, reverse bool, f func(k, v []byte) (bool, error)) error {
+	cursor := bucket.Cursor()
+
+	var k, v []byte
+	var next func() (k, v []byte)
+	if !reverse {
+		next = cursor.Next
+		if min == nil {
+			k, v = cursor.First()
+		} else {
+			k, v = cursor.Seek(min)
+		}
+	} else {
+		next = cursor.Prev
+		upper := prefixSuccessor(max)
+		if upper == nil {
+			k, v = cursor.Last()
+		} else {
+			k, v = cursor.Seek(upper)
+			if k == nil {
+				k, v = cursor.Last()
+			} else {
+				k, v = cursor.Prev()
+			}
+		}
+	}
+
+	for ; k != nil; k, v = next() {
+		if !reverse && max != nil && bytes.Compare(k, max) > 0 && !bytes.HasPrefix(k, max) {
+			break
+		}
+		if reverse && min != nil && bytes.Compare(k, min) < 0 {
+			break
+		}
+		if len(v)+keys.sequenceLength() > len(k) {
+			continue
+		}
+		o := keys.orderBy(k, v)
+		if min != nil && bytes.Compare(o, min) < 0 || max != nil && bytes.Compare(o, max) > 0 {
+			continue
+		}
+		cont, err := f(k, v)
+		if err != nil {
+			return err
+		}
+		if !cont {
+			break
+		}
+	}
+	return nil
+}
+
+// prefixSuccessor returns the smallest key that is greater than all keys with
+// the prefix p, or nil if there is no such key.
+func prefixSuccessor(p []byte) []byte {
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i] != 0xff {
+			s := append([]byte(nil), p[:i+1]...)
+			s[i]++
+			return s
+		}
+	}
+	return nil
+}
+
+// Rank returns the zero based position of the value in the list ordered by
+// order by values, or in the reverse order if reverse is true. If the value
+// does not exist, configured ErrValueNotFound is returned. Without
+// OrderStatistics option, the list is iterated up to the value. In multiset
+// lists, the position of the first added occurrence is returned.
+func (l *List[V, O]) Rank(value V, reverse bool) (int, error) {
+	v, err := l.definition.valueEncoding.Encode(value)
+	if err != nil {
+		return 0, fmt.Errorf("encode value: %w", err)
+	}
+	indexBucket, err := l.indexBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("index bucket: %w", err)
+	}
+	if indexBucket == nil {
+		return 0, l.definition.errValueNotFound
+	}
+	k := l.firstKey(indexBucket, v)
+	if k == nil {
+		return 0, l.definition.errValueNotFound
+	}
+	listBucket, err := l.listBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("list bucket: %w", err)
+	}
+	if listBucket == nil {
+		return 0, l.definition.errValueNotFound
+	}
+
+	ranksBucket, err := l.ranksBucket(listBucket, false)
+	if err != nil {
+		return 0, fmt.Errorf("ranks bucket: %w", err)
+	}
+	if ranksBucket != nil {
+		rank := rankOf(ranksBucket, listBucket, k)
+		if reverse {
+			rank = rankTotal(ranksBucket) - 1 - rank
+		}
+		return rank, nil
+	}
+
+	var rank int
+	_, _, err = iterate(listBucket, nil, reverse, func(lk, _ []byte) (bool, error) {
+		if bytes.Equal(lk, k) {
+			return false, nil
+		}
+		rank++
+		return true, nil
 	})
+	return rank, err
+}
+
+// At returns the element at the zero based position in the list ordered by
+// order by values, or in the reverse order if reverse is true. If the index is
+// out of range, configured ErrValueNotFound is returned. Without
+// OrderStatistics option, the list is iterated up to the index.
+func (l *List[V, O]) At(index int, reverse bool) (e ListElement[V, O], err error) {
+	if index < 0 {
+		return e, l.definition.errValueNotFound
+	}
+	listBucket, err := l.listBucket(false)
+	if err != nil {
+		return e, fmt.Errorf("list bucket: %w", err)
+	}
+	if listBucket == nil {
+		return e, l.definition.errValueNotFound
+	}
+
+	ranksBucket, err := l.ranksBucket(listBucket, false)
+	if err != nil {
+		return e, fmt.Errorf("ranks bucket: %w", err)
+	}
+	var k, v []byte
+	if ranksBucket != nil {
+		if reverse {
+			index = rankTotal(ranksBucket) - 1 - index
+		}
+		if index >= 0 {
+			k, v = rankAt(ranksBucket, listBucket, index)
+		}
+	} else {
+		var i int
+		_, _, err = iterate(listBucket, nil, reverse, func(lk, lv []byte) (bool, error) {
+			if i == index {
+				k, v = lk, lv
+				return false, nil
+			}
+			i++
+			return true, nil
+		})
+		if err != nil {
+			return e, err
+		}
+	}
+	if k == nil {
+		return e, l.definition.errValueNotFound
+	}
+	return l.decodeElement(k, v)
+}
+
+// listKeys composes list bucket keys from the encoded order by, value and, in
+// multiset lists and lists with tie breaks by insertion, the insertion
+// sequence, so that the keys are ordered by order by values first.
+type listKeys struct {
+	multiset bool
+	tieBreak ListTieBreak
+}
+
+const listSequenceLength = 8
+
+// sequenced returns true if the keys contain the insertion sequence.
+func (k listKeys) sequenced() bool {
+	return k.multiset || k.tieBreak != ListTieBreakValue
+}
+
+func (k listKeys) sequenceLength() int {
+	if k.sequenced() {
+		return listSequenceLength
+	}
+	return 0
+}
+
+// key returns a new list bucket key. The sequence is placed before the value
+// if ties are broken by insertion, inverted for the reverse order.
+func (k listKeys) key(o, v, seq []byte) []byte {
+	key := make([]byte, 0, len(o)+len(v)+len(seq))
+	key = append(key, o...)
+	switch k.tieBreak {
+	case ListTieBreakInsertion:
+		key = append(key, seq...)
+		key = append(key, v...)
+	case ListTieBreakInsertionReverse:
+		for _, b := range seq {
+			key = append(key, ^b)
+		}
+		key = append(key, v...)
+	default:
+		key = append(key, v...)
+		if k.multiset {
+			key = append(key, seq...)
+		}
+	}
+	return key
+}
+
+// orderBy returns the encoded order by from the list bucket key.
+func (k listKeys) orderBy(key, v []byte) []byte {
+	return key[:len(key)-len(v)-k.sequenceLength()]
+}
+
+// sequence returns the encoded insertion sequence from the list bucket key, or
+// nil if the keys do not contain it.
+func (k listKeys) sequence(key, v []byte) []byte {
+	if !k.sequenced() || key == nil {
+		return nil
+	}
+	switch k.tieBreak {
+	case ListTieBreakInsertion:
+		return key[len(key)-len(v)-listSequenceLength : len(key)-len(v)]
+	case ListTieBreakInsertionReverse:
+		seq := make([]byte, 0, listSequenceLength)
+		for _, b := range key[len(key)-len(v)-listSequenceLength : len(key)-len(v)] {
+			seq = append(seq, ^b)
+		}
+		return seq
+	}
+	return key[len(key)-listSequenceLength:]
+}
+
+// splitIndex returns the encoded order by and the insertion sequence from the
+// index bucket value of a list that is not a multiset.
+func (k listKeys) splitIndex(i []byte) (o, seq []byte) {
+	if i == nil || !k.sequenced() {
+		return i, nil
+	}
+	if len(i) < listSequenceLength {
+		return nil, nil
+	}
+	return i[:len(i)-listSequenceLength], i[len(i)-listSequenceLength:]
+}
+
+func encodeListSequence(seq uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, seq)
+}
+
+func decodeListSequence(b []byte) uint64 {
+	if len(b) != listSequenceLength {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
 }
@@ -8,6 +8,8 @@ package boltron_test
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -525,6 +527,718 @@ func TestList_pageOfValues(t *testing.T) {
 	})
 }
 
+func TestList_firstLast(t *testing.T) {
+	dbView(t, newDB(t), func(t testing.TB, tx *bolt.Tx) {
+		todo := todoDefinition.List(tx)
+
+		first, err := todo.First()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", first, (*boltron.ListElement[string, time.Time])(nil))
+	})
+
+	dbView(t, newTodoDB(t), func(t testing.TB, tx *bolt.Tx) {
+		todo := todoDefinition.List(tx)
+
+		first, err := todo.First()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", first.Value, testTodo[0].Value)
+		assertTime(t, "", first.OrderBy, testTodo[0].Time)
+
+		last, err := todo.Last()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", last.Value, testTodo[len(testTodo)-1].Value)
+		assertTime(t, "", last.OrderBy, testTodo[len(testTodo)-1].Time)
+	})
+}
+
+func TestList_pop(t *testing.T) {
+	db := newTodoDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		todo := todoDefinition.List(tx)
+
+		elements, err := todo.PopFirst(2)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(elements), 2)
+		for i, e := range elements {
+			assert(t, "", e.Value, testTodo[i].Value)
+			assertTime(t, "", e.OrderBy, testTodo[i].Time)
+		}
+
+		elements, err = todo.PopLast(3)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(elements), 3)
+		for i, e := range elements {
+			assert(t, "", e.Value, testTodo[len(testTodo)-1-i].Value)
+		}
+
+		for _, v := range testTodo[:2] {
+			has, err := todo.Has(v.Value)
+			assertErrorFail(t, "", err, nil)
+			assert(t, v.Value, has, false)
+		}
+
+		elements, err = todo.PopFirst(10)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(elements), 4)
+
+		elements, err = todo.PopLast(1)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(elements), 0)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		size, err := todoDefinition.List(tx).Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 0)
+	})
+}
+
+func TestList_orderByRange(t *testing.T) {
+	db := newTodoDB(t)
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		todo := todoDefinition.List(tx)
+
+		min := testTodo[2].Time
+		max := testTodo[5].Time
+
+		var values []string
+		err := todo.IterateOrderByRange(&min, &max, false, func(v string, _ time.Time) (bool, error) {
+			values = append(values, v)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []string{testTodo[2].Value, testTodo[3].Value, testTodo[4].Value, testTodo[5].Value})
+
+		values = nil
+		err = todo.IterateOrderByRange(&min, &max, true, func(v string, _ time.Time) (bool, error) {
+			values = append(values, v)
+			return len(values) < 2, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []string{testTodo[5].Value, testTodo[4].Value})
+
+		values = nil
+		err = todo.IterateOrderByRange(nil, &min, true, func(v string, _ time.Time) (bool, error) {
+			values = append(values, v)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []string{testTodo[2].Value, testTodo[1].Value, testTodo[0].Value})
+
+		count, err := todo.CountOrderByRange(&max, nil)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 4)
+
+		between := min.Add(time.Second)
+		count, err = todo.CountOrderByRange(&between, &between)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 0)
+
+		elements, totalElements, pages, err := todo.PageOrderByRange(&min, &max, 2, 3, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", totalElements, 4)
+		assert(t, "", pages, 2)
+		assert(t, "", len(elements), 1)
+		assert(t, "", elements[0].Value, testTodo[5].Value)
+
+		_, _, _, err = todo.PageOrderByRange(&min, &max, 0, 3, false)
+		assertError(t, "", err, boltron.ErrInvalidPageNumber)
+	})
+}
+
+func TestList_rankAndAt(t *testing.T) {
+	for _, tc := range []struct {
+		orderStatistics bool
+		blockSize       int
+	}{
+		{orderStatistics: false},
+		{orderStatistics: true},
+		{orderStatistics: true, blockSize: 3},
+	} {
+		t.Run(fmt.Sprintf("order statistics %v block size %v", tc.orderStatistics, tc.blockSize), func(t *testing.T) {
+			db := newDB(t)
+
+			definition := boltron.NewListDefinition(
+				"leaderboard",
+				boltron.StringEncoding,
+				boltron.Uint64BinaryEncoding,
+				&boltron.ListOptions{
+					OrderStatistics:          tc.orderStatistics,
+					OrderStatisticsBlockSize: tc.blockSize,
+				},
+			)
+
+			r := rand.New(rand.NewSource(1))
+			scores := make(map[string]uint64)
+
+			dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+				leaderboard := definition.List(tx)
+
+				for i := 0; i < 3000; i++ {
+					user := fmt.Sprintf("user-%v", r.Intn(1500))
+					if r.Intn(4) == 0 {
+						err := leaderboard.Remove(user, false)
+						assertErrorFail(t, "", err, nil)
+						delete(scores, user)
+						continue
+					}
+					score := uint64(r.Intn(1000))
+					err := leaderboard.Add(user, score)
+					assertErrorFail(t, "", err, nil)
+					scores[user] = score
+				}
+
+				elements, err := leaderboard.PopFirst(10)
+				assertErrorFail(t, "", err, nil)
+				for _, e := range elements {
+					delete(scores, e.Value)
+				}
+			})
+
+			dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+				leaderboard := definition.List(tx)
+
+				var elements []boltron.ListElement[string, uint64]
+				_, err := leaderboard.Iterate(nil, false, func(v string, o uint64) (bool, error) {
+					elements = append(elements, boltron.ListElement[string, uint64]{Value: v, OrderBy: o})
+					return true, nil
+				})
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", len(elements), len(scores))
+
+				for i, e := range elements {
+					rank, err := leaderboard.Rank(e.Value, false)
+					assertErrorFail(t, "", err, nil)
+					assert(t, e.Value, rank, i)
+
+					rank, err = leaderboard.Rank(e.Value, true)
+					assertErrorFail(t, "", err, nil)
+					assert(t, e.Value, rank, len(elements)-1-i)
+
+					got, err := leaderboard.At(i, false)
+					assertErrorFail(t, "", err, nil)
+					assert(t, "", got, e)
+
+					got, err = leaderboard.At(len(elements)-1-i, true)
+					assertErrorFail(t, "", err, nil)
+					assert(t, "", got, e)
+				}
+
+				_, err = leaderboard.Rank("missing", false)
+				assertError(t, "", err, boltron.ErrNotFound)
+
+				_, err = leaderboard.At(len(elements), false)
+				assertError(t, "", err, boltron.ErrNotFound)
+
+				_, err = leaderboard.At(len(elements), true)
+				assertError(t, "", err, boltron.ErrNotFound)
+			})
+		})
+	}
+}
+
+func TestList_incrementOrderBy(t *testing.T) {
+	db := newDB(t)
+
+	definition := boltron.NewListDefinition(
+		"scores",
+		boltron.StringEncoding,
+		boltron.Int64Base36Encoding,
+		nil,
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		scores := definition.List(tx)
+
+		score, err := scores.IncrementOrderBy("alice", 10)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", score, int64(10))
+
+		score, err = scores.IncrementOrderBy("bob", 5)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", score, int64(5))
+
+		score, err = scores.IncrementOrderBy("alice", -7)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", score, int64(3))
+
+		page, _, _, err := scores.Page(1, 10, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", page, []boltron.ListElement[string, int64]{
+			{Value: "alice", OrderBy: 3},
+			{Value: "bob", OrderBy: 5},
+		})
+
+		_, err = scores.IncrementOrderBy("bob", math.MaxInt64)
+		assertError(t, "", err, boltron.ErrOverflow)
+
+		orderBy, err := scores.OrderBy("bob")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", orderBy, int64(5))
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		_, err := todoDefinition.List(tx).IncrementOrderBy("Fix bug", time.Time{})
+		assertError(t, "", err, boltron.ErrNotNumeric)
+	})
+}
+
+func TestList_updateOrderBy(t *testing.T) {
+	db := newTodoDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		todo := todoDefinition.List(tx)
+
+		first := testTodo[0]
+		last := testTodo[len(testTodo)-1]
+
+		orderBy, err := todo.UpdateOrderBy(first.Value, func(old time.Time, exists bool) (time.Time, error) {
+			assertTime(t, "", old, first.Time)
+			assert(t, "", exists, true)
+			return last.Time.Add(time.Hour), nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assertTime(t, "", orderBy, last.Time.Add(time.Hour))
+
+		e, err := todo.Last()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", e.Value, first.Value)
+		assertTime(t, "", e.OrderBy, last.Time.Add(time.Hour))
+
+		now := time.Unix(1700000000, 0)
+		_, err = todo.UpdateOrderBy("New task", func(old time.Time, exists bool) (time.Time, error) {
+			assert(t, "", exists, false)
+			return now, nil
+		})
+		assertErrorFail(t, "", err, nil)
+
+		orderBy, err = todo.OrderBy("New task")
+		assertErrorFail(t, "", err, nil)
+		assertTime(t, "", orderBy, now)
+
+		errTest := errors.New("test error")
+		_, err = todo.UpdateOrderBy(last.Value, func(old time.Time, exists bool) (time.Time, error) {
+			return time.Time{}, errTest
+		})
+		assertError(t, "", err, errTest)
+
+		orderBy, err = todo.OrderBy(last.Value)
+		assertErrorFail(t, "", err, nil)
+		assertTime(t, "", orderBy, last.Time)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		size, err := todoDefinition.List(tx).Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, len(testTodo)+1)
+	})
+}
+
+func TestList_maxSize(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		options      *boltron.ListOptions
+		evicted      []string
+		remaining    []string
+		addedEvicted bool
+	}{
+		{
+			name:      "lowest",
+			options:   &boltron.ListOptions{MaxSize: 3},
+			evicted:   []string{"Plan new features"},
+			remaining: []string{"Implement new features", "Release new features", "Write more tests"},
+		},
+		{
+			name:      "lowest with order statistics",
+			options:   &boltron.ListOptions{MaxSize: 3, OrderStatistics: true},
+			evicted:   []string{"Plan new features"},
+			remaining: []string{"Implement new features", "Release new features", "Write more tests"},
+		},
+		{
+			name:      "highest",
+			options:   &boltron.ListOptions{MaxSize: 3, EvictHighest: true},
+			evicted:   []string{"Write more tests"},
+			remaining: []string{"Add documentation", "Update README.md", "Make a release"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newDB(t)
+
+			definition := boltron.NewListDefinition("todo", boltron.StringEncoding, boltron.TimeEncoding, tc.options)
+
+			dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+				todo := definition.List(tx)
+
+				var evicted []string
+				for i, n := range testTodo {
+					if i == 0 {
+						// the first element is added at the end
+						continue
+					}
+					e, err := todo.AddAndEvict(n.Value, n.Time)
+					assertErrorFail(t, "", err, nil)
+					if i <= 3 {
+						assert(t, "", len(e), 0)
+					} else {
+						assert(t, "", len(e), 1)
+					}
+					for _, e := range e {
+						evicted = append(evicted, e.Value)
+					}
+				}
+				assert(t, "", len(evicted), len(testTodo)-4)
+
+				e, err := todo.AddAndEvict(testTodo[0].Value, testTodo[len(testTodo)-1].Time.Add(time.Hour))
+				assertErrorFail(t, "", err, nil)
+				var values []string
+				for _, e := range e {
+					values = append(values, e.Value)
+				}
+				assert(t, "", values, tc.evicted)
+
+				var remaining []string
+				_, err = todo.IterateValues(nil, false, func(v string) (bool, error) {
+					remaining = append(remaining, v)
+					return true, nil
+				})
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", remaining, tc.remaining)
+
+				for _, v := range evicted {
+					has, err := todo.Has(v)
+					assertErrorFail(t, v, err, nil)
+					assert(t, v, has, false)
+				}
+			})
+
+			dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+				size, err := definition.List(tx).Size()
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", size, 3)
+			})
+		})
+	}
+}
+
+func TestList_evictionHandler(t *testing.T) {
+	db := newDB(t)
+
+	// list that is filled before the maximal size is set
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		scores := boltron.NewListDefinition("scores", boltron.StringEncoding, boltron.Uint64BinaryEncoding, nil).List(tx)
+		for i, v := range []string{"a", "b", "c", "d", "e"} {
+			err := scores.Add(v, uint64(i))
+			assertErrorFail(t, "", err, nil)
+		}
+	})
+
+	var evicted []string
+	definition := boltron.NewListDefinition("scores", boltron.StringEncoding, boltron.Uint64BinaryEncoding, &boltron.ListOptions{
+		MaxSize: 3,
+		EvictionHandler: func(value, orderBy []byte) {
+			evicted = append(evicted, string(value))
+		},
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		scores := definition.List(tx)
+
+		err := scores.Add("f", 10)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", evicted, []string{"a", "b", "c"})
+
+		_, err = scores.UpdateOrderBy("g", func(uint64, bool) (uint64, error) {
+			return 20, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", evicted, []string{"a", "b", "c", "d"})
+
+		_, err = scores.IncrementOrderBy("h", 1)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", evicted, []string{"a", "b", "c", "d", "h"})
+
+		e, err := scores.AddAndEvict("i", 30)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(e), 1)
+		assert(t, "", e[0].Value, "e")
+		assert(t, "", evicted, []string{"a", "b", "c", "d", "h", "e"})
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		var values []string
+		_, err := definition.List(tx).IterateValues(nil, false, func(v string) (bool, error) {
+			values = append(values, v)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []string{"f", "g", "i"})
+	})
+}
+
+func TestList_multiset(t *testing.T) {
+	for _, orderStatistics := range []bool{false, true} {
+		t.Run(fmt.Sprintf("order statistics %v", orderStatistics), func(t *testing.T) {
+			db := newDB(t)
+
+			definition := boltron.NewListDefinition(
+				"timeline",
+				boltron.StringEncoding,
+				boltron.Uint64BinaryEncoding,
+				&boltron.ListOptions{
+					Multiset:        true,
+					OrderStatistics: orderStatistics,
+				},
+			)
+
+			dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+				timeline := definition.List(tx)
+
+				for _, e := range []struct {
+					value   string
+					orderBy uint64
+				}{
+					{"b", 20},
+					{"a", 10},
+					{"b", 5},
+					{"c", 15},
+					{"b", 20},
+					{"a", 30},
+				} {
+					err := timeline.Add(e.value, e.orderBy)
+					assertErrorFail(t, "", err, nil)
+				}
+
+				count, err := timeline.Count("b")
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", count, 3)
+
+				count, err = timeline.Count("d")
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", count, 0)
+
+				var occurrences []uint64
+				err = timeline.IterateOccurrences("b", func(o uint64) (bool, error) {
+					occurrences = append(occurrences, o)
+					return true, nil
+				})
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", occurrences, []uint64{20, 5, 20})
+
+				orderBy, err := timeline.OrderBy("b")
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", orderBy, uint64(20))
+
+				page, _, _, err := timeline.Page(1, 10, false)
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", page, []boltron.ListElement[string, uint64]{
+					{Value: "b", OrderBy: 5, Sequence: 3},
+					{Value: "a", OrderBy: 10, Sequence: 2},
+					{Value: "c", OrderBy: 15, Sequence: 4},
+					{Value: "b", OrderBy: 20, Sequence: 1},
+					{Value: "b", OrderBy: 20, Sequence: 5},
+					{Value: "a", OrderBy: 30, Sequence: 6},
+				})
+
+				var elements []boltron.ListElement[string, uint64]
+				var next *boltron.ListElement[string, uint64]
+				for {
+					var n int
+					next, err = timeline.Iterate(next, false, func(v string, o uint64) (bool, error) {
+						n++
+						elements = append(elements, boltron.ListElement[string, uint64]{Value: v, OrderBy: o})
+						return n < 2, nil
+					})
+					assertErrorFail(t, "", err, nil)
+					if next == nil {
+						break
+					}
+				}
+				assert(t, "", len(elements), 6)
+				assert(t, "", elements[3], boltron.ListElement[string, uint64]{Value: "b", OrderBy: 20})
+				assert(t, "", elements[4], boltron.ListElement[string, uint64]{Value: "b", OrderBy: 20})
+
+				rank, err := timeline.Rank("b", false)
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", rank, 3)
+
+				orderBy, err = timeline.UpdateOrderBy("b", func(old uint64, exists bool) (uint64, error) {
+					assert(t, "", old, uint64(20))
+					assert(t, "", exists, true)
+					return 1, nil
+				})
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", orderBy, uint64(1))
+
+				occurrences = nil
+				err = timeline.IterateOccurrences("b", func(o uint64) (bool, error) {
+					occurrences = append(occurrences, o)
+					return true, nil
+				})
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", occurrences, []uint64{1, 5, 20})
+
+				err = timeline.RemoveOne("b", true)
+				assertErrorFail(t, "", err, nil)
+
+				count, err = timeline.Count("b")
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", count, 2)
+
+				first, err := timeline.First()
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", *first, boltron.ListElement[string, uint64]{Value: "b", OrderBy: 5, Sequence: 3})
+
+				removed, err := timeline.RemoveAll("b")
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", removed, 2)
+
+				has, err := timeline.Has("b")
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", has, false)
+
+				err = timeline.RemoveOne("b", true)
+				assertError(t, "", err, boltron.ErrNotFound)
+
+				err = timeline.Remove("a", true)
+				assertErrorFail(t, "", err, nil)
+
+				page, _, _, err = timeline.Page(1, 10, false)
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", page, []boltron.ListElement[string, uint64]{
+					{Value: "c", OrderBy: 15, Sequence: 4},
+				})
+			})
+
+			dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+				size, err := definition.List(tx).Size()
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", size, 1)
+			})
+		})
+	}
+}
+
+func TestList_tieBreak(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		options  *boltron.ListOptions
+		elements []boltron.ListElement[string, uint64]
+	}{
+		{
+			name:    "value",
+			options: nil,
+			elements: []boltron.ListElement[string, uint64]{
+				{Value: "d", OrderBy: 1},
+				{Value: "a", OrderBy: 2},
+				{Value: "b", OrderBy: 2},
+				{Value: "c", OrderBy: 2},
+			},
+		},
+		{
+			name:    "insertion",
+			options: &boltron.ListOptions{TieBreak: boltron.ListTieBreakInsertion},
+			elements: []boltron.ListElement[string, uint64]{
+				{Value: "d", OrderBy: 1, Sequence: 4},
+				{Value: "c", OrderBy: 2, Sequence: 1},
+				{Value: "b", OrderBy: 2, Sequence: 3},
+				{Value: "a", OrderBy: 2, Sequence: 5},
+			},
+		},
+		{
+			name:    "insertion reverse",
+			options: &boltron.ListOptions{TieBreak: boltron.ListTieBreakInsertionReverse},
+			elements: []boltron.ListElement[string, uint64]{
+				{Value: "d", OrderBy: 1, Sequence: 4},
+				{Value: "a", OrderBy: 2, Sequence: 5},
+				{Value: "b", OrderBy: 2, Sequence: 3},
+				{Value: "c", OrderBy: 2, Sequence: 1},
+			},
+		},
+		{
+			name:    "insertion reverse multiset",
+			options: &boltron.ListOptions{TieBreak: boltron.ListTieBreakInsertionReverse, Multiset: true},
+			elements: []boltron.ListElement[string, uint64]{
+				{Value: "d", OrderBy: 1, Sequence: 4},
+				{Value: "a", OrderBy: 2, Sequence: 5},
+				{Value: "b", OrderBy: 2, Sequence: 3},
+				{Value: "c", OrderBy: 2, Sequence: 1},
+				{Value: "a", OrderBy: 3, Sequence: 2},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newDB(t)
+
+			definition := boltron.NewListDefinition("ties", boltron.StringEncoding, boltron.Uint64BinaryEncoding, tc.options)
+
+			dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+				list := definition.List(tx)
+
+				for _, e := range []boltron.ListElement[string, uint64]{
+					{Value: "c", OrderBy: 2},
+					{Value: "a", OrderBy: 3},
+					{Value: "b", OrderBy: 2},
+					{Value: "d", OrderBy: 1},
+					{Value: "a", OrderBy: 2},
+				} {
+					err := list.Add(e.Value, e.OrderBy)
+					assertErrorFail(t, "", err, nil)
+				}
+			})
+
+			dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+				list := definition.List(tx)
+
+				page, _, _, err := list.Page(1, 10, false)
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", page, tc.elements)
+
+				page, _, _, err = list.Page(1, 10, true)
+				assertErrorFail(t, "", err, nil)
+				for i, j := 0, len(page)-1; i < j; i, j = i+1, j-1 {
+					page[i], page[j] = page[j], page[i]
+				}
+				assert(t, "", page, tc.elements)
+
+				for _, reverse := range []bool{false, true} {
+					var values []string
+					var next *boltron.ListElement[string, uint64]
+					for {
+						next, err = list.IterateValues(next, reverse, func(v string) (bool, error) {
+							values = append(values, v)
+							return false, nil
+						})
+						assertErrorFail(t, "", err, nil)
+						if next == nil {
+							break
+						}
+					}
+					want := make([]string, 0, len(tc.elements))
+					for _, e := range tc.elements {
+						want = append(want, e.Value)
+					}
+					if reverse {
+						for i, j := 0, len(want)-1; i < j; i, j = i+1, j-1 {
+							want[i], want[j] = want[j], want[i]
+						}
+					}
+					assert(t, fmt.Sprintf("reverse %v", reverse), values, want)
+				}
+
+				orderBy, err := list.OrderBy("d")
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", orderBy, uint64(1))
+
+				rank, err := list.Rank("b", false)
+				assertErrorFail(t, "", err, nil)
+				assert(t, "", rank, 2)
+			})
+		})
+	}
+}
+
 func TestList_ErrNotFound(t *testing.T) {
 	db := newDB(t)
 
@@ -668,3 +1382,54 @@ func assertListElements[V any, O time.Time](t testing.TB, got, want []boltron.Li
 		assertTime(t, fmt.Sprintf("element #%v time", i), got[i].OrderBy, want[i].OrderBy)
 	}
 }
+
+func TestList_orderStatisticsWithoutOption(t *testing.T) {
+	db := newDB(t)
+
+	newDefinition := func(orderStatistics bool) *boltron.ListDefinition[string, uint64] {
+		return boltron.NewListDefinition(
+			"leaderboard",
+			boltron.StringEncoding,
+			boltron.Uint64BinaryEncoding,
+			&boltron.ListOptions{
+				OrderStatistics:          orderStatistics,
+				OrderStatisticsBlockSize: 1,
+			},
+		)
+	}
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		l := newDefinition(true).List(tx)
+		for i, v := range []string{"alice", "bob", "carol"} {
+			err := l.Add(v, uint64(i+1))
+			assertErrorFail(t, "", err, nil)
+		}
+	})
+
+	// existing order statistics are maintained by a definition without the
+	// option
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		l := newDefinition(false).List(tx)
+		err := l.Add("dave", 0)
+		assertErrorFail(t, "", err, nil)
+		err = l.Remove("bob", true)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		l := newDefinition(true).List(tx)
+
+		for i, v := range []string{"dave", "alice", "carol"} {
+			rank, err := l.Rank(v, false)
+			assertErrorFail(t, "", err, nil)
+			assert(t, v, rank, i)
+
+			e, err := l.At(i, false)
+			assertErrorFail(t, "", err, nil)
+			assert(t, v, e.Value, v)
+		}
+
+		_, err := l.At(3, false)
+		assertError(t, "", err, boltron.ErrNotFound)
+	})
+}
@@ -19,14 +19,23 @@ type ListsDefinition[K, V, O any] struct {
 	bucketNameLists   []byte
 	bucketNameIndexes []byte
 	bucketNameValues  []byte
+	bucketNameRanks   []byte
 	keyEncoding       Encoding[K]
 	valueEncoding     Encoding[V]
 	orderByEncoding   Encoding[O]
 	fillPercent       float64
 	uniqueValues      bool
+	maxSize           int
+	evictHighest      bool
+	evictionHandler   func(key, value, orderBy []byte)
+	multiset          bool
+	tieBreak          ListTieBreak
+	orderStatistics   bool
+	rankBlockSize     int
 	errListNotFound   error
 	errValueNotFound  error
 	errValueExists    error
+	corruptedHandler  func(key []byte, err error)
 }
 
 // ListsOptions provides additional configuration for a Lists instance.
@@ -44,6 +53,39 @@ type ListsOptions struct {
 	// ErrValueExists is returned if UniqueValues option is set to true and the
 	// value already exists in another list.
 	ErrValueExists error
+	// CorruptedHandler is called with the bolt key of every element that is
+	// skipped by iteration and pagination methods of Lists and of every List
+	// because it can not be decoded with ErrCorrupted error, as ListOptions
+	// CorruptedHandler is for a single List. If it is nil, iteration is
+	// aborted. Skipped elements are still counted in total elements and pages
+	// returned by pagination methods, so a page may contain fewer elements
+	// than the limit.
+	CorruptedHandler func(key []byte, err error)
+	// OrderStatistics marks if order statistics are maintained for every list,
+	// as ListOptions OrderStatistics does for a single List.
+	OrderStatistics bool
+	// OrderStatisticsBlockSize is the number of elements in a block of order
+	// statistics of every list, as ListOptions OrderStatisticsBlockSize is for
+	// a single List.
+	OrderStatisticsBlockSize int
+	// MaxSize is the maximal number of elements in every list, as ListOptions
+	// MaxSize is for a single List. Order statistics are maintained for every
+	// list if it is set.
+	MaxSize int
+	// EvictHighest marks that elements with the highest order by values are
+	// removed from lists that grow beyond MaxSize.
+	EvictHighest bool
+	// EvictionHandler is called with the encoded list key, value and order by
+	// of every element that is removed from any list because it grew beyond
+	// MaxSize, as ListOptions EvictionHandler is for a single List, including
+	// additions by Move and set operation store methods.
+	EvictionHandler func(key, value, orderBy []byte)
+	// Multiset marks that the same value can be added to a list multiple
+	// times, as ListOptions Multiset does for a single List.
+	Multiset bool
+	// TieBreak specifies the order of elements with equal order by values in
+	// every list, as ListOptions TieBreak does for a single List.
+	TieBreak ListTieBreak
 }
 
 // NewListsDefinition constructs a new ListsDefinition with a unique name and
@@ -58,18 +100,31 @@ func NewListsDefinition[K, V, O any](
 	if o == nil {
 		o = new(ListsOptions)
 	}
+	rankBlockSize := o.OrderStatisticsBlockSize
+	if rankBlockSize <= 0 {
+		rankBlockSize = DefaultOrderStatisticsBlockSize
+	}
 	return &ListsDefinition[K, V, O]{
 		bucketNameLists:   []byte("boltron: lists: " + name + " lists"),
 		bucketNameIndexes: []byte("boltron: lists: " + name + " indexes"),
 		bucketNameValues:  []byte("boltron: lists: " + name + " values"),
+		bucketNameRanks:   []byte("boltron: lists: " + name + " ranks"),
 		keyEncoding:       keyEncoding,
 		valueEncoding:     valueEncoding,
 		orderByEncoding:   orderByEncoding,
 		fillPercent:       o.FillPercent,
 		uniqueValues:      o.UniqueValues,
+		maxSize:           o.MaxSize,
+		evictHighest:      o.EvictHighest,
+		evictionHandler:   o.EvictionHandler,
+		multiset:          o.Multiset,
+		tieBreak:          o.TieBreak,
+		orderStatistics:   o.OrderStatistics || o.MaxSize > 0,
+		rankBlockSize:     rankBlockSize,
 		errListNotFound:   withDefaultError(o.ErrListNotFound, ErrNotFound),
 		errValueNotFound:  withDefaultError(o.ErrValueNotFound, ErrNotFound),
 		errValueExists:    withDefaultError(o.ErrValueExists, ErrValueExists),
+		corruptedHandler:  o.CorruptedHandler,
 	}
 }
 
@@ -146,10 +201,18 @@ func (l *Lists[K, V, O]) List(key K) (list *List[V, O], exists bool, err error)
 		definition: &ListDefinition[V, O]{
 			bucketPath:       [][]byte{l.definition.bucketNameLists, k},
 			bucketPathIndex:  [][]byte{l.definition.bucketNameIndexes, k},
+			bucketPathRanks:  [][]byte{l.definition.bucketNameRanks, k},
 			valueEncoding:    l.definition.valueEncoding,
 			orderByEncoding:  l.definition.orderByEncoding,
 			fillPercent:      l.definition.fillPercent,
 			errValueNotFound: l.definition.errValueNotFound,
+			corruptedHandler: l.definition.corruptedHandler,
+			orderStatistics:  l.definition.orderStatistics,
+			rankBlockSize:    l.definition.rankBlockSize,
+			maxSize:          l.definition.maxSize,
+			evictHighest:     l.definition.evictHighest,
+			evictionHandler:  l.evictionHandler(k),
+			keys:             l.listKeys(),
 			addCallback: func(value, orderBy []byte) error {
 				valuesBucket, err := l.valuesBucket(true)
 				if err != nil {
@@ -198,6 +261,25 @@ func (l *Lists[K, V, O]) List(key K) (list *List[V, O], exists bool, err error)
 	}, exists, nil
 }
 
+// evictionHandler returns the eviction handler for the list with the encoded
+// key, or nil if it is not set.
+func (l *Lists[K, V, O]) evictionHandler(k []byte) func(value, orderBy []byte) {
+	if l.definition.evictionHandler == nil {
+		return nil
+	}
+	return func(value, orderBy []byte) {
+		l.definition.evictionHandler(k, value, orderBy)
+	}
+}
+
+// listKeys returns the composition of list bucket keys for every list.
+func (l *Lists[K, V, O]) listKeys() listKeys {
+	return listKeys{
+		multiset: l.definition.multiset,
+		tieBreak: l.definition.tieBreak,
+	}
+}
+
 // HasList returns true if the List associated with the key already exists in
 // the database.
 func (l *Lists[K, V, O]) HasList(key K) (bool, error) {
@@ -319,6 +401,12 @@ func (l *Lists[K, V, O]) DeleteList(key K, ensure bool) error {
 		return fmt.Errorf("delete key: %w", err)
 	}
 
+	if ranksBucket := l.tx.Bucket(l.definition.bucketNameRanks); ranksBucket != nil && ranksBucket.Bucket(k) != nil {
+		if err := ranksBucket.DeleteBucket(k); err != nil {
+			return fmt.Errorf("delete order statistics: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -365,11 +453,15 @@ func (l *Lists[K, V, O]) DeleteValue(value V, ensure bool) error {
 			valueEncoding:    l.definition.valueEncoding,
 			orderByEncoding:  l.definition.orderByEncoding,
 			errValueNotFound: l.definition.errValueNotFound,
-		}).List(nil)
+			rankBlockSize:    l.definition.rankBlockSize,
+			keys:             l.listKeys(),
+		}).List(l.tx)
 
 		if err := valueBucket.ForEach(func(k, _ []byte) error {
 			list.listBucketCache = listsBucket.Bucket(k)
 			list.indexBucketCache = indexesBucket.Bucket(k)
+			list.ranksBucketCache = nil
+			list.definition.bucketPathRanks = [][]byte{l.definition.bucketNameRanks, k}
 			return list.Remove(value, false)
 		}); err != nil {
 			return fmt.Errorf("delete value in keys bucket: %w", err)
@@ -383,6 +475,43 @@ func (l *Lists[K, V, O]) DeleteValue(value V, ensure bool) error {
 	return nil
 }
 
+// Move removes the value from the list identified by the from key and adds it
+// to the list identified by the to key with the provided order by. If the from
+// list does not exist, configured ErrListNotFound is returned, and if it does
+// not contain the value, configured ErrValueNotFound is returned. In multiset
+// lists, only the first added occurrence is moved.
+func (l *Lists[K, V, O]) Move(value V, from, to K, orderBy O) error {
+	source, _, err := l.List(from)
+	if err != nil {
+		return fmt.Errorf("source list: %w", err)
+	}
+	listBucket, err := source.listBucket(false)
+	if err != nil {
+		return fmt.Errorf("list bucket: %w", err)
+	}
+	if listBucket == nil {
+		return l.definition.errListNotFound
+	}
+
+	count, err := source.removeOccurrences(value, false)
+	if err != nil {
+		return fmt.Errorf("remove from source list: %w", err)
+	}
+	if count == 0 {
+		return l.definition.errValueNotFound
+	}
+
+	destination, _, err := l.List(to)
+	if err != nil {
+		return fmt.Errorf("destination list: %w", err)
+	}
+	if err := destination.Add(value, orderBy); err != nil {
+		return fmt.Errorf("add to destination list: %w", err)
+	}
+
+	return nil
+}
+
 // Size returns the number of lists.
 func (l *Lists[K, V, O]) Size() (int, error) {
 	listsBucket, err := l.listsBucket(false)
@@ -406,9 +535,12 @@ func (l *Lists[K, V, O]) IterateLists(start *K, reverse bool, f func(K) (bool, e
 	if listsBucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(listsBucket, l.definition.keyEncoding, start, reverse, func(k, _ []byte) (bool, error) {
+	return iterateKeys(listsBucket, l.definition.keyEncoding, l.definition.corruptedHandler, start, reverse, func(k, _ []byte) (bool, error) {
 		key, err := l.definition.keyEncoding.Decode(k)
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode key: %w", err)
 		}
 
@@ -425,8 +557,12 @@ func (l *Lists[K, V, O]) PageOfLists(number, limit int, reverse bool) (s []K, to
 	if listsBucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(listsBucket, true, number, limit, reverse, func(k, _ []byte) (K, error) {
-		return l.definition.keyEncoding.Decode(k)
+	return page(listsBucket, true, number, limit, reverse, func(k, _ []byte) (e K, err error) {
+		e, err = l.definition.keyEncoding.Decode(k)
+		if skipCorrupted(l.definition.corruptedHandler, k, err) {
+			return e, errSkipElement
+		}
+		return e, err
 	})
 }
 
@@ -450,14 +586,20 @@ func (l *Lists[K, V, O]) IterateListsWithValue(value V, start *K, reverse bool,
 	if valueBucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(valueBucket, l.definition.keyEncoding, start, reverse, func(k, o []byte) (bool, error) {
+	return iterateKeys(valueBucket, l.definition.keyEncoding, l.definition.corruptedHandler, start, reverse, func(k, o []byte) (bool, error) {
 		key, err := l.definition.keyEncoding.Decode(k)
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode value: %w", err)
 		}
 
 		orderBy, err := l.definition.orderByEncoding.Decode(o)
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode value: %w", err)
 		}
 
@@ -494,11 +636,17 @@ func (l *Lists[K, V, O]) PageOfListsWithValue(value V, number, limit int, revers
 	return page(valueBucket, false, number, limit, reverse, func(k, o []byte) (e ListsElement[K, O], err error) {
 		key, err := l.definition.keyEncoding.Decode(k)
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, k, err) {
+				return e, errSkipElement
+			}
 			return e, fmt.Errorf("decode value: %w", err)
 		}
 
 		orderBy, err := l.definition.orderByEncoding.Decode(o)
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, k, err) {
+				return e, errSkipElement
+			}
 			return e, fmt.Errorf("decode value: %w", err)
 		}
 
@@ -520,9 +668,12 @@ func (l *Lists[K, V, O]) IterateValues(start *V, reverse bool, f func(V) (bool,
 	if valuesBucket == nil {
 		return nil, nil
 	}
-	return iterateKeys(valuesBucket, l.definition.valueEncoding, start, reverse, func(v, _ []byte) (bool, error) {
+	return iterateKeys(valuesBucket, l.definition.valueEncoding, l.definition.corruptedHandler, start, reverse, func(v, _ []byte) (bool, error) {
 		value, err := l.definition.valueEncoding.Decode(v)
 		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, v, err) {
+				return true, nil
+			}
 			return false, fmt.Errorf("decode value: %w", err)
 		}
 
@@ -539,7 +690,385 @@ func (l *Lists[K, V, O]) PageOfValues(number, limit int, reverse bool) (s []V, t
 	if valuesBucket == nil {
 		return nil, 0, 0, nil
 	}
-	return page(valuesBucket, true, number, limit, reverse, func(v, _ []byte) (V, error) {
-		return l.definition.valueEncoding.Decode(v)
+	return page(valuesBucket, true, number, limit, reverse, func(v, _ []byte) (e V, err error) {
+		e, err = l.definition.valueEncoding.Decode(v)
+		if skipCorrupted(l.definition.corruptedHandler, v, err) {
+			return e, errSkipElement
+		}
+		return e, err
 	})
 }
+
+// ListsOrder specifies the order of values passed by set operations on Lists.
+type ListsOrder uint8
+
+// Lists orders.
+const (
+	// ListsOrderValue orders values by their encoded values.
+	ListsOrderValue ListsOrder = iota
+	// ListsOrderOrderBy orders values by their order by values.
+	ListsOrderOrderBy
+)
+
+type listsSetOperation uint8
+
+const (
+	listsIntersection listsSetOperation = iota
+	listsUnion
+	listsDifference
+)
+
+// Intersect iterates over values that exist in all lists identified by keys.
+// The order by of a value is the one from the first list. If the callback
+// function f returns false, the iteration stops.
+func (l *Lists[K, V, O]) Intersect(keys []K, order ListsOrder, reverse bool, f func(V, O) (bool, error)) error {
+	return l.iterateSetOperation(listsIntersection, keys, order, reverse, f)
+}
+
+// Union iterates over values that exist in any of the lists identified by
+// keys. The order by of a value is the one from the first list in keys that
+// contains it. If the callback function f returns false, the iteration stops.
+func (l *Lists[K, V, O]) Union(keys []K, order ListsOrder, reverse bool, f func(V, O) (bool, error)) error {
+	return l.iterateSetOperation(listsUnion, keys, order, reverse, f)
+}
+
+// Difference iterates over values that exist in the list identified by the
+// first key, but not in any other list identified by keys. If the callback
+// function f returns false, the iteration stops.
+func (l *Lists[K, V, O]) Difference(keys []K, order ListsOrder, reverse bool, f func(V, O) (bool, error)) error {
+	return l.iterateSetOperation(listsDifference, keys, order, reverse, f)
+}
+
+// CountIntersect returns the number of values that exist in all lists
+// identified by keys.
+func (l *Lists[K, V, O]) CountIntersect(keys []K) (int, error) {
+	return l.countSetOperation(listsIntersection, keys)
+}
+
+// CountUnion returns the number of values that exist in any of the lists
+// identified by keys.
+func (l *Lists[K, V, O]) CountUnion(keys []K) (int, error) {
+	return l.countSetOperation(listsUnion, keys)
+}
+
+// CountDifference returns the number of values that exist in the list
+// identified by the first key, but not in any other list identified by keys.
+func (l *Lists[K, V, O]) CountDifference(keys []K) (int, error) {
+	return l.countSetOperation(listsDifference, keys)
+}
+
+// IntersectStore replaces values of the destination list with the values that
+// exist in all lists identified by keys, as Intersect iterates over them, and
+// returns the size of the destination list, which can be smaller than the
+// number of values if MaxSize is set. Destination can be one of the lists
+// identified by keys.
+func (l *Lists[K, V, O]) IntersectStore(destination K, keys []K) (int, error) {
+	return l.storeSetOperation(listsIntersection, destination, keys)
+}
+
+// UnionStore replaces values of the destination list with the values that
+// exist in any of the lists identified by keys, as Union iterates over them,
+// and returns the size of the destination list, which can be smaller than the
+// number of values if MaxSize is set. Destination can be one of the lists
+// identified by keys.
+func (l *Lists[K, V, O]) UnionStore(destination K, keys []K) (int, error) {
+	return l.storeSetOperation(listsUnion, destination, keys)
+}
+
+// DifferenceStore replaces values of the destination list with the values that
+// exist in the list identified by the first key, but not in any other list
+// identified by keys, as Difference iterates over them, and returns the size
+// of the destination list, which can be smaller than the number of values if
+// MaxSize is set. Destination can be one of the lists identified by keys.
+func (l *Lists[K, V, O]) DifferenceStore(destination K, keys []K) (int, error) {
+	return l.storeSetOperation(listsDifference, destination, keys)
+}
+
+func (l *Lists[K, V, O]) iterateSetOperation(op listsSetOperation, keys []K, order ListsOrder, reverse bool, f func(V, O) (bool, error)) error {
+	return l.setOperation(op, keys, order, reverse, func(v, o []byte) (bool, error) {
+		value, err := l.definition.valueEncoding.Decode(v)
+		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, v, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode value: %w", err)
+		}
+
+		orderBy, err := l.definition.orderByEncoding.Decode(o)
+		if err != nil {
+			if skipCorrupted(l.definition.corruptedHandler, v, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode order by: %w", err)
+		}
+
+		return f(value, orderBy)
+	})
+}
+
+func (l *Lists[K, V, O]) countSetOperation(op listsSetOperation, keys []K) (count int, err error) {
+	err = l.setOperation(op, keys, ListsOrderValue, false, func(_, _ []byte) (bool, error) {
+		count++
+		return true, nil
+	})
+	return count, err
+}
+
+func (l *Lists[K, V, O]) storeSetOperation(op listsSetOperation, destination K, keys []K) (int, error) {
+	type encodedElement struct {
+		value, orderBy []byte
+	}
+	var elements []encodedElement
+	if err := l.setOperation(op, keys, ListsOrderValue, false, func(v, o []byte) (bool, error) {
+		elements = append(elements, encodedElement{
+			value:   append([]byte(nil), v...),
+			orderBy: append([]byte(nil), o...),
+		})
+		return true, nil
+	}); err != nil {
+		return 0, err
+	}
+
+	if err := l.DeleteList(destination, false); err != nil {
+		return 0, fmt.Errorf("delete destination list: %w", err)
+	}
+
+	list, _, err := l.List(destination)
+	if err != nil {
+		return 0, fmt.Errorf("destination list: %w", err)
+	}
+	for _, e := range elements {
+		if _, err := list.add(e.value, e.orderBy, false); err != nil {
+			return 0, fmt.Errorf("add to destination list: %w", err)
+		}
+	}
+
+	// bucket statistics do not include changes that are not committed, so
+	// count the elements that are left in the list after evictions
+	listBucket, err := list.listBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("destination list bucket: %w", err)
+	}
+	if listBucket == nil {
+		return 0, nil
+	}
+	return bucketKeyCount(listBucket), nil
+}
+
+// listsSetOperand holds buckets of a list that is an operand of a set
+// operation.
+type listsSetOperand[V, O any] struct {
+	list        *List[V, O]
+	listBucket  *bolt.Bucket
+	indexBucket *bolt.Bucket
+}
+
+// has returns true if the encoded value exists in the list.
+func (o listsSetOperand[V, O]) has(v []byte) bool {
+	return o.indexBucket != nil && o.list.firstKey(o.indexBucket, v) != nil
+}
+
+// orderBy returns the encoded order by of the value in the list.
+func (o listsSetOperand[V, O]) orderBy(v []byte) []byte {
+	return o.list.definition.keys.orderBy(o.list.firstKey(o.indexBucket, v), v)
+}
+
+// isFirst returns true if the list bucket key k is the key of the encoded value
+// in the list, and not of any other occurrence in multiset lists.
+func (o listsSetOperand[V, O]) isFirst(k, v []byte) bool {
+	return !o.list.definition.keys.multiset || bytes.Equal(o.list.firstKey(o.indexBucket, v), k)
+}
+
+// setOperation calls the function f with encoded values and order by values
+// that are the result of the set operation over lists identified by keys,
+// merging cursors of index buckets to order by values, or of list buckets to
+// order by order by values.
+func (l *Lists[K, V, O]) setOperation(op listsSetOperation, keys []K, order ListsOrder, reverse bool, f func(v, o []byte) (bool, error)) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	operands := make([]listsSetOperand[V, O], 0, len(keys))
+	for _, key := range keys {
+		list, _, err := l.List(key)
+		if err != nil {
+			return err
+		}
+		listBucket, err := list.listBucket(false)
+		if err != nil {
+			return fmt.Errorf("list bucket: %w", err)
+		}
+		indexBucket, err := list.indexBucket(false)
+		if err != nil {
+			return fmt.Errorf("index bucket: %w", err)
+		}
+		if listBucket == nil || indexBucket == nil {
+			listBucket, indexBucket = nil, nil
+		}
+		if op == listsIntersection && listBucket == nil {
+			return nil
+		}
+		operands = append(operands, listsSetOperand[V, O]{
+			list:        list,
+			listBucket:  listBucket,
+			indexBucket: indexBucket,
+		})
+	}
+	if operands[0].listBucket == nil && op != listsUnion {
+		return nil
+	}
+
+	if order == ListsOrderOrderBy {
+		if op == listsUnion {
+			return unionByOrderBy(operands, reverse, f)
+		}
+		// values are in the order of the first list
+		_, _, err := iterate(operands[0].listBucket, nil, reverse, func(k, v []byte) (bool, error) {
+			if !operands[0].isFirst(k, v) {
+				return true, nil
+			}
+			for _, o := range operands[1:] {
+				if o.has(v) != (op == listsIntersection) {
+					return true, nil
+				}
+			}
+			return f(v, operands[0].list.definition.keys.orderBy(k, v))
+		})
+		return err
+	}
+
+	cursors := make([]*bolt.Cursor, len(operands))
+	current := make([][]byte, len(operands))
+	for i, o := range operands {
+		if o.indexBucket == nil {
+			continue
+		}
+		cursors[i] = o.indexBucket.Cursor()
+		if reverse {
+			current[i], _ = cursors[i].Last()
+		} else {
+			current[i], _ = cursors[i].First()
+		}
+	}
+	next := func(i int) {
+		if reverse {
+			current[i], _ = cursors[i].Prev()
+		} else {
+			current[i], _ = cursors[i].Next()
+		}
+	}
+
+	for {
+		var v []byte
+		for _, c := range current {
+			if c == nil {
+				if op == listsIntersection {
+					return nil
+				}
+				continue
+			}
+			if v == nil || (bytes.Compare(c, v) < 0) != reverse && !bytes.Equal(c, v) {
+				v = c
+			}
+		}
+		if v == nil || op == listsDifference && current[0] == nil {
+			return nil
+		}
+
+		first, present := -1, 0
+		for i, c := range current {
+			if c != nil && bytes.Equal(c, v) {
+				if first < 0 {
+					first = i
+				}
+				present++
+			}
+		}
+
+		var include bool
+		switch op {
+		case listsIntersection:
+			include = present == len(current)
+		case listsUnion:
+			include = true
+		case listsDifference:
+			include = first == 0 && present == 1
+		}
+		if include {
+			cont, err := f(v, operands[first].orderBy(v))
+			if err != nil {
+				return err
+			}
+			if !cont {
+				return nil
+			}
+		}
+
+		for i, c := range current {
+			if c != nil && bytes.Equal(c, v) {
+				next(i)
+			}
+		}
+	}
+}
+
+// unionByOrderBy merges cursors of list buckets, calling the function f with
+// every value from the first list that contains it.
+func unionByOrderBy[V, O any](operands []listsSetOperand[V, O], reverse bool, f func(v, o []byte) (bool, error)) error {
+	cursors := make([]*bolt.Cursor, len(operands))
+	currentKeys := make([][]byte, len(operands))
+	currentValues := make([][]byte, len(operands))
+	next := func(i int) {
+		if reverse {
+			currentKeys[i], currentValues[i] = cursors[i].Prev()
+		} else {
+			currentKeys[i], currentValues[i] = cursors[i].Next()
+		}
+	}
+	for i, o := range operands {
+		if o.listBucket == nil {
+			continue
+		}
+		cursors[i] = o.listBucket.Cursor()
+		if reverse {
+			currentKeys[i], currentValues[i] = cursors[i].Last()
+		} else {
+			currentKeys[i], currentValues[i] = cursors[i].First()
+		}
+	}
+
+	for {
+		i := -1
+		for j, k := range currentKeys {
+			if k == nil {
+				continue
+			}
+			if i < 0 || (bytes.Compare(k, currentKeys[i]) < 0) != reverse && !bytes.Equal(k, currentKeys[i]) {
+				i = j
+			}
+		}
+		if i < 0 {
+			return nil
+		}
+
+		k, v := currentKeys[i], currentValues[i]
+		include := operands[i].isFirst(k, v)
+		for _, o := range operands[:i] {
+			if !include {
+				break
+			}
+			include = !o.has(v)
+		}
+		if include {
+			cont, err := f(v, operands[i].list.definition.keys.orderBy(k, v))
+			if err != nil {
+				return err
+			}
+			if !cont {
+				return nil
+			}
+		}
+
+		next(i)
+	}
+}
@@ -1072,6 +1072,577 @@ func TestLists_uniqueValues_customErrValueExists(t *testing.T) {
 	})
 }
 
+func TestLists_pop(t *testing.T) {
+	db := projectsDependenciesDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		projectDependencies := projectDependenciesDefinition.Lists(tx)
+
+		list, _, err := projectDependencies.List("resenje.org/boltron")
+		assertErrorFail(t, "", err, nil)
+
+		elements, err := list.PopFirst(1)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(elements), 1)
+		assert(t, "", elements[0].Value, uint64(121))
+
+		elements, err = list.PopLast(1)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(elements), 1)
+		assert(t, "", elements[0].Value, uint64(122))
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		projectDependencies := projectDependenciesDefinition.Lists(tx)
+
+		var keys []string
+		_, err := projectDependencies.IterateListsWithValue(121, nil, false, func(k string, _ time.Time) (bool, error) {
+			keys = append(keys, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", keys, []string{"resenje.org/pool", "resenje.org/schulze", "resenje.org/web"})
+
+		has, err := projectDependencies.HasValue(122)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+	})
+}
+
+func TestLists_orderByRange(t *testing.T) {
+	db := projectsDependenciesDB(t)
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		list, _, err := projectDependenciesDefinition.Lists(tx).List("resenje.org/schulze")
+		assertErrorFail(t, "", err, nil)
+
+		min := time.Unix(1640732188, 0)
+		max := time.Unix(1640732205, 0)
+
+		var values []uint64
+		err = list.IterateOrderByRange(&min, &max, false, func(v uint64, _ time.Time) (bool, error) {
+			values = append(values, v)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []uint64{121, 398, 125})
+
+		count, err := list.CountOrderByRange(nil, &min)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 2)
+	})
+}
+
+func TestLists_rankAndAt(t *testing.T) {
+	definition := boltron.NewListsDefinition(
+		"project dependencies",
+		boltron.StringEncoding,
+		boltron.Uint64Base36Encoding,
+		boltron.TimeEncoding,
+		&boltron.ListsOptions{
+			OrderStatistics: true,
+		},
+	)
+
+	db := newDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		projectDependencies := definition.Lists(tx)
+
+		for _, p := range testProjectDependencies {
+			list, _, err := projectDependencies.List(p.ProjectName)
+			assertErrorFail(t, "", err, nil)
+
+			err = list.Add(p.DependencyID, p.UpdateTime)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		err := projectDependencies.DeleteValue(398, true)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		list, _, err := definition.Lists(tx).List("resenje.org/schulze")
+		assertErrorFail(t, "", err, nil)
+
+		rank, err := list.Rank(125, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", rank, 2)
+
+		rank, err = list.Rank(501, true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", rank, 3)
+
+		e, err := list.At(0, true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", e.Value, uint64(881))
+
+		_, err = list.At(4, false)
+		assertError(t, "", err, boltron.ErrNotFound)
+	})
+}
+
+func TestLists_maxSize(t *testing.T) {
+	definition := boltron.NewListsDefinition(
+		"project dependencies",
+		boltron.StringEncoding,
+		boltron.Uint64Base36Encoding,
+		boltron.TimeEncoding,
+		&boltron.ListsOptions{
+			MaxSize: 2,
+		},
+	)
+
+	db := newDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		projectDependencies := definition.Lists(tx)
+
+		var evicted []uint64
+		for _, p := range testProjectDependencies {
+			list, _, err := projectDependencies.List(p.ProjectName)
+			assertErrorFail(t, "", err, nil)
+
+			e, err := list.AddAndEvict(p.DependencyID, p.UpdateTime)
+			assertErrorFail(t, "", err, nil)
+			for _, e := range e {
+				evicted = append(evicted, e.Value)
+			}
+		}
+		assert(t, "", evicted, []uint64{121, 501, 121, 398, 398, 121, 121})
+
+		for _, v := range []uint64{121, 398, 501} {
+			has, err := projectDependencies.HasValue(v)
+			assertErrorFail(t, "", err, nil)
+			assert(t, fmt.Sprint(v), has, false)
+		}
+
+		list, _, err := projectDependencies.List("resenje.org/schulze")
+		assertErrorFail(t, "", err, nil)
+		var values []uint64
+		_, err = list.IterateValues(nil, false, func(v uint64) (bool, error) {
+			values = append(values, v)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []uint64{125, 881})
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		projectDependencies := definition.Lists(tx)
+
+		var lists []string
+		_, err := projectDependencies.IterateListsWithValue(125, nil, false, func(k string, _ time.Time) (bool, error) {
+			lists = append(lists, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", lists, []string{"resenje.org/pool", "resenje.org/schulze", "resenje.org/web"})
+	})
+}
+
+func TestLists_evictionHandler(t *testing.T) {
+	db := newDB(t)
+
+	type eviction struct {
+		list, value string
+	}
+	var evicted []eviction
+	definition := boltron.NewListsDefinition(
+		"queues",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		boltron.Uint64BinaryEncoding,
+		&boltron.ListsOptions{
+			MaxSize: 2,
+			EvictionHandler: func(key, value, orderBy []byte) {
+				evicted = append(evicted, eviction{list: string(key), value: string(value)})
+			},
+		},
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		queues := definition.Lists(tx)
+
+		for i, k := range []string{"x", "x", "y", "y"} {
+			list, _, err := queues.List(k)
+			assertErrorFail(t, "", err, nil)
+			err = list.Add(fmt.Sprint(k, i), uint64(i))
+			assertErrorFail(t, "", err, nil)
+		}
+		assert(t, "", len(evicted), 0)
+
+		err := queues.Move("x1", "x", "y", 10)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", evicted, []eviction{{list: "y", value: "y2"}})
+
+		has, err := queues.HasValue("y2")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		evicted = nil
+		count, err := queues.UnionStore("z", []string{"x", "y"})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 2)
+		assert(t, "", len(evicted), 1)
+
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		list, _, err := definition.Lists(tx).List("z")
+		assertErrorFail(t, "", err, nil)
+		size, err := list.Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 2)
+	})
+}
+
+func TestLists_multiset(t *testing.T) {
+	definition := boltron.NewListsDefinition(
+		"timelines",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		boltron.Uint64BinaryEncoding,
+		&boltron.ListsOptions{
+			Multiset: true,
+		},
+	)
+
+	db := newDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		timelines := definition.Lists(tx)
+
+		list, _, err := timelines.List("first")
+		assertErrorFail(t, "", err, nil)
+		for i, v := range []string{"a", "b", "a", "a"} {
+			err := list.Add(v, uint64(i))
+			assertErrorFail(t, "", err, nil)
+		}
+
+		list, _, err = timelines.List("second")
+		assertErrorFail(t, "", err, nil)
+		err = list.Add("a", 10)
+		assertErrorFail(t, "", err, nil)
+
+		listsWithValue := func(value string) (s []boltron.ListsElement[string, uint64]) {
+			t.Helper()
+
+			s, _, _, err := timelines.PageOfListsWithValue(value, 1, 10, false)
+			assertErrorFail(t, "", err, nil)
+			return s
+		}
+
+		assert(t, "", listsWithValue("a"), []boltron.ListsElement[string, uint64]{
+			{Key: "first", OrderBy: 3},
+			{Key: "second", OrderBy: 10},
+		})
+
+		list, _, err = timelines.List("first")
+		assertErrorFail(t, "", err, nil)
+
+		err = list.RemoveOne("a", true)
+		assertErrorFail(t, "", err, nil)
+
+		assert(t, "", listsWithValue("a"), []boltron.ListsElement[string, uint64]{
+			{Key: "first", OrderBy: 3},
+			{Key: "second", OrderBy: 10},
+		})
+
+		removed, err := list.RemoveAll("a")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", removed, 2)
+
+		assert(t, "", listsWithValue("a"), []boltron.ListsElement[string, uint64]{
+			{Key: "second", OrderBy: 10},
+		})
+
+		err = list.Add("b", 5)
+		assertErrorFail(t, "", err, nil)
+
+		err = timelines.DeleteValue("b", true)
+		assertErrorFail(t, "", err, nil)
+
+		count, err := list.Count("b")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 0)
+
+		has, err := timelines.HasValue("b")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+	})
+}
+
+func TestLists_setOperations(t *testing.T) {
+	db := projectsDependenciesDB(t)
+
+	type element = boltron.ListElement[uint64, time.Time]
+
+	collect := func(t testing.TB, f func(func(uint64, time.Time) (bool, error)) error) (s []element) {
+		t.Helper()
+
+		err := f(func(v uint64, o time.Time) (bool, error) {
+			s = append(s, element{Value: v, OrderBy: o})
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		return s
+	}
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		projectDependencies := projectDependenciesDefinition.Lists(tx)
+
+		for _, tc := range []struct {
+			name    string
+			f       func(keys []string, order boltron.ListsOrder, reverse bool, f func(uint64, time.Time) (bool, error)) error
+			keys    []string
+			order   boltron.ListsOrder
+			reverse bool
+			want    []element
+		}{
+			{
+				name:  "intersect by value",
+				f:     projectDependencies.Intersect,
+				keys:  []string{"resenje.org/web", "resenje.org/schulze"},
+				order: boltron.ListsOrderValue,
+				want: []element{
+					{Value: 121, OrderBy: time.Unix(1640732362, 0)},
+					{Value: 125, OrderBy: time.Unix(1640732381, 0)},
+					{Value: 398, OrderBy: time.Unix(1640732358, 0)},
+					{Value: 881, OrderBy: time.Unix(1640732390, 0)},
+				},
+			},
+			{
+				name:  "intersect by order by",
+				f:     projectDependencies.Intersect,
+				keys:  []string{"resenje.org/web", "resenje.org/schulze"},
+				order: boltron.ListsOrderOrderBy,
+				want: []element{
+					{Value: 398, OrderBy: time.Unix(1640732358, 0)},
+					{Value: 121, OrderBy: time.Unix(1640732362, 0)},
+					{Value: 125, OrderBy: time.Unix(1640732381, 0)},
+					{Value: 881, OrderBy: time.Unix(1640732390, 0)},
+				},
+			},
+			{
+				name:  "intersect with missing list",
+				f:     projectDependencies.Intersect,
+				keys:  []string{"resenje.org/web", "resenje.org/missing"},
+				order: boltron.ListsOrderValue,
+				want:  nil,
+			},
+			{
+				name:    "union by value reverse",
+				f:       projectDependencies.Union,
+				keys:    []string{"resenje.org/boltron", "resenje.org/missing", "resenje.org/pool"},
+				order:   boltron.ListsOrderValue,
+				reverse: true,
+				want: []element{
+					{Value: 881, OrderBy: time.Unix(1640732508, 0)},
+					{Value: 382, OrderBy: time.Unix(1640731016, 0)},
+					{Value: 125, OrderBy: time.Unix(1640732500, 0)},
+					{Value: 122, OrderBy: time.Unix(1640731310, 0)},
+					{Value: 121, OrderBy: time.Unix(1640730983, 0)},
+				},
+			},
+			{
+				name:  "union by order by",
+				f:     projectDependencies.Union,
+				keys:  []string{"resenje.org/boltron", "resenje.org/pool"},
+				order: boltron.ListsOrderOrderBy,
+				want: []element{
+					{Value: 121, OrderBy: time.Unix(1640730983, 0)},
+					{Value: 382, OrderBy: time.Unix(1640731016, 0)},
+					{Value: 122, OrderBy: time.Unix(1640731310, 0)},
+					{Value: 125, OrderBy: time.Unix(1640732500, 0)},
+					{Value: 881, OrderBy: time.Unix(1640732508, 0)},
+				},
+			},
+			{
+				name:    "union by order by reverse",
+				f:       projectDependencies.Union,
+				keys:    []string{"resenje.org/pool", "resenje.org/boltron"},
+				order:   boltron.ListsOrderOrderBy,
+				reverse: true,
+				want: []element{
+					{Value: 881, OrderBy: time.Unix(1640732508, 0)},
+					{Value: 125, OrderBy: time.Unix(1640732500, 0)},
+					{Value: 121, OrderBy: time.Unix(1640732487, 0)},
+					{Value: 122, OrderBy: time.Unix(1640731310, 0)},
+					{Value: 382, OrderBy: time.Unix(1640731016, 0)},
+				},
+			},
+			{
+				name:  "difference by value",
+				f:     projectDependencies.Difference,
+				keys:  []string{"resenje.org/schulze", "resenje.org/boltron"},
+				order: boltron.ListsOrderValue,
+				want: []element{
+					{Value: 125, OrderBy: time.Unix(1640732205, 0)},
+					{Value: 398, OrderBy: time.Unix(1640732192, 0)},
+					{Value: 501, OrderBy: time.Unix(1640732181, 0)},
+					{Value: 881, OrderBy: time.Unix(1640732216, 0)},
+				},
+			},
+			{
+				name:    "difference by order by reverse",
+				f:       projectDependencies.Difference,
+				keys:    []string{"resenje.org/schulze", "resenje.org/web", "resenje.org/missing"},
+				order:   boltron.ListsOrderOrderBy,
+				reverse: true,
+				want: []element{
+					{Value: 501, OrderBy: time.Unix(1640732181, 0)},
+				},
+			},
+		} {
+			got := collect(t, func(f func(uint64, time.Time) (bool, error)) error {
+				return tc.f(tc.keys, tc.order, tc.reverse, f)
+			})
+			assert(t, tc.name, len(got), len(tc.want))
+			for i := range got {
+				assert(t, tc.name, got[i].Value, tc.want[i].Value)
+				assertTime(t, tc.name, got[i].OrderBy, tc.want[i].OrderBy)
+			}
+		}
+
+		count, err := projectDependencies.CountUnion(testProjectDependenciesLists)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, len(testProjectDependenciesValues))
+
+		count, err = projectDependencies.CountIntersect([]string{"resenje.org/schulze", "resenje.org/web", "resenje.org/pool"})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 3)
+
+		count, err = projectDependencies.CountDifference([]string{"resenje.org/boltron", "resenje.org/pool"})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 2)
+
+		var values []uint64
+		err = projectDependencies.Union(testProjectDependenciesLists, boltron.ListsOrderValue, false, func(v uint64, _ time.Time) (bool, error) {
+			values = append(values, v)
+			return len(values) < 2, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []uint64{121, 122})
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		projectDependencies := projectDependenciesDefinition.Lists(tx)
+
+		count, err := projectDependencies.UnionStore("union", []string{"resenje.org/boltron", "resenje.org/pool"})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 5)
+
+		count, err = projectDependencies.IntersectStore("resenje.org/web", []string{"resenje.org/web", "resenje.org/pool"})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 3)
+
+		count, err = projectDependencies.DifferenceStore("difference", []string{"resenje.org/schulze", "resenje.org/missing"})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 5)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		projectDependencies := projectDependenciesDefinition.Lists(tx)
+
+		for key, want := range map[string][]uint64{
+			"union":            {121, 382, 122, 125, 881},
+			"resenje.org/web":  {121, 125, 881},
+			"difference":       {501, 121, 398, 125, 881},
+			"resenje.org/pool": {121, 125, 881},
+		} {
+			list, _, err := projectDependencies.List(key)
+			assertErrorFail(t, "", err, nil)
+			var values []uint64
+			_, err = list.IterateValues(nil, false, func(v uint64) (bool, error) {
+				values = append(values, v)
+				return true, nil
+			})
+			assertErrorFail(t, "", err, nil)
+			assert(t, key, values, want)
+		}
+
+		var lists []string
+		_, err := projectDependencies.IterateListsWithValue(398, nil, false, func(k string, _ time.Time) (bool, error) {
+			lists = append(lists, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", lists, []string{"difference", "resenje.org/schulze"})
+	})
+}
+
+func TestLists_move(t *testing.T) {
+	definition := boltron.NewListsDefinition(
+		"board",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		boltron.Uint64BinaryEncoding,
+		&boltron.ListsOptions{
+			UniqueValues: true,
+		},
+	)
+
+	db := newDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		board := definition.Lists(tx)
+
+		todo, _, err := board.List("todo")
+		assertErrorFail(t, "", err, nil)
+		for i, v := range []string{"design", "implement", "test"} {
+			err := todo.Add(v, uint64(i))
+			assertErrorFail(t, "", err, nil)
+		}
+
+		err = board.Move("design", "todo", "doing", 0)
+		assertErrorFail(t, "", err, nil)
+
+		err = board.Move("design", "doing", "done", 5)
+		assertErrorFail(t, "", err, nil)
+
+		err = board.Move("implement", "todo", "todo", 10)
+		assertErrorFail(t, "", err, nil)
+
+		err = board.Move("design", "todo", "done", 0)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		err = board.Move("design", "missing", "done", 0)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		values, _, _, err := todo.Page(1, 10, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", values, []boltron.ListElement[string, uint64]{
+			{Value: "test", OrderBy: 2},
+			{Value: "implement", OrderBy: 10},
+		})
+
+		var lists []boltron.ListsElement[string, uint64]
+		_, err = board.IterateListsWithValue("design", nil, false, func(k string, o uint64) (bool, error) {
+			lists = append(lists, boltron.ListsElement[string, uint64]{Key: k, OrderBy: o})
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", lists, []boltron.ListsElement[string, uint64]{
+			{Key: "done", OrderBy: 5},
+		})
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		board := definition.Lists(tx)
+
+		has, err := board.HasList("doing")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		done, _, err := board.List("done")
+		assertErrorFail(t, "", err, nil)
+		orderBy, err := done.OrderBy("design")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", orderBy, uint64(5))
+	})
+}
+
 func projectsDependenciesDB(t testing.TB) *bolt.DB {
 	t.Helper()
 
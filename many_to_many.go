@@ -0,0 +1,617 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ManyToManyDefinition defines many-to-many relation between values named left
+// and right, where every link between them holds an edge value.
+type ManyToManyDefinition[L, R, E any] struct {
+	bucketNameLeft   []byte
+	bucketNameRight  []byte
+	leftEncoding     Encoding[L]
+	rightEncoding    Encoding[R]
+	edgeEncoding     Encoding[E]
+	fillPercent      float64
+	errNotFound      error
+	corruptedHandler func(key []byte, err error)
+}
+
+// ManyToManyOptions provides additional configuration for a ManyToMany.
+type ManyToManyOptions struct {
+	// FillPercent is the value for the bolt bucket fill percent.
+	FillPercent float64
+	// ErrNotFound is returned if the link is not found.
+	ErrNotFound error
+	// CorruptedHandler is called with the encoded left or right value of every
+	// link that is skipped by iteration and pagination methods because it can
+	// not be decoded with ErrCorrupted error. If it is nil, iteration is
+	// aborted. Skipped links are still counted in total elements and pages
+	// returned by pagination methods, so a page may contain fewer elements
+	// than the limit.
+	CorruptedHandler func(key []byte, err error)
+}
+
+// NewManyToManyDefinition constructs a new ManyToManyDefinition with a unique
+// name and left, right and edge values encodings.
+func NewManyToManyDefinition[L, R, E any](
+	name string,
+	leftEncoding Encoding[L],
+	rightEncoding Encoding[R],
+	edgeEncoding Encoding[E],
+	o *ManyToManyOptions,
+) *ManyToManyDefinition[L, R, E] {
+	if o == nil {
+		o = new(ManyToManyOptions)
+	}
+	return &ManyToManyDefinition[L, R, E]{
+		bucketNameLeft:   []byte("boltron: many to many: " + name + " left"),
+		bucketNameRight:  []byte("boltron: many to many: " + name + " right"),
+		leftEncoding:     leftEncoding,
+		rightEncoding:    rightEncoding,
+		edgeEncoding:     edgeEncoding,
+		fillPercent:      o.FillPercent,
+		errNotFound:      withDefaultError(o.ErrNotFound, ErrNotFound),
+		corruptedHandler: o.CorruptedHandler,
+	}
+}
+
+// ManyToMany returns a ManyToMany that has access to the stored data through
+// the bolt transaction.
+func (d *ManyToManyDefinition[L, R, E]) ManyToMany(tx *bolt.Tx) *ManyToMany[L, R, E] {
+	return &ManyToMany[L, R, E]{
+		tx:         tx,
+		definition: d,
+	}
+}
+
+// ManyToMany provides methods to access and change many-to-many links. Links
+// are stored in both forward and reverse index, each holding the edge value.
+type ManyToMany[L, R, E any] struct {
+	tx               *bolt.Tx
+	leftBucketCache  *bolt.Bucket
+	rightBucketCache *bolt.Bucket
+	definition       *ManyToManyDefinition[L, R, E]
+}
+
+func (m *ManyToMany[L, R, E]) leftBucket(create bool) (*bolt.Bucket, error) {
+	if m.leftBucketCache != nil {
+		return m.leftBucketCache, nil
+	}
+	bucket, err := rootBucket(m.tx, create, m.definition.bucketNameLeft)
+	if err != nil {
+		return nil, err
+	}
+	m.leftBucketCache = bucket
+	return bucket, nil
+}
+
+func (m *ManyToMany[L, R, E]) rightBucket(create bool) (*bolt.Bucket, error) {
+	if m.rightBucketCache != nil {
+		return m.rightBucketCache, nil
+	}
+	bucket, err := rootBucket(m.tx, create, m.definition.bucketNameRight)
+	if err != nil {
+		return nil, err
+	}
+	m.rightBucketCache = bucket
+	return bucket, nil
+}
+
+// sideBucket returns a nested bucket with links of the encoded value k in the
+// left or right index bucket.
+func (m *ManyToMany[L, R, E]) sideBucket(left bool, k []byte, create bool) (*bolt.Bucket, error) {
+	var bucket *bolt.Bucket
+	var err error
+	if left {
+		bucket, err = m.leftBucket(create)
+		if err != nil {
+			return nil, fmt.Errorf("left bucket: %w", err)
+		}
+	} else {
+		bucket, err = m.rightBucket(create)
+		if err != nil {
+			return nil, fmt.Errorf("right bucket: %w", err)
+		}
+	}
+	if bucket == nil {
+		return nil, nil
+	}
+	b, err := nestedBucket(bucket, create, k)
+	if err != nil {
+		return nil, fmt.Errorf("links bucket: %w", err)
+	}
+	if m.definition.fillPercent > 0 && b != nil {
+		b.FillPercent = m.definition.fillPercent
+	}
+	return b, nil
+}
+
+// Link saves the link between left and right values with the edge value. If
+// the link already exists, its edge value is replaced.
+func (m *ManyToMany[L, R, E]) Link(left L, right R, edge E) error {
+	l, err := m.definition.leftEncoding.Encode(left)
+	if err != nil {
+		return fmt.Errorf("encode left value: %w", err)
+	}
+	r, err := m.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return fmt.Errorf("encode right value: %w", err)
+	}
+	e, err := m.definition.edgeEncoding.Encode(edge)
+	if err != nil {
+		return fmt.Errorf("encode edge value: %w", err)
+	}
+	leftLinks, err := m.sideBucket(true, l, true)
+	if err != nil {
+		return err
+	}
+	if err := leftLinks.Put(r, e); err != nil {
+		return fmt.Errorf("put right value: %w", err)
+	}
+	rightLinks, err := m.sideBucket(false, r, true)
+	if err != nil {
+		return err
+	}
+	if err := rightLinks.Put(l, e); err != nil {
+		return fmt.Errorf("put left value: %w", err)
+	}
+	return nil
+}
+
+// HasLink returns true if the link between left and right values exists.
+func (m *ManyToMany[L, R, E]) HasLink(left L, right R) (bool, error) {
+	l, err := m.definition.leftEncoding.Encode(left)
+	if err != nil {
+		return false, fmt.Errorf("encode left value: %w", err)
+	}
+	r, err := m.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return false, fmt.Errorf("encode right value: %w", err)
+	}
+	leftLinks, err := m.sideBucket(true, l, false)
+	if err != nil {
+		return false, err
+	}
+	if leftLinks == nil {
+		return false, nil
+	}
+	_, exists := bucketValue(leftLinks, r)
+	return exists, nil
+}
+
+// Edge returns the edge value of the link between left and right values. If
+// the link does not exist, configured ErrNotFound is returned.
+func (m *ManyToMany[L, R, E]) Edge(left L, right R) (edge E, err error) {
+	l, err := m.definition.leftEncoding.Encode(left)
+	if err != nil {
+		return edge, fmt.Errorf("encode left value: %w", err)
+	}
+	r, err := m.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return edge, fmt.Errorf("encode right value: %w", err)
+	}
+	leftLinks, err := m.sideBucket(true, l, false)
+	if err != nil {
+		return edge, err
+	}
+	if leftLinks == nil {
+		return edge, m.definition.errNotFound
+	}
+	e, exists := bucketValue(leftLinks, r)
+	if !exists {
+		return edge, m.definition.errNotFound
+	}
+	edge, err = m.definition.edgeEncoding.Decode(e)
+	if err != nil {
+		return edge, fmt.Errorf("decode edge value: %w", err)
+	}
+	return edge, nil
+}
+
+// Unlink removes the link between left and right values. If ensure flag is set
+// to true and the link does not exist, configured ErrNotFound is returned.
+func (m *ManyToMany[L, R, E]) Unlink(left L, right R, ensure bool) error {
+	l, err := m.definition.leftEncoding.Encode(left)
+	if err != nil {
+		return fmt.Errorf("encode left value: %w", err)
+	}
+	r, err := m.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return fmt.Errorf("encode right value: %w", err)
+	}
+	leftLinks, err := m.sideBucket(true, l, false)
+	if err != nil {
+		return err
+	}
+	if leftLinks == nil {
+		if ensure {
+			return m.definition.errNotFound
+		}
+		return nil
+	}
+	if _, exists := bucketValue(leftLinks, r); !exists {
+		if ensure {
+			return m.definition.errNotFound
+		}
+		return nil
+	}
+	if err := m.unlink(true, l, r); err != nil {
+		return err
+	}
+	return m.unlink(false, r, l)
+}
+
+// unlink removes the value v from the links bucket of the value k in the left
+// or right index bucket and removes the links bucket if it is empty.
+func (m *ManyToMany[L, R, E]) unlink(left bool, k, v []byte) error {
+	var bucket *bolt.Bucket
+	var err error
+	if left {
+		bucket, err = m.leftBucket(false)
+	} else {
+		bucket, err = m.rightBucket(false)
+	}
+	if err != nil {
+		return fmt.Errorf("index bucket: %w", err)
+	}
+	if bucket == nil {
+		return errors.New("index bucket does not exist")
+	}
+	links := bucket.Bucket(k)
+	if links == nil {
+		return errors.New("links bucket does not exist")
+	}
+	if err := links.Delete(v); err != nil {
+		return fmt.Errorf("delete link: %w", err)
+	}
+	if f, _ := links.Cursor().First(); f == nil {
+		if err := bucket.DeleteBucket(k); err != nil {
+			return fmt.Errorf("delete empty links bucket: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteLeft removes all links of the left value. If ensure flag is set to
+// true and the left value has no links, configured ErrNotFound is returned.
+func (m *ManyToMany[L, R, E]) DeleteLeft(left L, ensure bool) error {
+	l, err := m.definition.leftEncoding.Encode(left)
+	if err != nil {
+		return fmt.Errorf("encode left value: %w", err)
+	}
+	return m.deleteSide(true, l, ensure)
+}
+
+// DeleteRight removes all links of the right value. If ensure flag is set to
+// true and the right value has no links, configured ErrNotFound is returned.
+func (m *ManyToMany[L, R, E]) DeleteRight(right R, ensure bool) error {
+	r, err := m.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return fmt.Errorf("encode right value: %w", err)
+	}
+	return m.deleteSide(false, r, ensure)
+}
+
+func (m *ManyToMany[L, R, E]) deleteSide(left bool, k []byte, ensure bool) error {
+	links, err := m.sideBucket(left, k, false)
+	if err != nil {
+		return err
+	}
+	if links == nil {
+		if ensure {
+			return m.definition.errNotFound
+		}
+		return nil
+	}
+	var others [][]byte
+	if err := links.ForEach(func(o, _ []byte) error {
+		others = append(others, append([]byte(nil), o...))
+		return nil
+	}); err != nil {
+		return fmt.Errorf("iterate links: %w", err)
+	}
+	for _, o := range others {
+		if err := m.unlink(!left, o, k); err != nil {
+			return err
+		}
+	}
+	var bucket *bolt.Bucket
+	if left {
+		bucket, err = m.leftBucket(false)
+	} else {
+		bucket, err = m.rightBucket(false)
+	}
+	if err != nil {
+		return fmt.Errorf("index bucket: %w", err)
+	}
+	if err := bucket.DeleteBucket(k); err != nil {
+		return fmt.Errorf("delete links bucket: %w", err)
+	}
+	return nil
+}
+
+// CountRights returns the number of right values linked to the left value.
+func (m *ManyToMany[L, R, E]) CountRights(left L) (int, error) {
+	l, err := m.definition.leftEncoding.Encode(left)
+	if err != nil {
+		return 0, fmt.Errorf("encode left value: %w", err)
+	}
+	return m.count(true, l)
+}
+
+// CountLefts returns the number of left values linked to the right value.
+func (m *ManyToMany[L, R, E]) CountLefts(right R) (int, error) {
+	r, err := m.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return 0, fmt.Errorf("encode right value: %w", err)
+	}
+	return m.count(false, r)
+}
+
+func (m *ManyToMany[L, R, E]) count(left bool, k []byte) (int, error) {
+	links, err := m.sideBucket(left, k, false)
+	if err != nil {
+		return 0, err
+	}
+	if links == nil {
+		return 0, nil
+	}
+	return size(links, false), nil
+}
+
+// IterateRights iterates over right values linked to the left value and their
+// edge values in the lexicographical order of right values. If the callback
+// function f returns false, the iteration stops and the next can be used to
+// continue the iteration.
+func (m *ManyToMany[L, R, E]) IterateRights(left L, start *R, reverse bool, f func(R, E) (bool, error)) (next *R, err error) {
+	l, err := m.definition.leftEncoding.Encode(left)
+	if err != nil {
+		return nil, fmt.Errorf("encode left value: %w", err)
+	}
+	links, err := m.sideBucket(true, l, false)
+	if err != nil {
+		return nil, err
+	}
+	if links == nil {
+		return nil, nil
+	}
+	return iterateKeys(links, m.definition.rightEncoding, m.definition.corruptedHandler, start, reverse, func(r, e []byte) (bool, error) {
+		right, err := m.definition.rightEncoding.Decode(r)
+		if err != nil {
+			if skipCorrupted(m.definition.corruptedHandler, r, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode right value: %w", err)
+		}
+
+		edge, err := m.definition.edgeEncoding.Decode(e)
+		if err != nil {
+			if skipCorrupted(m.definition.corruptedHandler, r, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode edge value: %w", err)
+		}
+
+		return f(right, edge)
+	})
+}
+
+// IterateLefts iterates over left values linked to the right value and their
+// edge values in the lexicographical order of left values. If the callback
+// function f returns false, the iteration stops and the next can be used to
+// continue the iteration.
+func (m *ManyToMany[L, R, E]) IterateLefts(right R, start *L, reverse bool, f func(L, E) (bool, error)) (next *L, err error) {
+	r, err := m.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return nil, fmt.Errorf("encode right value: %w", err)
+	}
+	links, err := m.sideBucket(false, r, false)
+	if err != nil {
+		return nil, err
+	}
+	if links == nil {
+		return nil, nil
+	}
+	return iterateKeys(links, m.definition.leftEncoding, m.definition.corruptedHandler, start, reverse, func(l, e []byte) (bool, error) {
+		left, err := m.definition.leftEncoding.Decode(l)
+		if err != nil {
+			if skipCorrupted(m.definition.corruptedHandler, l, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode left value: %w", err)
+		}
+
+		edge, err := m.definition.edgeEncoding.Decode(e)
+		if err != nil {
+			if skipCorrupted(m.definition.corruptedHandler, l, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode edge value: %w", err)
+		}
+
+		return f(left, edge)
+	})
+}
+
+// IterateLeftValues iterates over all left values that have links in the
+// lexicographical order of left values. If the callback function f returns
+// false, the iteration stops and the next can be used to continue the
+// iteration.
+func (m *ManyToMany[L, R, E]) IterateLeftValues(start *L, reverse bool, f func(L) (bool, error)) (next *L, err error) {
+	bucket, err := m.leftBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("left bucket: %w", err)
+	}
+	if bucket == nil {
+		return nil, nil
+	}
+	return iterateKeys(bucket, m.definition.leftEncoding, m.definition.corruptedHandler, start, reverse, func(l, _ []byte) (bool, error) {
+		left, err := m.definition.leftEncoding.Decode(l)
+		if err != nil {
+			if skipCorrupted(m.definition.corruptedHandler, l, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode left value: %w", err)
+		}
+
+		return f(left)
+	})
+}
+
+// IterateRightValues iterates over all right values that have links in the
+// lexicographical order of right values. If the callback function f returns
+// false, the iteration stops and the next can be used to continue the
+// iteration.
+func (m *ManyToMany[L, R, E]) IterateRightValues(start *R, reverse bool, f func(R) (bool, error)) (next *R, err error) {
+	bucket, err := m.rightBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("right bucket: %w", err)
+	}
+	if bucket == nil {
+		return nil, nil
+	}
+	return iterateKeys(bucket, m.definition.rightEncoding, m.definition.corruptedHandler, start, reverse, func(r, _ []byte) (bool, error) {
+		right, err := m.definition.rightEncoding.Decode(r)
+		if err != nil {
+			if skipCorrupted(m.definition.corruptedHandler, r, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode right value: %w", err)
+		}
+
+		return f(right)
+	})
+}
+
+// ManyToManyElement is the type returned by ManyToMany pagination methods as
+// slice elements that contain linked values and the edge value.
+type ManyToManyElement[L, R, E any] struct {
+	Left  L
+	Right R
+	Edge  E
+}
+
+// PageOfRights returns at most a limit of links of the left value at the
+// provided page number, ordered by right values.
+func (m *ManyToMany[L, R, E]) PageOfRights(left L, number, limit int, reverse bool) (s []ManyToManyElement[L, R, E], totalElements, pages int, err error) {
+	l, err := m.definition.leftEncoding.Encode(left)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("encode left value: %w", err)
+	}
+	links, err := m.sideBucket(true, l, false)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if links == nil {
+		return nil, 0, 0, nil
+	}
+	return page(links, false, number, limit, reverse, func(r, e []byte) (el ManyToManyElement[L, R, E], err error) {
+		right, err := m.definition.rightEncoding.Decode(r)
+		if err != nil {
+			if skipCorrupted(m.definition.corruptedHandler, r, err) {
+				return el, errSkipElement
+			}
+			return el, fmt.Errorf("decode right value: %w", err)
+		}
+
+		edge, err := m.definition.edgeEncoding.Decode(e)
+		if err != nil {
+			if skipCorrupted(m.definition.corruptedHandler, r, err) {
+				return el, errSkipElement
+			}
+			return el, fmt.Errorf("decode edge value: %w", err)
+		}
+
+		return ManyToManyElement[L, R, E]{
+			Left:  left,
+			Right: right,
+			Edge:  edge,
+		}, nil
+	})
+}
+
+// PageOfLefts returns at most a limit of links of the right value at the
+// provided page number, ordered by left values.
+func (m *ManyToMany[L, R, E]) PageOfLefts(right R, number, limit int, reverse bool) (s []ManyToManyElement[L, R, E], totalElements, pages int, err error) {
+	r, err := m.definition.rightEncoding.Encode(right)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("encode right value: %w", err)
+	}
+	links, err := m.sideBucket(false, r, false)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if links == nil {
+		return nil, 0, 0, nil
+	}
+	return page(links, false, number, limit, reverse, func(l, e []byte) (el ManyToManyElement[L, R, E], err error) {
+		left, err := m.definition.leftEncoding.Decode(l)
+		if err != nil {
+			if skipCorrupted(m.definition.corruptedHandler, l, err) {
+				return el, errSkipElement
+			}
+			return el, fmt.Errorf("decode left value: %w", err)
+		}
+
+		edge, err := m.definition.edgeEncoding.Decode(e)
+		if err != nil {
+			if skipCorrupted(m.definition.corruptedHandler, l, err) {
+				return el, errSkipElement
+			}
+			return el, fmt.Errorf("decode edge value: %w", err)
+		}
+
+		return ManyToManyElement[L, R, E]{
+			Left:  left,
+			Right: right,
+			Edge:  edge,
+		}, nil
+	})
+}
+
+// PageOfLeftValues returns at most a limit of left values that have links at
+// the provided page number.
+func (m *ManyToMany[L, R, E]) PageOfLeftValues(number, limit int, reverse bool) (s []L, totalElements, pages int, err error) {
+	bucket, err := m.leftBucket(false)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("left bucket: %w", err)
+	}
+	if bucket == nil {
+		return nil, 0, 0, nil
+	}
+	return page(bucket, true, number, limit, reverse, func(l, _ []byte) (left L, err error) {
+		left, err = m.definition.leftEncoding.Decode(l)
+		if skipCorrupted(m.definition.corruptedHandler, l, err) {
+			return left, errSkipElement
+		}
+		return left, err
+	})
+}
+
+// PageOfRightValues returns at most a limit of right values that have links at
+// the provided page number.
+func (m *ManyToMany[L, R, E]) PageOfRightValues(number, limit int, reverse bool) (s []R, totalElements, pages int, err error) {
+	bucket, err := m.rightBucket(false)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("right bucket: %w", err)
+	}
+	if bucket == nil {
+		return nil, 0, 0, nil
+	}
+	return page(bucket, true, number, limit, reverse, func(r, _ []byte) (right R, err error) {
+		right, err = m.definition.rightEncoding.Decode(r)
+		if skipCorrupted(m.definition.corruptedHandler, r, err) {
+			return right, errSkipElement
+		}
+		return right, err
+	})
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+var membershipsDefinition = boltron.NewManyToManyDefinition(
+	"memberships",
+	boltron.StringEncoding,
+	boltron.StringEncoding,
+	boltron.StringEncoding,
+	nil,
+)
+
+func TestManyToMany(t *testing.T) {
+	db := newDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		memberships := membershipsDefinition.ManyToMany(tx)
+
+		_, err := memberships.Edge("alice", "admins")
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		for _, e := range []boltron.ManyToManyElement[string, string, string]{
+			{Left: "alice", Right: "admins", Edge: "owner"},
+			{Left: "alice", Right: "users", Edge: "member"},
+			{Left: "bob", Right: "users", Edge: "member"},
+			{Left: "carol", Right: "users", Edge: "guest"},
+		} {
+			err := memberships.Link(e.Left, e.Right, e.Edge)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		err = memberships.Link("carol", "users", "member")
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		memberships := membershipsDefinition.ManyToMany(tx)
+
+		edge, err := memberships.Edge("carol", "users")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", edge, "member")
+
+		has, err := memberships.HasLink("bob", "admins")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		count, err := memberships.CountRights("alice")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 2)
+
+		count, err = memberships.CountLefts("users")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 3)
+
+		got := make(map[string]string)
+		_, err = memberships.IterateRights("alice", nil, false, func(r, e string) (bool, error) {
+			got[r] = e
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", got, map[string]string{"admins": "owner", "users": "member"})
+
+		var lefts []string
+		next, err := memberships.IterateLefts("users", nil, true, func(l, _ string) (bool, error) {
+			lefts = append(lefts, l)
+			return len(lefts) < 2, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", lefts, []string{"carol", "bob"})
+		assert(t, "", *next, "alice")
+
+		elements, totalElements, pages, err := memberships.PageOfLefts("users", 2, 2, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", elements, []boltron.ManyToManyElement[string, string, string]{
+			{Left: "carol", Right: "users", Edge: "member"},
+		})
+		assert(t, "", totalElements, 3)
+		assert(t, "", pages, 2)
+
+		elements, _, _, err = memberships.PageOfRights("alice", 1, 10, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", elements, []boltron.ManyToManyElement[string, string, string]{
+			{Left: "alice", Right: "admins", Edge: "owner"},
+			{Left: "alice", Right: "users", Edge: "member"},
+		})
+
+		rights, _, _, err := memberships.PageOfRightValues(1, 10, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", rights, []string{"admins", "users"})
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		memberships := membershipsDefinition.ManyToMany(tx)
+
+		err := memberships.Unlink("alice", "admins", true)
+		assertErrorFail(t, "", err, nil)
+
+		err = memberships.Unlink("alice", "admins", true)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		rights, _, _, err := memberships.PageOfRightValues(1, 10, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", rights, []string{"users"})
+
+		err = memberships.DeleteRight("users", true)
+		assertErrorFail(t, "", err, nil)
+
+		lefts, _, _, err := memberships.PageOfLeftValues(1, 10, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(lefts), 0)
+
+		err = memberships.DeleteLeft("alice", true)
+		assertError(t, "", err, boltron.ErrNotFound)
+	})
+}
+
+func TestManyToMany_nullEdge(t *testing.T) {
+	db := newDB(t)
+
+	followsDefinition := boltron.NewManyToManyDefinition(
+		"follows",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		boltron.NullEncoding,
+		nil,
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		follows := followsDefinition.ManyToMany(tx)
+
+		err := follows.Link("alice", "bob", nil)
+		assertErrorFail(t, "", err, nil)
+
+		has, err := follows.HasLink("alice", "bob")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		has, err = follows.HasLink("alice", "carol")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		_, err = follows.Edge("alice", "bob")
+		assertErrorFail(t, "", err, nil)
+
+		_, err = follows.Edge("alice", "carol")
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		err = follows.Link("alice", "carol", nil)
+		assertErrorFail(t, "", err, nil)
+
+		err = follows.Unlink("alice", "bob", true)
+		assertErrorFail(t, "", err, nil)
+
+		err = follows.Unlink("alice", "bob", true)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		count, err := follows.CountLefts("bob")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", count, 0)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		follows := followsDefinition.ManyToMany(tx)
+
+		has, err := follows.HasLink("alice", "carol")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		has, err = follows.HasLink("alice", "bob")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+	})
+}
+
+func TestManyToMany_skipCorrupted(t *testing.T) {
+	db := newDB(t)
+
+	var corrupted []string
+
+	definition := boltron.NewManyToManyDefinition(
+		"checksums",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		&boltron.ManyToManyOptions{
+			CorruptedHandler: func(key []byte, err error) {
+				assertError(t, "", err, boltron.ErrCorrupted)
+				corrupted = append(corrupted, string(key))
+			},
+		},
+	)
+
+	strictDefinition := boltron.NewManyToManyDefinition(
+		"checksums",
+		boltron.StringEncoding,
+		boltron.StringEncoding,
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		nil,
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		m := definition.ManyToMany(tx)
+		for _, r := range []string{"a", "b", "c"} {
+			err := m.Link("x", r, "edge "+r)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		// corrupt the edge value of the link between x and b
+		bucket := tx.Bucket([]byte("boltron: many to many: checksums left")).Bucket([]byte("x"))
+		v := append([]byte(nil), bucket.Get([]byte("b"))...)
+		v[0] ^= 0xff
+		err := bucket.Put([]byte("b"), v)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		var rights []string
+		_, err := definition.ManyToMany(tx).IterateRights("x", nil, false, func(r, _ string) (bool, error) {
+			rights = append(rights, r)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", rights, []string{"a", "c"})
+		assert(t, "", corrupted, []string{"b"})
+
+		elements, totalElements, pages, err := definition.ManyToMany(tx).PageOfRights("x", 1, 2, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(elements), 1)
+		assert(t, "", elements[0].Right, "a")
+		assert(t, "", totalElements, 3)
+		assert(t, "", pages, 2)
+		assert(t, "", corrupted, []string{"b", "b"})
+
+		_, err = strictDefinition.ManyToMany(tx).IterateRights("x", nil, false, func(_, _ string) (bool, error) {
+			return true, nil
+		})
+		assertError(t, "", err, boltron.ErrCorrupted)
+	})
+}
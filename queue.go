@@ -0,0 +1,611 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrLeaseExpired is the default error if the dequeued Queue item is
+// acknowledged after its lease expired and it became available again.
+var ErrLeaseExpired = errors.New("boltron: lease expired")
+
+// DefaultQueuePollInterval is the default duration between checks for
+// available items by the Queue Wait method.
+const DefaultQueuePollInterval = time.Second
+
+// Queue item records consist of the time when the item becomes available as
+// Unix nanoseconds, the number of delivery attempts and the encoded value.
+const (
+	queueRecordTimeLen     = 8
+	queueRecordAttemptsLen = 4
+	queueRecordHeaderLen   = queueRecordTimeLen + queueRecordAttemptsLen
+)
+
+// QueueDefinition defines a FIFO queue of values. Dequeued items are leased
+// for a visibility timeout during which they are not available to other
+// consumers, and they become available again if they are not acknowledged
+// before the lease expires.
+type QueueDefinition[V any] struct {
+	bucketNameItems    []byte
+	bucketNameSchedule []byte
+	bucketNameDead     []byte
+	valueEncoding      Encoding[V]
+	maxAttempts        int
+	pollInterval       time.Duration
+	now                func() time.Time
+	fillPercent        float64
+	errNotFound        error
+	errLeaseExpired    error
+}
+
+// QueueOptions provides additional configuration for a Queue.
+type QueueOptions struct {
+	// FillPercent is the value for the bolt bucket fill percent.
+	FillPercent float64
+	// MaxAttempts is the number of times the item can be dequeued before it is
+	// moved to dead letters. If it is not positive, items are delivered
+	// until they are acknowledged.
+	MaxAttempts int
+	// PollInterval is the maximal duration between checks for available items
+	// by the Wait method. Items enqueued in the same process on the same
+	// database are noticed immediately, even through a different definition
+	// with the same name. If it is not positive, DefaultQueuePollInterval is
+	// used.
+	PollInterval time.Duration
+	// Now returns the current time. If it is nil, time.Now is used.
+	Now func() time.Time
+	// ErrNotFound is returned if the item is not found.
+	ErrNotFound error
+	// ErrLeaseExpired is returned if the item is acknowledged after its lease
+	// expired.
+	ErrLeaseExpired error
+}
+
+// NewQueueDefinition constructs a new QueueDefinition with a unique name and
+// value encoding.
+func NewQueueDefinition[V any](
+	name string,
+	valueEncoding Encoding[V],
+	o *QueueOptions,
+) *QueueDefinition[V] {
+	if o == nil {
+		o = new(QueueOptions)
+	}
+	pollInterval := o.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultQueuePollInterval
+	}
+	now := o.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &QueueDefinition[V]{
+		bucketNameItems:    []byte("boltron: queue: " + name + " items"),
+		bucketNameSchedule: []byte("boltron: queue: " + name + " schedule"),
+		bucketNameDead:     []byte("boltron: queue: " + name + " dead"),
+		valueEncoding:      valueEncoding,
+		maxAttempts:        o.MaxAttempts,
+		pollInterval:       pollInterval,
+		now:                now,
+		fillPercent:        o.FillPercent,
+		errNotFound:        withDefaultError(o.ErrNotFound, ErrNotFound),
+		errLeaseExpired:    withDefaultError(o.ErrLeaseExpired, ErrLeaseExpired),
+	}
+}
+
+// Queue returns a Queue that has access to the stored data through the bolt
+// transaction.
+func (d *QueueDefinition[V]) Queue(tx *bolt.Tx) *Queue[V] {
+	return &Queue[V]{
+		tx:         tx,
+		definition: d,
+	}
+}
+
+// Wait dequeues the next available item in a new write transaction on the
+// database, blocking until an item becomes available or the context is done.
+func (d *QueueDefinition[V]) Wait(ctx context.Context, db *bolt.DB, visibilityTimeout time.Duration) (*QueueItem[V], error) {
+	for {
+		notify := queueNotifyChan(db, d.bucketNameItems)
+
+		var item *QueueItem[V]
+		var next time.Time
+		if err := db.Update(func(tx *bolt.Tx) (err error) {
+			q := d.Queue(tx)
+			item, err = q.Dequeue(visibilityTimeout)
+			if err != nil || item != nil {
+				return err
+			}
+			next, err = q.nextAvailable()
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		if item != nil {
+			return item, nil
+		}
+
+		wait := d.pollInterval
+		if !next.IsZero() {
+			if w := next.Sub(d.now()); w < wait {
+				wait = w
+			}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-notify:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// queueWaiters holds channels that are closed when items are enqueued, by the
+// database and the queue, so that waiters are notified regardless of which
+// QueueDefinition instance is used to enqueue items.
+var queueWaiters = struct {
+	sync.Mutex
+	m map[queueWaiterKey]chan struct{}
+}{
+	m: make(map[queueWaiterKey]chan struct{}),
+}
+
+type queueWaiterKey struct {
+	db   *bolt.DB
+	name string
+}
+
+func queueNotifyChan(db *bolt.DB, name []byte) chan struct{} {
+	queueWaiters.Lock()
+	defer queueWaiters.Unlock()
+
+	k := queueWaiterKey{db: db, name: string(name)}
+	c, ok := queueWaiters.m[k]
+	if !ok {
+		c = make(chan struct{})
+		queueWaiters.m[k] = c
+	}
+	return c
+}
+
+func queueSignal(db *bolt.DB, name []byte) {
+	queueWaiters.Lock()
+	defer queueWaiters.Unlock()
+
+	k := queueWaiterKey{db: db, name: string(name)}
+	if c, ok := queueWaiters.m[k]; ok {
+		close(c)
+		delete(queueWaiters.m, k)
+	}
+}
+
+// signalOnCommit notifies waiters on the queue after the transaction is
+// committed.
+func (q *Queue[V]) signalOnCommit() {
+	db := q.tx.DB() // the transaction is closed before commit handlers are called
+	q.tx.OnCommit(func() {
+		queueSignal(db, q.definition.bucketNameItems)
+	})
+}
+
+// Queue provides methods to enqueue and dequeue items.
+type Queue[V any] struct {
+	tx                  *bolt.Tx
+	itemsBucketCache    *bolt.Bucket
+	scheduleBucketCache *bolt.Bucket
+	deadBucketCache     *bolt.Bucket
+	definition          *QueueDefinition[V]
+}
+
+func (q *Queue[V]) bucket(cache **bolt.Bucket, name []byte, create bool) (*bolt.Bucket, error) {
+	if *cache != nil {
+		return *cache, nil
+	}
+	bucket, err := rootBucket(q.tx, create, name)
+	if err != nil {
+		return nil, err
+	}
+	if q.definition.fillPercent > 0 && bucket != nil {
+		bucket.FillPercent = q.definition.fillPercent
+	}
+	*cache = bucket
+	return bucket, nil
+}
+
+func (q *Queue[V]) itemsBucket(create bool) (*bolt.Bucket, error) {
+	return q.bucket(&q.itemsBucketCache, q.definition.bucketNameItems, create)
+}
+
+func (q *Queue[V]) scheduleBucket(create bool) (*bolt.Bucket, error) {
+	return q.bucket(&q.scheduleBucketCache, q.definition.bucketNameSchedule, create)
+}
+
+func (q *Queue[V]) deadBucket(create bool) (*bolt.Bucket, error) {
+	return q.bucket(&q.deadBucketCache, q.definition.bucketNameDead, create)
+}
+
+// QueueItem is the item returned by Queue methods.
+type QueueItem[V any] struct {
+	// ID is the unique identifier of the item, assigned in the order of
+	// enqueuing.
+	ID uint64
+	// Value is the enqueued value.
+	Value V
+	// Attempts is the number of times the item was dequeued.
+	Attempts int
+	// LeasedUntil is the time when the lease of the dequeued item expires.
+	LeasedUntil time.Time
+}
+
+func encodeQueueTime(t time.Time) uint64 {
+	n := t.UnixNano()
+	if n < 0 {
+		return 0
+	}
+	return uint64(n)
+}
+
+func decodeQueueTime(n uint64) time.Time {
+	return time.Unix(0, int64(n))
+}
+
+func queueScheduleKey(at uint64, id []byte) []byte {
+	k := make([]byte, 8, 8+len(id))
+	binary.BigEndian.PutUint64(k, at)
+	return append(k, id...)
+}
+
+func queueID(id uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, id)
+}
+
+func encodeQueueRecord(at uint64, attempts uint32, v []byte) []byte {
+	r := make([]byte, queueRecordHeaderLen, queueRecordHeaderLen+len(v))
+	binary.BigEndian.PutUint64(r, at)
+	binary.BigEndian.PutUint32(r[queueRecordTimeLen:], attempts)
+	return append(r, v...)
+}
+
+func decodeQueueRecord(r []byte) (at uint64, attempts uint32, v []byte, err error) {
+	if len(r) < queueRecordHeaderLen {
+		return 0, 0, nil, errors.New("invalid queue record")
+	}
+	return binary.BigEndian.Uint64(r), binary.BigEndian.Uint32(r[queueRecordTimeLen:]), r[queueRecordHeaderLen:], nil
+}
+
+// Enqueue adds the value to the end of the queue and returns its item ID.
+func (q *Queue[V]) Enqueue(value V) (id uint64, err error) {
+	return q.EnqueueAt(value, q.definition.now())
+}
+
+// EnqueueAt adds the value to the queue that becomes available at the provided
+// time and returns its item ID. Items that are available at the same time are
+// dequeued in the order of enqueuing.
+func (q *Queue[V]) EnqueueAt(value V, availableAt time.Time) (id uint64, err error) {
+	v, err := q.definition.valueEncoding.Encode(value)
+	if err != nil {
+		return 0, fmt.Errorf("encode value: %w", err)
+	}
+	itemsBucket, err := q.itemsBucket(true)
+	if err != nil {
+		return 0, fmt.Errorf("items bucket: %w", err)
+	}
+	id, err = itemsBucket.NextSequence()
+	if err != nil {
+		return 0, fmt.Errorf("next sequence: %w", err)
+	}
+	if err := q.schedule(queueID(id), encodeQueueTime(availableAt), 0, v); err != nil {
+		return 0, err
+	}
+	q.signalOnCommit()
+	return id, nil
+}
+
+// schedule saves the item record and its schedule key.
+func (q *Queue[V]) schedule(id []byte, at uint64, attempts uint32, v []byte) error {
+	itemsBucket, err := q.itemsBucket(true)
+	if err != nil {
+		return fmt.Errorf("items bucket: %w", err)
+	}
+	scheduleBucket, err := q.scheduleBucket(true)
+	if err != nil {
+		return fmt.Errorf("schedule bucket: %w", err)
+	}
+	if err := itemsBucket.Put(id, encodeQueueRecord(at, attempts, v)); err != nil {
+		return fmt.Errorf("put item: %w", err)
+	}
+	if err := scheduleBucket.Put(queueScheduleKey(at, id), []byte{}); err != nil {
+		return fmt.Errorf("put schedule: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the item record and its schedule key.
+func (q *Queue[V]) remove(id []byte, at uint64) error {
+	itemsBucket, err := q.itemsBucket(false)
+	if err != nil {
+		return fmt.Errorf("items bucket: %w", err)
+	}
+	scheduleBucket, err := q.scheduleBucket(false)
+	if err != nil {
+		return fmt.Errorf("schedule bucket: %w", err)
+	}
+	if itemsBucket == nil || scheduleBucket == nil {
+		return errors.New("queue buckets do not exist")
+	}
+	if err := itemsBucket.Delete(id); err != nil {
+		return fmt.Errorf("delete item: %w", err)
+	}
+	if err := scheduleBucket.Delete(queueScheduleKey(at, id)); err != nil {
+		return fmt.Errorf("delete schedule: %w", err)
+	}
+	return nil
+}
+
+// bury moves the item to dead letters.
+func (q *Queue[V]) bury(id []byte, at uint64, attempts uint32, v []byte) error {
+	v = append([]byte(nil), v...)
+	if err := q.remove(id, at); err != nil {
+		return err
+	}
+	deadBucket, err := q.deadBucket(true)
+	if err != nil {
+		return fmt.Errorf("dead bucket: %w", err)
+	}
+	if err := deadBucket.Put(id, encodeQueueRecord(0, attempts, v)); err != nil {
+		return fmt.Errorf("put dead item: %w", err)
+	}
+	return nil
+}
+
+// Dequeue leases the first available item for the visibility timeout and
+// returns it. The item must be acknowledged with Ack before the lease expires,
+// or it becomes available again. Items that reached the maximal number of
+// attempts are moved to dead letters. If there are no available items, nil is
+// returned.
+func (q *Queue[V]) Dequeue(visibilityTimeout time.Duration) (*QueueItem[V], error) {
+	scheduleBucket, err := q.scheduleBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("schedule bucket: %w", err)
+	}
+	if scheduleBucket == nil {
+		return nil, nil
+	}
+	itemsBucket, err := q.itemsBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("items bucket: %w", err)
+	}
+	now := q.definition.now()
+	for {
+		k, _ := scheduleBucket.Cursor().First()
+		if k == nil || binary.BigEndian.Uint64(k) > encodeQueueTime(now) {
+			return nil, nil
+		}
+		id := append([]byte(nil), k[8:]...)
+		at, attempts, v, err := decodeQueueRecord(itemsBucket.Get(id))
+		if err != nil {
+			return nil, err
+		}
+		if q.definition.maxAttempts > 0 && int(attempts) >= q.definition.maxAttempts {
+			if err := q.bury(id, at, attempts, v); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		value, err := q.definition.valueEncoding.Decode(v)
+		if err != nil {
+			return nil, fmt.Errorf("decode value: %w", err)
+		}
+		v = append([]byte(nil), v...)
+		if attempts < math.MaxUint32 {
+			attempts++
+		}
+		leasedUntil := now.Add(visibilityTimeout)
+		if err := q.remove(id, at); err != nil {
+			return nil, err
+		}
+		if err := q.schedule(id, encodeQueueTime(leasedUntil), attempts, v); err != nil {
+			return nil, err
+		}
+		return &QueueItem[V]{
+			ID:          binary.BigEndian.Uint64(id),
+			Value:       value,
+			Attempts:    int(attempts),
+			LeasedUntil: decodeQueueTime(encodeQueueTime(leasedUntil)),
+		}, nil
+	}
+}
+
+// leased returns the record of the dequeued item if its lease is still held.
+func (q *Queue[V]) leased(item *QueueItem[V]) (id []byte, at uint64, attempts uint32, v []byte, err error) {
+	itemsBucket, err := q.itemsBucket(false)
+	if err != nil {
+		return nil, 0, 0, nil, fmt.Errorf("items bucket: %w", err)
+	}
+	if itemsBucket == nil {
+		return nil, 0, 0, nil, q.definition.errNotFound
+	}
+	id = queueID(item.ID)
+	r := itemsBucket.Get(id)
+	if r == nil {
+		return nil, 0, 0, nil, q.definition.errNotFound
+	}
+	at, attempts, v, err = decodeQueueRecord(r)
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+	if at != encodeQueueTime(item.LeasedUntil) || int(attempts) != item.Attempts || at <= encodeQueueTime(q.definition.now()) {
+		return nil, 0, 0, nil, q.definition.errLeaseExpired
+	}
+	return id, at, attempts, append([]byte(nil), v...), nil
+}
+
+// Ack removes the dequeued item from the queue. If the lease of the item
+// expired, configured ErrLeaseExpired is returned, and if the item does not
+// exist, configured ErrNotFound is returned.
+func (q *Queue[V]) Ack(item *QueueItem[V]) error {
+	id, at, _, _, err := q.leased(item)
+	if err != nil {
+		return err
+	}
+	return q.remove(id, at)
+}
+
+// Nack releases the lease of the dequeued item, making it available again
+// after the delay. If the item reached the maximal number of attempts, it is
+// moved to dead letters.
+func (q *Queue[V]) Nack(item *QueueItem[V], delay time.Duration) error {
+	id, at, attempts, v, err := q.leased(item)
+	if err != nil {
+		return err
+	}
+	if q.definition.maxAttempts > 0 && int(attempts) >= q.definition.maxAttempts {
+		return q.bury(id, at, attempts, v)
+	}
+	if err := q.remove(id, at); err != nil {
+		return err
+	}
+	if err := q.schedule(id, encodeQueueTime(q.definition.now().Add(delay)), attempts, v); err != nil {
+		return err
+	}
+	q.signalOnCommit()
+	return nil
+}
+
+// nextAvailable returns the time when the next item becomes available, or zero
+// time if the queue is empty.
+func (q *Queue[V]) nextAvailable() (time.Time, error) {
+	scheduleBucket, err := q.scheduleBucket(false)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("schedule bucket: %w", err)
+	}
+	if scheduleBucket == nil {
+		return time.Time{}, nil
+	}
+	k, _ := scheduleBucket.Cursor().First()
+	if k == nil {
+		return time.Time{}, nil
+	}
+	return decodeQueueTime(binary.BigEndian.Uint64(k)), nil
+}
+
+// Size returns the number of items in the queue, including the leased and
+// delayed ones, but not dead letters.
+func (q *Queue[V]) Size() (int, error) {
+	itemsBucket, err := q.itemsBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("items bucket: %w", err)
+	}
+	if itemsBucket == nil {
+		return 0, nil
+	}
+	return size(itemsBucket, false), nil
+}
+
+// DeadLettersSize returns the number of items in dead letters.
+func (q *Queue[V]) DeadLettersSize() (int, error) {
+	deadBucket, err := q.deadBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("dead bucket: %w", err)
+	}
+	if deadBucket == nil {
+		return 0, nil
+	}
+	return size(deadBucket, false), nil
+}
+
+// IterateDeadLetters iterates over items in dead letters in the order of their
+// IDs. If the callback function f returns false, the iteration stops and the
+// next can be used to continue the iteration.
+func (q *Queue[V]) IterateDeadLetters(start *uint64, reverse bool, f func(QueueItem[V]) (bool, error)) (next *uint64, err error) {
+	deadBucket, err := q.deadBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("dead bucket: %w", err)
+	}
+	if deadBucket == nil {
+		return nil, nil
+	}
+	return iterateKeys(deadBucket, Uint64BinaryEncoding, nil, start, reverse, func(k, r []byte) (bool, error) {
+		_, attempts, v, err := decodeQueueRecord(r)
+		if err != nil {
+			return false, err
+		}
+
+		value, err := q.definition.valueEncoding.Decode(v)
+		if err != nil {
+			return false, fmt.Errorf("decode value: %w", err)
+		}
+
+		return f(QueueItem[V]{
+			ID:       binary.BigEndian.Uint64(k),
+			Value:    value,
+			Attempts: int(attempts),
+		})
+	})
+}
+
+// RequeueDeadLetter moves the item from dead letters back to the queue with
+// reset attempts, available immediately. If the item does not exist in dead
+// letters, configured ErrNotFound is returned.
+func (q *Queue[V]) RequeueDeadLetter(id uint64) error {
+	deadBucket, err := q.deadBucket(false)
+	if err != nil {
+		return fmt.Errorf("dead bucket: %w", err)
+	}
+	if deadBucket == nil {
+		return q.definition.errNotFound
+	}
+	k := queueID(id)
+	r := deadBucket.Get(k)
+	if r == nil {
+		return q.definition.errNotFound
+	}
+	_, _, v, err := decodeQueueRecord(r)
+	if err != nil {
+		return err
+	}
+	v = append([]byte(nil), v...)
+	if err := deadBucket.Delete(k); err != nil {
+		return fmt.Errorf("delete dead item: %w", err)
+	}
+	if err := q.schedule(k, encodeQueueTime(q.definition.now()), 0, v); err != nil {
+		return err
+	}
+	q.signalOnCommit()
+	return nil
+}
+
+// DeleteDeadLetter removes the item from dead letters. If ensure flag is set to
+// true and the item does not exist, configured ErrNotFound is returned.
+func (q *Queue[V]) DeleteDeadLetter(id uint64, ensure bool) error {
+	deadBucket, err := q.deadBucket(false)
+	if err != nil {
+		return fmt.Errorf("dead bucket: %w", err)
+	}
+	k := queueID(id)
+	if deadBucket == nil || deadBucket.Get(k) == nil {
+		if ensure {
+			return q.definition.errNotFound
+		}
+		return nil
+	}
+	if err := deadBucket.Delete(k); err != nil {
+		return fmt.Errorf("delete dead item: %w", err)
+	}
+	return nil
+}
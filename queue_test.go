@@ -0,0 +1,170 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+func TestQueue(t *testing.T) {
+	db := newDB(t)
+
+	now := time.Unix(1600000000, 0)
+	definition := boltron.NewQueueDefinition(
+		"jobs",
+		boltron.StringEncoding,
+		&boltron.QueueOptions{
+			MaxAttempts: 2,
+			Now:         func() time.Time { return now },
+		},
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		jobs := definition.Queue(tx)
+
+		item, err := jobs.Dequeue(time.Minute)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", item, (*boltron.QueueItem[string])(nil))
+
+		for _, v := range []string{"first", "second"} {
+			_, err := jobs.Enqueue(v)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		_, err = jobs.EnqueueAt("delayed", now.Add(time.Hour))
+		assertErrorFail(t, "", err, nil)
+	})
+
+	var leased *boltron.QueueItem[string]
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		jobs := definition.Queue(tx)
+
+		item, err := jobs.Dequeue(time.Minute)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", item.Value, "first")
+		assert(t, "", item.Attempts, 1)
+		assertTime(t, "", item.LeasedUntil, now.Add(time.Minute))
+
+		err = jobs.Ack(item)
+		assertErrorFail(t, "", err, nil)
+
+		err = jobs.Ack(item)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		leased, err = jobs.Dequeue(time.Minute)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", leased.Value, "second")
+
+		item, err = jobs.Dequeue(time.Minute)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "delayed item is not available", item, (*boltron.QueueItem[string])(nil))
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		size, err := definition.Queue(tx).Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 2)
+	})
+
+	now = now.Add(2 * time.Minute)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		jobs := definition.Queue(tx)
+
+		err := jobs.Ack(leased)
+		assertError(t, "", err, boltron.ErrLeaseExpired)
+
+		item, err := jobs.Dequeue(time.Minute)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", item.Value, "second")
+		assert(t, "", item.Attempts, 2)
+
+		err = jobs.Nack(item, 0)
+		assertErrorFail(t, "", err, nil)
+
+		item, err = jobs.Dequeue(time.Minute)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", item, (*boltron.QueueItem[string])(nil))
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		jobs := definition.Queue(tx)
+
+		size, err := jobs.DeadLettersSize()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 1)
+
+		var dead []boltron.QueueItem[string]
+		_, err = jobs.IterateDeadLetters(nil, false, func(i boltron.QueueItem[string]) (bool, error) {
+			dead = append(dead, i)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", dead, []boltron.QueueItem[string]{{ID: 2, Value: "second", Attempts: 2}})
+
+		err = jobs.RequeueDeadLetter(2)
+		assertErrorFail(t, "", err, nil)
+
+		item, err := jobs.Dequeue(time.Minute)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", item.Value, "second")
+		assert(t, "", item.Attempts, 1)
+
+		err = jobs.Ack(item)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	now = now.Add(time.Hour)
+
+	item, err := definition.Wait(context.Background(), db, time.Minute)
+	assertErrorFail(t, "", err, nil)
+	assert(t, "", item.Value, "delayed")
+}
+
+func TestQueue_Wait(t *testing.T) {
+	db := newDB(t)
+
+	definition := boltron.NewQueueDefinition("jobs", boltron.StringEncoding, &boltron.QueueOptions{
+		PollInterval: time.Hour,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := definition.Wait(ctx, db, time.Minute)
+	assertError(t, "", err, context.DeadlineExceeded)
+
+	result := make(chan *boltron.QueueItem[string])
+	go func() {
+		item, err := definition.Wait(context.Background(), db, time.Minute)
+		if err != nil {
+			t.Error(err)
+		}
+		result <- item
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	// the waiter is notified by an enqueuer with a different definition
+	// instance of the same queue
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := boltron.NewQueueDefinition("jobs", boltron.StringEncoding, nil).Queue(tx).Enqueue("job")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case item := <-result:
+		assert(t, "", item.Value, "job")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for item")
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Order statistics of a list are kept in a ranks bucket that splits the list
+// bucket keys into contiguous blocks. Every block is stored under its start key
+// with the number of list elements in it as the value. A block contains all
+// keys that are greater than or equal to its start key and less than the start
+// key of the next block, while the first block also contains all keys before
+// its start key. Blocks have between one and two times the block size
+// elements, as a block is split in half when it grows beyond that. The total
+// number of elements is stored as the ranks bucket sequence.
+//
+// Rank and position queries sum block counts instead of iterating over all
+// list elements, and then iterate over at most one block. For a list of n
+// elements and the block size b, they read n/b block counts, that are small
+// and stored sequentially in the same pages, and up to 2*b list keys.
+
+// DefaultOrderStatisticsBlockSize is the number of list elements in a block of
+// order statistics if it is not set in ListOptions or ListsOptions. With it, a
+// list of a million elements has about four thousand blocks, that fit in a few
+// pages, while a single block is iterated in one or two list bucket pages.
+const DefaultOrderStatisticsBlockSize = 256
+
+func encodeRankCount(c uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, c)
+}
+
+func decodeRankCount(b []byte) uint64 {
+	if len(b) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+// rankBlockFor returns the start key and the count of the block that contains
+// the list bucket key k and true if it is the first block.
+func rankBlockFor(ranks *bolt.Bucket, k []byte) (start []byte, count uint64, first bool) {
+	c := ranks.Cursor()
+	s, v := c.Seek(k)
+	if s == nil {
+		s, v = c.Last()
+	} else if !bytes.Equal(s, k) {
+		s, v = c.Prev()
+	}
+	if s == nil {
+		s, v = c.First()
+	}
+	if s == nil {
+		return nil, 0, false
+	}
+	f, _ := ranks.Cursor().First()
+	return append([]byte(nil), s...), decodeRankCount(v), bytes.Equal(f, s)
+}
+
+// rankBlockCursor positions the list bucket cursor at the first key of the
+// block.
+func rankBlockCursor(list *bolt.Bucket, start []byte, first bool) (c *bolt.Cursor, k, v []byte) {
+	c = list.Cursor()
+	if first {
+		k, v = c.First()
+	} else {
+		k, v = c.Seek(start)
+	}
+	return c, k, v
+}
+
+// rankBuild creates blocks for all keys in the list bucket.
+func rankBuild(ranks, list *bolt.Bucket, blockSize int) error {
+	var start []byte
+	var count, total uint64
+	c := list.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		if count == uint64(blockSize) {
+			if err := ranks.Put(start, encodeRankCount(count)); err != nil {
+				return fmt.Errorf("put rank block: %w", err)
+			}
+			start, count = nil, 0
+		}
+		if start == nil {
+			start = append([]byte(nil), k...)
+		}
+		count++
+		total++
+	}
+	if count > 0 {
+		if err := ranks.Put(start, encodeRankCount(count)); err != nil {
+			return fmt.Errorf("put rank block: %w", err)
+		}
+	}
+	if err := ranks.SetSequence(total); err != nil {
+		return fmt.Errorf("set rank total: %w", err)
+	}
+	return nil
+}
+
+// rankInsert accounts the key k that was put in the list bucket, splitting the
+// block if it became too large.
+func rankInsert(ranks, list *bolt.Bucket, k []byte, blockSize int) error {
+	if err := ranks.SetSequence(ranks.Sequence() + 1); err != nil {
+		return fmt.Errorf("set rank total: %w", err)
+	}
+	start, count, first := rankBlockFor(ranks, k)
+	if start == nil {
+		if err := ranks.Put(append([]byte(nil), k...), encodeRankCount(1)); err != nil {
+			return fmt.Errorf("put rank block: %w", err)
+		}
+		return nil
+	}
+	count++
+	if count <= 2*uint64(blockSize) {
+		if err := ranks.Put(start, encodeRankCount(count)); err != nil {
+			return fmt.Errorf("put rank block: %w", err)
+		}
+		return nil
+	}
+	c, lk, _ := rankBlockCursor(list, start, first)
+	if first {
+		// the first block may contain keys before its start key, which would
+		// end up in the second block after the split
+		if err := ranks.Delete(start); err != nil {
+			return fmt.Errorf("delete rank block: %w", err)
+		}
+		start = append([]byte(nil), lk...)
+	}
+	for i := 0; i < blockSize && lk != nil; i++ {
+		lk, _ = c.Next()
+	}
+	if lk == nil {
+		return fmt.Errorf("rank block split key not found")
+	}
+	lk = append([]byte(nil), lk...)
+	if err := ranks.Put(start, encodeRankCount(uint64(blockSize))); err != nil {
+		return fmt.Errorf("put rank block: %w", err)
+	}
+	if err := ranks.Put(lk, encodeRankCount(count-uint64(blockSize))); err != nil {
+		return fmt.Errorf("put rank block: %w", err)
+	}
+	return nil
+}
+
+// rankDelete accounts the key k that was deleted from the list bucket,
+// removing the block if it became empty.
+func rankDelete(ranks *bolt.Bucket, k []byte) error {
+	start, count, _ := rankBlockFor(ranks, k)
+	if start == nil {
+		return fmt.Errorf("rank block not found")
+	}
+	if total := ranks.Sequence(); total > 0 {
+		if err := ranks.SetSequence(total - 1); err != nil {
+			return fmt.Errorf("set rank total: %w", err)
+		}
+	}
+	if count <= 1 {
+		if err := ranks.Delete(start); err != nil {
+			return fmt.Errorf("delete rank block: %w", err)
+		}
+		return nil
+	}
+	if err := ranks.Put(start, encodeRankCount(count-1)); err != nil {
+		return fmt.Errorf("put rank block: %w", err)
+	}
+	return nil
+}
+
+// rankTotal returns the number of list elements accounted in blocks.
+func rankTotal(ranks *bolt.Bucket) int {
+	return int(ranks.Sequence())
+}
+
+// rankOf returns the number of list bucket keys that are less than k.
+func rankOf(ranks, list *bolt.Bucket, k []byte) int {
+	var rank int
+	c := ranks.Cursor()
+	s, v := c.First()
+	if s == nil {
+		return 0
+	}
+	first := true
+	for {
+		ns, nv := c.Next()
+		if ns == nil || bytes.Compare(ns, k) > 0 {
+			break
+		}
+		rank += int(decodeRankCount(v))
+		s, v = ns, nv
+		first = false
+	}
+	lc, lk, _ := rankBlockCursor(list, s, first)
+	for ; lk != nil && bytes.Compare(lk, k) < 0; lk, _ = lc.Next() {
+		rank++
+	}
+	return rank
+}
+
+// rankAt returns the list bucket key and value at the index.
+func rankAt(ranks, list *bolt.Bucket, index int) (k, v []byte) {
+	var offset int
+	c := ranks.Cursor()
+	first := true
+	for s, count := c.First(); s != nil; s, count = c.Next() {
+		n := int(decodeRankCount(count))
+		if offset+n <= index {
+			offset += n
+			first = false
+			continue
+		}
+		lc, lk, lv := rankBlockCursor(list, s, first)
+		for i := offset; i < index && lk != nil; i++ {
+			lk, lv = lc.Next()
+		}
+		return lk, lv
+	}
+	return nil, nil
+}
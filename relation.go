@@ -0,0 +1,566 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// ErrParentNotFound is the default error if requested parent in Relation
+	// does not exist.
+	ErrParentNotFound = errors.New("boltron: parent not found")
+	// ErrChildNotFound is the default error if requested child in Relation
+	// does not exist.
+	ErrChildNotFound = errors.New("boltron: child not found")
+	// ErrChildExists is the default error if the child already has a
+	// different parent in the Relation.
+	ErrChildExists = errors.New("boltron: child exists")
+)
+
+// RelationDefinition defines one-to-many relation between parents and
+// children. Every child has exactly one parent, while a parent can have many
+// children.
+type RelationDefinition[P, C any] struct {
+	bucketNameChildren []byte
+	bucketPathParents  [][]byte
+	parentEncoding     Encoding[P]
+	childEncoding      Encoding[C]
+	fillPercent        float64
+	errParentNotFound  error
+	errChildNotFound   error
+	errChildExists     error
+	corruptedHandler   func(key []byte, err error)
+}
+
+// RelationOptions provides additional configuration for a Relation.
+type RelationOptions struct {
+	// FillPercent is the value for the bolt bucket fill percent.
+	FillPercent float64
+	// ErrParentNotFound is returned if the parent is not found.
+	ErrParentNotFound error
+	// ErrChildNotFound is returned if the child is not found.
+	ErrChildNotFound error
+	// ErrChildExists is returned if the child already has a different
+	// parent.
+	ErrChildExists error
+	// CorruptedHandler is called with the encoded child or parent of every
+	// element that is skipped by iteration and pagination methods because it
+	// can not be decoded with ErrCorrupted error. If it is nil, iteration is
+	// aborted. Skipped elements are still counted in total elements and pages
+	// returned by pagination methods, so a page may contain fewer elements
+	// than the limit.
+	CorruptedHandler func(key []byte, err error)
+}
+
+// NewRelationDefinition constructs a new RelationDefinition with a unique name
+// and parent and child encodings.
+func NewRelationDefinition[P, C any](
+	name string,
+	parentEncoding Encoding[P],
+	childEncoding Encoding[C],
+	o *RelationOptions,
+) *RelationDefinition[P, C] {
+	if o == nil {
+		o = new(RelationOptions)
+	}
+	return &RelationDefinition[P, C]{
+		bucketNameChildren: []byte("boltron: relation: " + name + " children"),
+		bucketPathParents:  bucketPath("boltron: relation: " + name + " parents"),
+		parentEncoding:     parentEncoding,
+		childEncoding:      childEncoding,
+		fillPercent:        o.FillPercent,
+		errParentNotFound:  withDefaultError(o.ErrParentNotFound, ErrParentNotFound),
+		errChildNotFound:   withDefaultError(o.ErrChildNotFound, ErrChildNotFound),
+		errChildExists:     withDefaultError(o.ErrChildExists, ErrChildExists),
+		corruptedHandler:   o.CorruptedHandler,
+	}
+}
+
+// Relation returns a Relation that has access to the stored data through the
+// bolt transaction.
+func (d *RelationDefinition[P, C]) Relation(tx *bolt.Tx) *Relation[P, C] {
+	return &Relation[P, C]{
+		tx:         tx,
+		definition: d,
+	}
+}
+
+// Relation provides methods to access and change one-to-many relations.
+type Relation[P, C any] struct {
+	tx                  *bolt.Tx
+	childrenBucketCache *bolt.Bucket
+	parentsBucketCache  *bolt.Bucket
+	definition          *RelationDefinition[P, C]
+}
+
+func (r *Relation[P, C]) childrenBucket(create bool) (*bolt.Bucket, error) {
+	if r.childrenBucketCache != nil {
+		return r.childrenBucketCache, nil
+	}
+	bucket, err := rootBucket(r.tx, create, r.definition.bucketNameChildren)
+	if err != nil {
+		return nil, err
+	}
+	r.childrenBucketCache = bucket
+	return bucket, nil
+}
+
+func (r *Relation[P, C]) parentsBucket(create bool) (*bolt.Bucket, error) {
+	if r.parentsBucketCache != nil {
+		return r.parentsBucketCache, nil
+	}
+	bucket, err := deepBucket(r.tx, create, r.definition.bucketPathParents...)
+	if err != nil {
+		return nil, err
+	}
+	if r.definition.fillPercent > 0 && bucket != nil {
+		bucket.FillPercent = r.definition.fillPercent
+	}
+	r.parentsBucketCache = bucket
+	return bucket, nil
+}
+
+// parentChildrenBucket returns the bucket with children of the encoded parent.
+func (r *Relation[P, C]) parentChildrenBucket(p []byte, create bool) (*bolt.Bucket, error) {
+	childrenBucket, err := r.childrenBucket(create)
+	if err != nil {
+		return nil, fmt.Errorf("children bucket: %w", err)
+	}
+	if childrenBucket == nil {
+		return nil, nil
+	}
+	bucket, err := nestedBucket(childrenBucket, create, p)
+	if err != nil {
+		return nil, fmt.Errorf("parent children bucket: %w", err)
+	}
+	if r.definition.fillPercent > 0 && bucket != nil {
+		bucket.FillPercent = r.definition.fillPercent
+	}
+	return bucket, nil
+}
+
+// HasParent returns true if the parent has at least one child.
+func (r *Relation[P, C]) HasParent(parent P) (bool, error) {
+	p, err := r.definition.parentEncoding.Encode(parent)
+	if err != nil {
+		return false, fmt.Errorf("encode parent: %w", err)
+	}
+	bucket, err := r.parentChildrenBucket(p, false)
+	if err != nil {
+		return false, err
+	}
+	if bucket == nil {
+		return false, nil
+	}
+	f, _ := bucket.Cursor().First()
+	return f != nil, nil
+}
+
+// HasChild returns true if the child already exists in the database.
+func (r *Relation[P, C]) HasChild(child C) (bool, error) {
+	c, err := r.definition.childEncoding.Encode(child)
+	if err != nil {
+		return false, fmt.Errorf("encode child: %w", err)
+	}
+	parentsBucket, err := r.parentsBucket(false)
+	if err != nil {
+		return false, fmt.Errorf("parents bucket: %w", err)
+	}
+	if parentsBucket == nil {
+		return false, nil
+	}
+	return parentsBucket.Get(c) != nil, nil
+}
+
+// Parent returns the parent of the child. If the child does not exist,
+// configured ErrChildNotFound is returned.
+func (r *Relation[P, C]) Parent(child C) (parent P, err error) {
+	c, err := r.definition.childEncoding.Encode(child)
+	if err != nil {
+		return parent, fmt.Errorf("encode child: %w", err)
+	}
+	parentsBucket, err := r.parentsBucket(false)
+	if err != nil {
+		return parent, fmt.Errorf("parents bucket: %w", err)
+	}
+	if parentsBucket == nil {
+		return parent, r.definition.errChildNotFound
+	}
+	p := parentsBucket.Get(c)
+	if p == nil {
+		return parent, r.definition.errChildNotFound
+	}
+	parent, err = r.definition.parentEncoding.Decode(p)
+	if err != nil {
+		return parent, fmt.Errorf("decode parent: %w", err)
+	}
+	return parent, nil
+}
+
+// Add saves the relation between the parent and the child. If the child
+// already has a different parent, configured ErrChildExists is returned.
+func (r *Relation[P, C]) Add(parent P, child C) error {
+	p, err := r.definition.parentEncoding.Encode(parent)
+	if err != nil {
+		return fmt.Errorf("encode parent: %w", err)
+	}
+	c, err := r.definition.childEncoding.Encode(child)
+	if err != nil {
+		return fmt.Errorf("encode child: %w", err)
+	}
+	parentsBucket, err := r.parentsBucket(true)
+	if err != nil {
+		return fmt.Errorf("parents bucket: %w", err)
+	}
+	if currentParent := parentsBucket.Get(c); currentParent != nil {
+		if bytes.Equal(currentParent, p) {
+			return nil
+		}
+		return r.definition.errChildExists
+	}
+	return r.put(parentsBucket, p, c)
+}
+
+// Move changes the parent of the existing child. If the child does not exist,
+// configured ErrChildNotFound is returned.
+func (r *Relation[P, C]) Move(child C, newParent P) error {
+	p, err := r.definition.parentEncoding.Encode(newParent)
+	if err != nil {
+		return fmt.Errorf("encode parent: %w", err)
+	}
+	c, err := r.definition.childEncoding.Encode(child)
+	if err != nil {
+		return fmt.Errorf("encode child: %w", err)
+	}
+	parentsBucket, err := r.parentsBucket(false)
+	if err != nil {
+		return fmt.Errorf("parents bucket: %w", err)
+	}
+	if parentsBucket == nil {
+		return r.definition.errChildNotFound
+	}
+	currentParent := parentsBucket.Get(c)
+	if currentParent == nil {
+		return r.definition.errChildNotFound
+	}
+	if bytes.Equal(currentParent, p) {
+		return nil
+	}
+	if err := r.removeFromParent(append([]byte(nil), currentParent...), c); err != nil {
+		return err
+	}
+	return r.put(parentsBucket, p, c)
+}
+
+func (r *Relation[P, C]) put(parentsBucket *bolt.Bucket, p, c []byte) error {
+	bucket, err := r.parentChildrenBucket(p, true)
+	if err != nil {
+		return err
+	}
+	if err := bucket.Put(c, nil); err != nil {
+		return fmt.Errorf("put child: %w", err)
+	}
+	if err := parentsBucket.Put(c, p); err != nil {
+		return fmt.Errorf("put parent: %w", err)
+	}
+	return nil
+}
+
+// removeFromParent removes the child from the parent children bucket and
+// removes the bucket if it is empty.
+func (r *Relation[P, C]) removeFromParent(p, c []byte) error {
+	childrenBucket, err := r.childrenBucket(false)
+	if err != nil {
+		return fmt.Errorf("children bucket: %w", err)
+	}
+	if childrenBucket == nil {
+		return errors.New("children bucket does not exist")
+	}
+	bucket := childrenBucket.Bucket(p)
+	if bucket == nil {
+		return errors.New("parent children bucket does not exist")
+	}
+	if err := bucket.Delete(c); err != nil {
+		return fmt.Errorf("delete child: %w", err)
+	}
+	if f, _ := bucket.Cursor().First(); f == nil {
+		if err := childrenBucket.DeleteBucket(p); err != nil {
+			return fmt.Errorf("delete empty parent children bucket: %w", err)
+		}
+	}
+	return nil
+}
+
+// RemoveChild removes the child from its parent. If ensure flag is set to true
+// and the child does not exist, configured ErrChildNotFound is returned.
+func (r *Relation[P, C]) RemoveChild(child C, ensure bool) error {
+	c, err := r.definition.childEncoding.Encode(child)
+	if err != nil {
+		return fmt.Errorf("encode child: %w", err)
+	}
+	parentsBucket, err := r.parentsBucket(false)
+	if err != nil {
+		return fmt.Errorf("parents bucket: %w", err)
+	}
+	if parentsBucket == nil {
+		if ensure {
+			return r.definition.errChildNotFound
+		}
+		return nil
+	}
+	p := parentsBucket.Get(c)
+	if p == nil {
+		if ensure {
+			return r.definition.errChildNotFound
+		}
+		return nil
+	}
+	if err := r.removeFromParent(append([]byte(nil), p...), c); err != nil {
+		return err
+	}
+	if err := parentsBucket.Delete(c); err != nil {
+		return fmt.Errorf("delete parent: %w", err)
+	}
+	return nil
+}
+
+// DeleteParent removes the parent and relations to all of its children. If
+// ensure flag is set to true and the parent does not exist, configured
+// ErrParentNotFound is returned.
+func (r *Relation[P, C]) DeleteParent(parent P, ensure bool) error {
+	p, err := r.definition.parentEncoding.Encode(parent)
+	if err != nil {
+		return fmt.Errorf("encode parent: %w", err)
+	}
+	childrenBucket, err := r.childrenBucket(false)
+	if err != nil {
+		return fmt.Errorf("children bucket: %w", err)
+	}
+	if childrenBucket == nil || childrenBucket.Bucket(p) == nil {
+		if ensure {
+			return r.definition.errParentNotFound
+		}
+		return nil
+	}
+	parentsBucket, err := r.parentsBucket(false)
+	if err != nil {
+		return fmt.Errorf("parents bucket: %w", err)
+	}
+	if parentsBucket == nil {
+		return errors.New("parents bucket does not exist")
+	}
+	if err := childrenBucket.Bucket(p).ForEach(func(c, _ []byte) error {
+		if err := parentsBucket.Delete(c); err != nil {
+			return fmt.Errorf("delete parent: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("delete children: %w", err)
+	}
+	if err := childrenBucket.DeleteBucket(p); err != nil {
+		return fmt.Errorf("delete parent children bucket: %w", err)
+	}
+	return nil
+}
+
+// Size returns the number of children in all relations.
+func (r *Relation[P, C]) Size() (int, error) {
+	parentsBucket, err := r.parentsBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("parents bucket: %w", err)
+	}
+	if parentsBucket == nil {
+		return 0, nil
+	}
+	return size(parentsBucket, false), nil
+}
+
+// CountChildren returns the number of children of the parent.
+func (r *Relation[P, C]) CountChildren(parent P) (int, error) {
+	p, err := r.definition.parentEncoding.Encode(parent)
+	if err != nil {
+		return 0, fmt.Errorf("encode parent: %w", err)
+	}
+	bucket, err := r.parentChildrenBucket(p, false)
+	if err != nil {
+		return 0, err
+	}
+	if bucket == nil {
+		return 0, nil
+	}
+	return size(bucket, false), nil
+}
+
+// Iterate iterates over children and their parents in the lexicographical
+// order of children. If the callback function f returns false, the iteration
+// stops and the next can be used to continue the iteration.
+func (r *Relation[P, C]) Iterate(start *C, reverse bool, f func(C, P) (bool, error)) (next *C, err error) {
+	parentsBucket, err := r.parentsBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("parents bucket: %w", err)
+	}
+	if parentsBucket == nil {
+		return nil, nil
+	}
+	return iterateKeys(parentsBucket, r.definition.childEncoding, r.definition.corruptedHandler, start, reverse, func(c, p []byte) (bool, error) {
+		child, err := r.definition.childEncoding.Decode(c)
+		if err != nil {
+			if skipCorrupted(r.definition.corruptedHandler, c, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode child: %w", err)
+		}
+
+		parent, err := r.definition.parentEncoding.Decode(p)
+		if err != nil {
+			if skipCorrupted(r.definition.corruptedHandler, c, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode parent: %w", err)
+		}
+
+		return f(child, parent)
+	})
+}
+
+// IterateParents iterates over parents in the lexicographical order of parents.
+// If the callback function f returns false, the iteration stops and the next
+// can be used to continue the iteration.
+func (r *Relation[P, C]) IterateParents(start *P, reverse bool, f func(P) (bool, error)) (next *P, err error) {
+	childrenBucket, err := r.childrenBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("children bucket: %w", err)
+	}
+	if childrenBucket == nil {
+		return nil, nil
+	}
+	return iterateKeys(childrenBucket, r.definition.parentEncoding, r.definition.corruptedHandler, start, reverse, func(p, _ []byte) (bool, error) {
+		parent, err := r.definition.parentEncoding.Decode(p)
+		if err != nil {
+			if skipCorrupted(r.definition.corruptedHandler, p, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode parent: %w", err)
+		}
+
+		return f(parent)
+	})
+}
+
+// IterateChildren iterates over children of the parent in the lexicographical
+// order of children. If the callback function f returns false, the iteration
+// stops and the next can be used to continue the iteration.
+func (r *Relation[P, C]) IterateChildren(parent P, start *C, reverse bool, f func(C) (bool, error)) (next *C, err error) {
+	p, err := r.definition.parentEncoding.Encode(parent)
+	if err != nil {
+		return nil, fmt.Errorf("encode parent: %w", err)
+	}
+	bucket, err := r.parentChildrenBucket(p, false)
+	if err != nil {
+		return nil, err
+	}
+	if bucket == nil {
+		return nil, nil
+	}
+	return iterateKeys(bucket, r.definition.childEncoding, r.definition.corruptedHandler, start, reverse, func(c, _ []byte) (bool, error) {
+		child, err := r.definition.childEncoding.Decode(c)
+		if err != nil {
+			if skipCorrupted(r.definition.corruptedHandler, c, err) {
+				return true, nil
+			}
+			return false, fmt.Errorf("decode child: %w", err)
+		}
+
+		return f(child)
+	})
+}
+
+// RelationElement is the type returned by Relation pagination methods as slice
+// elements that contain both child and its parent.
+type RelationElement[P, C any] struct {
+	Parent P
+	Child  C
+}
+
+// Page returns at most a limit of children and their parents at the provided
+// page number.
+func (r *Relation[P, C]) Page(number, limit int, reverse bool) (s []RelationElement[P, C], totalElements, pages int, err error) {
+	parentsBucket, err := r.parentsBucket(false)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("parents bucket: %w", err)
+	}
+	if parentsBucket == nil {
+		return nil, 0, 0, nil
+	}
+	return page(parentsBucket, false, number, limit, reverse, func(c, p []byte) (e RelationElement[P, C], err error) {
+		child, err := r.definition.childEncoding.Decode(c)
+		if err != nil {
+			if skipCorrupted(r.definition.corruptedHandler, c, err) {
+				return e, errSkipElement
+			}
+			return e, fmt.Errorf("decode child: %w", err)
+		}
+
+		parent, err := r.definition.parentEncoding.Decode(p)
+		if err != nil {
+			if skipCorrupted(r.definition.corruptedHandler, c, err) {
+				return e, errSkipElement
+			}
+			return e, fmt.Errorf("decode parent: %w", err)
+		}
+
+		return RelationElement[P, C]{
+			Parent: parent,
+			Child:  child,
+		}, nil
+	})
+}
+
+// PageOfParents returns at most a limit of parents at the provided page number.
+func (r *Relation[P, C]) PageOfParents(number, limit int, reverse bool) (s []P, totalElements, pages int, err error) {
+	childrenBucket, err := r.childrenBucket(false)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("children bucket: %w", err)
+	}
+	if childrenBucket == nil {
+		return nil, 0, 0, nil
+	}
+	return page(childrenBucket, true, number, limit, reverse, func(p, _ []byte) (parent P, err error) {
+		parent, err = r.definition.parentEncoding.Decode(p)
+		if skipCorrupted(r.definition.corruptedHandler, p, err) {
+			return parent, errSkipElement
+		}
+		return parent, err
+	})
+}
+
+// PageOfChildren returns at most a limit of children of the parent at the
+// provided page number.
+func (r *Relation[P, C]) PageOfChildren(parent P, number, limit int, reverse bool) (s []C, totalElements, pages int, err error) {
+	p, err := r.definition.parentEncoding.Encode(parent)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("encode parent: %w", err)
+	}
+	bucket, err := r.parentChildrenBucket(p, false)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if bucket == nil {
+		return nil, 0, 0, nil
+	}
+	return page(bucket, false, number, limit, reverse, func(c, _ []byte) (child C, err error) {
+		child, err = r.definition.childEncoding.Decode(c)
+		if skipCorrupted(r.definition.corruptedHandler, c, err) {
+			return child, errSkipElement
+		}
+		return child, err
+	})
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+var foldersDefinition = boltron.NewRelationDefinition(
+	"folders",
+	boltron.StringEncoding,
+	boltron.StringEncoding,
+	nil,
+)
+
+func TestRelation(t *testing.T) {
+	db := newDB(t)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		folders := foldersDefinition.Relation(tx)
+
+		_, err := folders.Parent("a.txt")
+		assertError(t, "", err, boltron.ErrChildNotFound)
+
+		for _, e := range []boltron.RelationElement[string, string]{
+			{Parent: "docs", Child: "a.txt"},
+			{Parent: "docs", Child: "b.txt"},
+			{Parent: "docs", Child: "c.txt"},
+			{Parent: "images", Child: "d.png"},
+		} {
+			err := folders.Add(e.Parent, e.Child)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		err = folders.Add("docs", "a.txt")
+		assertErrorFail(t, "", err, nil)
+
+		err = folders.Add("images", "a.txt")
+		assertError(t, "", err, boltron.ErrChildExists)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		folders := foldersDefinition.Relation(tx)
+
+		parent, err := folders.Parent("b.txt")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", parent, "docs")
+
+		has, err := folders.HasParent("images")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, true)
+
+		has, err = folders.HasChild("e.txt")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		var children []string
+		next, err := folders.IterateChildren("docs", nil, false, func(c string) (bool, error) {
+			children = append(children, c)
+			return len(children) < 2, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", children, []string{"a.txt", "b.txt"})
+		assert(t, "", *next, "c.txt")
+
+		children, totalElements, pages, err := folders.PageOfChildren("docs", 2, 2, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", children, []string{"c.txt"})
+		assert(t, "", totalElements, 3)
+		assert(t, "", pages, 2)
+
+		parents, _, _, err := folders.PageOfParents(1, 10, true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", parents, []string{"images", "docs"})
+
+		elements, _, _, err := folders.Page(1, 2, true)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", elements, []boltron.RelationElement[string, string]{
+			{Parent: "images", Child: "d.png"},
+			{Parent: "docs", Child: "c.txt"},
+		})
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		folders := foldersDefinition.Relation(tx)
+
+		err := folders.Move("d.png", "docs")
+		assertErrorFail(t, "", err, nil)
+
+		err = folders.Move("missing", "docs")
+		assertError(t, "", err, boltron.ErrChildNotFound)
+
+		has, err := folders.HasParent("images")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", has, false)
+
+		parent, err := folders.Parent("d.png")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", parent, "docs")
+
+		err = folders.RemoveChild("a.txt", true)
+		assertErrorFail(t, "", err, nil)
+
+		err = folders.RemoveChild("a.txt", true)
+		assertError(t, "", err, boltron.ErrChildNotFound)
+
+		err = folders.Add("images", "e.png")
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		folders := foldersDefinition.Relation(tx)
+
+		err := folders.DeleteParent("docs", true)
+		assertErrorFail(t, "", err, nil)
+
+		err = folders.DeleteParent("docs", true)
+		assertError(t, "", err, boltron.ErrParentNotFound)
+
+		for _, c := range []string{"b.txt", "c.txt", "d.png"} {
+			has, err := folders.HasChild(c)
+			assertErrorFail(t, "", err, nil)
+			assert(t, c, has, false)
+		}
+
+		got := make(map[string]string)
+		_, err = folders.Iterate(nil, false, func(c, p string) (bool, error) {
+			got[c] = p
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", got, map[string]string{"e.png": "images"})
+	})
+}
+
+func TestRelation_skipCorrupted(t *testing.T) {
+	db := newDB(t)
+
+	var corrupted []string
+
+	definition := boltron.NewRelationDefinition(
+		"checksums",
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		boltron.StringEncoding,
+		&boltron.RelationOptions{
+			CorruptedHandler: func(key []byte, err error) {
+				assertError(t, "", err, boltron.ErrCorrupted)
+				corrupted = append(corrupted, string(key))
+			},
+		},
+	)
+
+	strictDefinition := boltron.NewRelationDefinition(
+		"checksums",
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		boltron.StringEncoding,
+		nil,
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		r := definition.Relation(tx)
+		for _, c := range []string{"a", "b", "c"} {
+			err := r.Add("x", c)
+			assertErrorFail(t, "", err, nil)
+		}
+
+		// corrupt the parent of the child b
+		bucket := tx.Bucket([]byte("boltron: relation: checksums parents"))
+		v := append([]byte(nil), bucket.Get([]byte("b"))...)
+		v[0] ^= 0xff
+		err := bucket.Put([]byte("b"), v)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		var children []string
+		_, err := definition.Relation(tx).Iterate(nil, false, func(c, _ string) (bool, error) {
+			children = append(children, c)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", children, []string{"a", "c"})
+		assert(t, "", corrupted, []string{"b"})
+
+		elements, totalElements, pages, err := definition.Relation(tx).Page(1, 2, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(elements), 1)
+		assert(t, "", elements[0].Child, "a")
+		assert(t, "", totalElements, 3)
+		assert(t, "", pages, 2)
+		assert(t, "", corrupted, []string{"b", "b"})
+
+		_, err = strictDefinition.Relation(tx).Iterate(nil, false, func(_, _ string) (bool, error) {
+			return true, nil
+		})
+		assertError(t, "", err, boltron.ErrCorrupted)
+	})
+}
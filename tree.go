@@ -0,0 +1,679 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrTreeCycle is the default error if the Tree node would become its own
+// ancestor.
+var ErrTreeCycle = errors.New("boltron: tree cycle")
+
+// Parent links in the parents bucket are prefixed to distinguish root nodes
+// from nodes with parents that are encoded as empty byte slices.
+const (
+	treeRootPrefix   byte = 0
+	treeParentPrefix byte = 1
+)
+
+// TreeDefinition defines a hierarchy of nodes identified by unique keys, where
+// every node holds a value and has at most one parent. Nodes without parents
+// are root nodes. Children of every node are ordered by their keys.
+type TreeDefinition[K, V any] struct {
+	bucketNameNodes    []byte
+	bucketNameParents  []byte
+	bucketNameChildren []byte
+	bucketNameRoots    []byte
+	keyEncoding        Encoding[K]
+	valueEncoding      Encoding[V]
+	fillPercent        float64
+	errNotFound        error
+	errKeyExists       error
+	errParentNotFound  error
+	errCycle           error
+	corruptedHandler   func(key []byte, err error)
+}
+
+// TreeOptions provides additional configuration for a Tree.
+type TreeOptions struct {
+	// FillPercent is the value for the bolt bucket fill percent.
+	FillPercent float64
+	// ErrNotFound is returned if the node is not found.
+	ErrNotFound error
+	// ErrKeyExists is returned if the inserted node already exists.
+	ErrKeyExists error
+	// ErrParentNotFound is returned if the parent node is not found.
+	ErrParentNotFound error
+	// ErrCycle is returned if the node is moved under itself or under one of
+	// its descendants.
+	ErrCycle error
+	// CorruptedHandler is called with the encoded key of every node that is
+	// skipped by iteration, pagination and traversal methods because its key
+	// or value can not be decoded with ErrCorrupted error. If it is nil,
+	// iteration is aborted. Skipped nodes are still counted in total elements
+	// and pages returned by pagination methods, so a page may contain fewer
+	// elements than the limit. Descendants of skipped nodes are still
+	// traversed.
+	CorruptedHandler func(key []byte, err error)
+}
+
+// NewTreeDefinition constructs a new TreeDefinition with a unique name and key
+// and value encodings.
+func NewTreeDefinition[K, V any](
+	name string,
+	keyEncoding Encoding[K],
+	valueEncoding Encoding[V],
+	o *TreeOptions,
+) *TreeDefinition[K, V] {
+	if o == nil {
+		o = new(TreeOptions)
+	}
+	return &TreeDefinition[K, V]{
+		bucketNameNodes:    []byte("boltron: tree: " + name + " nodes"),
+		bucketNameParents:  []byte("boltron: tree: " + name + " parents"),
+		bucketNameChildren: []byte("boltron: tree: " + name + " children"),
+		bucketNameRoots:    []byte("boltron: tree: " + name + " roots"),
+		keyEncoding:        keyEncoding,
+		valueEncoding:      valueEncoding,
+		fillPercent:        o.FillPercent,
+		errNotFound:        withDefaultError(o.ErrNotFound, ErrNotFound),
+		errKeyExists:       withDefaultError(o.ErrKeyExists, ErrKeyExists),
+		errParentNotFound:  withDefaultError(o.ErrParentNotFound, ErrParentNotFound),
+		errCycle:           withDefaultError(o.ErrCycle, ErrTreeCycle),
+		corruptedHandler:   o.CorruptedHandler,
+	}
+}
+
+// Tree returns a Tree that has access to the stored data through the bolt
+// transaction.
+func (d *TreeDefinition[K, V]) Tree(tx *bolt.Tx) *Tree[K, V] {
+	return &Tree[K, V]{
+		tx:         tx,
+		definition: d,
+	}
+}
+
+// Tree provides methods to access and change the hierarchy of nodes.
+type Tree[K, V any] struct {
+	tx                  *bolt.Tx
+	nodesBucketCache    *bolt.Bucket
+	parentsBucketCache  *bolt.Bucket
+	childrenBucketCache *bolt.Bucket
+	rootsBucketCache    *bolt.Bucket
+	definition          *TreeDefinition[K, V]
+}
+
+func (t *Tree[K, V]) bucket(cache **bolt.Bucket, name []byte, create bool) (*bolt.Bucket, error) {
+	if *cache != nil {
+		return *cache, nil
+	}
+	bucket, err := rootBucket(t.tx, create, name)
+	if err != nil {
+		return nil, err
+	}
+	if t.definition.fillPercent > 0 && bucket != nil {
+		bucket.FillPercent = t.definition.fillPercent
+	}
+	*cache = bucket
+	return bucket, nil
+}
+
+func (t *Tree[K, V]) nodesBucket(create bool) (*bolt.Bucket, error) {
+	return t.bucket(&t.nodesBucketCache, t.definition.bucketNameNodes, create)
+}
+
+func (t *Tree[K, V]) parentsBucket(create bool) (*bolt.Bucket, error) {
+	return t.bucket(&t.parentsBucketCache, t.definition.bucketNameParents, create)
+}
+
+func (t *Tree[K, V]) childrenBucket(create bool) (*bolt.Bucket, error) {
+	return t.bucket(&t.childrenBucketCache, t.definition.bucketNameChildren, create)
+}
+
+func (t *Tree[K, V]) rootsBucket(create bool) (*bolt.Bucket, error) {
+	return t.bucket(&t.rootsBucketCache, t.definition.bucketNameRoots, create)
+}
+
+// childrenOf returns the bucket with children of the encoded parent, or the
+// bucket with root nodes if the parent is nil.
+func (t *Tree[K, V]) childrenOf(p []byte, create bool) (*bolt.Bucket, error) {
+	if p == nil {
+		bucket, err := t.rootsBucket(create)
+		if err != nil {
+			return nil, fmt.Errorf("roots bucket: %w", err)
+		}
+		return bucket, nil
+	}
+	childrenBucket, err := t.childrenBucket(create)
+	if err != nil {
+		return nil, fmt.Errorf("children bucket: %w", err)
+	}
+	if childrenBucket == nil {
+		return nil, nil
+	}
+	bucket, err := nestedBucket(childrenBucket, create, p)
+	if err != nil {
+		return nil, fmt.Errorf("node children bucket: %w", err)
+	}
+	if t.definition.fillPercent > 0 && bucket != nil {
+		bucket.FillPercent = t.definition.fillPercent
+	}
+	return bucket, nil
+}
+
+// parentOf returns the encoded parent of the encoded node and true if the node
+// exists. The parent is nil for root nodes.
+func (t *Tree[K, V]) parentOf(k []byte) (p []byte, exists bool, err error) {
+	parentsBucket, err := t.parentsBucket(false)
+	if err != nil {
+		return nil, false, fmt.Errorf("parents bucket: %w", err)
+	}
+	if parentsBucket == nil {
+		return nil, false, nil
+	}
+	v := parentsBucket.Get(k)
+	if len(v) == 0 {
+		return nil, false, nil
+	}
+	if v[0] == treeRootPrefix {
+		return nil, true, nil
+	}
+	return v[1:], true, nil
+}
+
+func (t *Tree[K, V]) encodeParent(parent *K) ([]byte, error) {
+	if parent == nil {
+		return nil, nil
+	}
+	p, err := t.definition.keyEncoding.Encode(*parent)
+	if err != nil {
+		return nil, fmt.Errorf("encode parent: %w", err)
+	}
+	_, exists, err := t.parentOf(p)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, t.definition.errParentNotFound
+	}
+	return p, nil
+}
+
+// link saves the encoded node as a child of the encoded parent.
+func (t *Tree[K, V]) link(k, p []byte) error {
+	children, err := t.childrenOf(p, true)
+	if err != nil {
+		return err
+	}
+	if err := children.Put(k, []byte{}); err != nil {
+		return fmt.Errorf("put child: %w", err)
+	}
+	parentsBucket, err := t.parentsBucket(true)
+	if err != nil {
+		return fmt.Errorf("parents bucket: %w", err)
+	}
+	v := []byte{treeRootPrefix}
+	if p != nil {
+		v = append([]byte{treeParentPrefix}, p...)
+	}
+	if err := parentsBucket.Put(k, v); err != nil {
+		return fmt.Errorf("put parent: %w", err)
+	}
+	return nil
+}
+
+// unlink removes the encoded node from children of the encoded parent.
+func (t *Tree[K, V]) unlink(k, p []byte) error {
+	children, err := t.childrenOf(p, false)
+	if err != nil {
+		return err
+	}
+	if children == nil {
+		return errors.New("children bucket does not exist")
+	}
+	if err := children.Delete(k); err != nil {
+		return fmt.Errorf("delete child: %w", err)
+	}
+	if p == nil {
+		return nil
+	}
+	if f, _ := children.Cursor().First(); f == nil {
+		childrenBucket, err := t.childrenBucket(false)
+		if err != nil {
+			return fmt.Errorf("children bucket: %w", err)
+		}
+		if err := childrenBucket.DeleteBucket(p); err != nil {
+			return fmt.Errorf("delete empty node children bucket: %w", err)
+		}
+	}
+	return nil
+}
+
+// Has returns true if the node exists in the tree.
+func (t *Tree[K, V]) Has(key K) (bool, error) {
+	k, err := t.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return false, fmt.Errorf("encode key: %w", err)
+	}
+	_, exists, err := t.parentOf(k)
+	return exists, err
+}
+
+// Get returns the value of the node. If the node does not exist, configured
+// ErrNotFound is returned.
+func (t *Tree[K, V]) Get(key K) (value V, err error) {
+	k, err := t.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return value, fmt.Errorf("encode key: %w", err)
+	}
+	nodesBucket, err := t.nodesBucket(false)
+	if err != nil {
+		return value, fmt.Errorf("nodes bucket: %w", err)
+	}
+	if nodesBucket == nil {
+		return value, t.definition.errNotFound
+	}
+	v, exists := bucketValue(nodesBucket, k)
+	if !exists {
+		return value, t.definition.errNotFound
+	}
+	value, err = t.definition.valueEncoding.Decode(v)
+	if err != nil {
+		return value, fmt.Errorf("decode value: %w", err)
+	}
+	return value, nil
+}
+
+// Insert saves a new node with the value under the parent node. If the parent
+// is nil, the node is saved as a root node. If the node already exists,
+// configured ErrKeyExists is returned, and if the parent does not exist,
+// configured ErrParentNotFound is returned.
+func (t *Tree[K, V]) Insert(parent *K, key K, value V) error {
+	k, err := t.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+	v, err := t.definition.valueEncoding.Encode(value)
+	if err != nil {
+		return fmt.Errorf("encode value: %w", err)
+	}
+	_, exists, err := t.parentOf(k)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return t.definition.errKeyExists
+	}
+	p, err := t.encodeParent(parent)
+	if err != nil {
+		return err
+	}
+	nodesBucket, err := t.nodesBucket(true)
+	if err != nil {
+		return fmt.Errorf("nodes bucket: %w", err)
+	}
+	if err := nodesBucket.Put(k, v); err != nil {
+		return fmt.Errorf("put value: %w", err)
+	}
+	return t.link(k, p)
+}
+
+// Update replaces the value of the existing node. If the node does not exist,
+// configured ErrNotFound is returned.
+func (t *Tree[K, V]) Update(key K, value V) error {
+	k, err := t.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+	v, err := t.definition.valueEncoding.Encode(value)
+	if err != nil {
+		return fmt.Errorf("encode value: %w", err)
+	}
+	_, exists, err := t.parentOf(k)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return t.definition.errNotFound
+	}
+	nodesBucket, err := t.nodesBucket(true)
+	if err != nil {
+		return fmt.Errorf("nodes bucket: %w", err)
+	}
+	if err := nodesBucket.Put(k, v); err != nil {
+		return fmt.Errorf("put value: %w", err)
+	}
+	return nil
+}
+
+// Parent returns the parent of the node, or nil if the node is a root node. If
+// the node does not exist, configured ErrNotFound is returned.
+func (t *Tree[K, V]) Parent(key K) (parent *K, err error) {
+	k, err := t.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return nil, fmt.Errorf("encode key: %w", err)
+	}
+	p, exists, err := t.parentOf(k)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, t.definition.errNotFound
+	}
+	if p == nil {
+		return nil, nil
+	}
+	pk, err := t.definition.keyEncoding.Decode(p)
+	if err != nil {
+		return nil, fmt.Errorf("decode parent: %w", err)
+	}
+	return &pk, nil
+}
+
+// Ancestors returns the path from the parent of the node to its root node. It
+// is empty for root nodes. If the node does not exist, configured ErrNotFound
+// is returned.
+func (t *Tree[K, V]) Ancestors(key K) (ancestors []K, err error) {
+	k, err := t.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return nil, fmt.Errorf("encode key: %w", err)
+	}
+	p, exists, err := t.parentOf(k)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, t.definition.errNotFound
+	}
+	for p != nil {
+		ancestor, err := t.definition.keyEncoding.Decode(p)
+		if err != nil {
+			return nil, fmt.Errorf("decode parent: %w", err)
+		}
+		ancestors = append(ancestors, ancestor)
+		p, _, err = t.parentOf(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ancestors, nil
+}
+
+// Move moves the node with its whole subtree under the new parent. If the new
+// parent is nil, the node becomes a root node. If the new parent is the node
+// itself or one of its descendants, configured ErrCycle is returned.
+func (t *Tree[K, V]) Move(key K, newParent *K) error {
+	k, err := t.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+	current, exists, err := t.parentOf(k)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return t.definition.errNotFound
+	}
+	current = append([]byte(nil), current...)
+	p, err := t.encodeParent(newParent)
+	if err != nil {
+		return err
+	}
+	for a := p; a != nil; {
+		if string(a) == string(k) {
+			return t.definition.errCycle
+		}
+		a, _, err = t.parentOf(a)
+		if err != nil {
+			return err
+		}
+	}
+	if (current == nil) == (p == nil) && string(current) == string(p) {
+		return nil
+	}
+	if err := t.unlink(k, current); err != nil {
+		return err
+	}
+	return t.link(k, p)
+}
+
+// IterateChildren iterates over children of the parent node, or over root nodes
+// if the parent is nil, in the lexicographical order of keys. If the callback
+// function f returns false, the iteration stops and the next can be used to
+// continue the iteration.
+func (t *Tree[K, V]) IterateChildren(parent *K, start *K, reverse bool, f func(K, V) (bool, error)) (next *K, err error) {
+	p, err := t.encodeParent(parent)
+	if err != nil {
+		return nil, err
+	}
+	children, err := t.childrenOf(p, false)
+	if err != nil {
+		return nil, err
+	}
+	if children == nil {
+		return nil, nil
+	}
+	nodesBucket, err := t.nodesBucket(false)
+	if err != nil {
+		return nil, fmt.Errorf("nodes bucket: %w", err)
+	}
+	return iterateKeys(children, t.definition.keyEncoding, t.definition.corruptedHandler, start, reverse, func(k, _ []byte) (bool, error) {
+		key, value, err := t.decodeNode(nodesBucket, k)
+		if err != nil {
+			if skipCorrupted(t.definition.corruptedHandler, k, err) {
+				return true, nil
+			}
+			return false, err
+		}
+
+		return f(key, value)
+	})
+}
+
+// TreeElement is the type returned by Tree pagination methods as slice elements
+// that contain both key and value.
+type TreeElement[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// PageOfChildren returns at most a limit of children of the parent node, or
+// root nodes if the parent is nil, at the provided page number.
+func (t *Tree[K, V]) PageOfChildren(parent *K, number, limit int, reverse bool) (s []TreeElement[K, V], totalElements, pages int, err error) {
+	p, err := t.encodeParent(parent)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	children, err := t.childrenOf(p, false)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if children == nil {
+		return nil, 0, 0, nil
+	}
+	nodesBucket, err := t.nodesBucket(false)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("nodes bucket: %w", err)
+	}
+	return page(children, false, number, limit, reverse, func(k, _ []byte) (e TreeElement[K, V], err error) {
+		key, value, err := t.decodeNode(nodesBucket, k)
+		if err != nil {
+			if skipCorrupted(t.definition.corruptedHandler, k, err) {
+				return e, errSkipElement
+			}
+			return e, err
+		}
+
+		return TreeElement[K, V]{
+			Key:   key,
+			Value: value,
+		}, nil
+	})
+}
+
+func (t *Tree[K, V]) decodeNode(nodesBucket *bolt.Bucket, k []byte) (key K, value V, err error) {
+	key, err = t.definition.keyEncoding.Decode(k)
+	if err != nil {
+		return key, value, fmt.Errorf("decode key: %w", err)
+	}
+	if nodesBucket == nil {
+		return key, value, errors.New("nodes bucket does not exist")
+	}
+	v, exists := bucketValue(nodesBucket, k)
+	if !exists {
+		return key, value, errors.New("node value does not exist")
+	}
+	value, err = t.definition.valueEncoding.Decode(v)
+	if err != nil {
+		return key, value, fmt.Errorf("decode value: %w", err)
+	}
+	return key, value, nil
+}
+
+// Descendants traverses the subtree of the node depth-first, with children in
+// the lexicographical order of keys, and calls f for every descendant with its
+// depth relative to the node. Children of the node have depth 1. Descendants
+// deeper than maxDepth are not visited, unless maxDepth is not positive. If the
+// callback function f returns false, the traversal stops. If the node does not
+// exist, configured ErrNotFound is returned.
+func (t *Tree[K, V]) Descendants(key K, maxDepth int, f func(key K, value V, depth int) (bool, error)) error {
+	k, err := t.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+	_, exists, err := t.parentOf(k)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return t.definition.errNotFound
+	}
+	nodesBucket, err := t.nodesBucket(false)
+	if err != nil {
+		return fmt.Errorf("nodes bucket: %w", err)
+	}
+	_, err = t.descendants(nodesBucket, k, 1, maxDepth, f)
+	return err
+}
+
+func (t *Tree[K, V]) descendants(nodesBucket *bolt.Bucket, k []byte, depth, maxDepth int, f func(K, V, int) (bool, error)) (bool, error) {
+	if maxDepth > 0 && depth > maxDepth {
+		return true, nil
+	}
+	children, err := t.childrenOf(k, false)
+	if err != nil {
+		return false, err
+	}
+	if children == nil {
+		return true, nil
+	}
+	c := children.Cursor()
+	for ck, _ := c.First(); ck != nil; ck, _ = c.Next() {
+		key, value, err := t.decodeNode(nodesBucket, ck)
+		if err != nil {
+			if !skipCorrupted(t.definition.corruptedHandler, ck, err) {
+				return false, err
+			}
+		} else {
+			cont, err := f(key, value, depth)
+			if err != nil {
+				return false, err
+			}
+			if !cont {
+				return false, nil
+			}
+		}
+		cont, err := t.descendants(nodesBucket, ck, depth+1, maxDepth, f)
+		if err != nil || !cont {
+			return cont, err
+		}
+	}
+	return true, nil
+}
+
+// DeleteSubtree removes the node and all of its descendants. If ensure flag is
+// set to true and the node does not exist, configured ErrNotFound is returned.
+func (t *Tree[K, V]) DeleteSubtree(key K, ensure bool) error {
+	k, err := t.definition.keyEncoding.Encode(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+	p, exists, err := t.parentOf(k)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if ensure {
+			return t.definition.errNotFound
+		}
+		return nil
+	}
+	if err := t.unlink(k, append([]byte(nil), p...)); err != nil {
+		return err
+	}
+	return t.deleteNode(k)
+}
+
+// deleteNode removes the encoded node and its descendants without unlinking it
+// from its parent.
+func (t *Tree[K, V]) deleteNode(k []byte) error {
+	children, err := t.childrenOf(k, false)
+	if err != nil {
+		return err
+	}
+	if children != nil {
+		var keys [][]byte
+		if err := children.ForEach(func(ck, _ []byte) error {
+			keys = append(keys, append([]byte(nil), ck...))
+			return nil
+		}); err != nil {
+			return fmt.Errorf("iterate children: %w", err)
+		}
+		for _, ck := range keys {
+			if err := t.deleteNode(ck); err != nil {
+				return err
+			}
+		}
+		childrenBucket, err := t.childrenBucket(false)
+		if err != nil {
+			return fmt.Errorf("children bucket: %w", err)
+		}
+		if err := childrenBucket.DeleteBucket(k); err != nil {
+			return fmt.Errorf("delete node children bucket: %w", err)
+		}
+	}
+	nodesBucket, err := t.nodesBucket(false)
+	if err != nil {
+		return fmt.Errorf("nodes bucket: %w", err)
+	}
+	if err := nodesBucket.Delete(k); err != nil {
+		return fmt.Errorf("delete value: %w", err)
+	}
+	parentsBucket, err := t.parentsBucket(false)
+	if err != nil {
+		return fmt.Errorf("parents bucket: %w", err)
+	}
+	if err := parentsBucket.Delete(k); err != nil {
+		return fmt.Errorf("delete parent: %w", err)
+	}
+	return nil
+}
+
+// Size returns the number of nodes in the tree.
+func (t *Tree[K, V]) Size() (int, error) {
+	nodesBucket, err := t.nodesBucket(false)
+	if err != nil {
+		return 0, fmt.Errorf("nodes bucket: %w", err)
+	}
+	if nodesBucket == nil {
+		return 0, nil
+	}
+	return size(nodesBucket, false), nil
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+var categoriesDefinition = boltron.NewTreeDefinition(
+	"categories",
+	boltron.StringEncoding,
+	boltron.StringEncoding,
+	nil,
+)
+
+func TestTree(t *testing.T) {
+	db := newDB(t)
+
+	ptr := func(s string) *string { return &s }
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		categories := categoriesDefinition.Tree(tx)
+
+		for _, n := range []struct {
+			Parent *string
+			Key    string
+			Value  string
+		}{
+			{nil, "root", "Root"},
+			{ptr("root"), "books", "Books"},
+			{ptr("root"), "music", "Music"},
+			{ptr("books"), "fiction", "Fiction"},
+			{ptr("books"), "science", "Science"},
+			{ptr("fiction"), "fantasy", "Fantasy"},
+			{nil, "archive", "Archive"},
+		} {
+			err := categories.Insert(n.Parent, n.Key, n.Value)
+			assertErrorFail(t, n.Key, err, nil)
+		}
+
+		err := categories.Insert(nil, "books", "Books")
+		assertError(t, "", err, boltron.ErrKeyExists)
+
+		err = categories.Insert(ptr("missing"), "other", "Other")
+		assertError(t, "", err, boltron.ErrParentNotFound)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		categories := categoriesDefinition.Tree(tx)
+
+		value, err := categories.Get("fiction")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", value, "Fiction")
+
+		parent, err := categories.Parent("fiction")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", *parent, "books")
+
+		parent, err = categories.Parent("root")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", parent, (*string)(nil))
+
+		ancestors, err := categories.Ancestors("fantasy")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", ancestors, []string{"fiction", "books", "root"})
+
+		roots, _, _, err := categories.PageOfChildren(nil, 1, 10, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", roots, []boltron.TreeElement[string, string]{
+			{Key: "archive", Value: "Archive"},
+			{Key: "root", Value: "Root"},
+		})
+
+		var children []string
+		_, err = categories.IterateChildren(ptr("books"), nil, true, func(k, _ string) (bool, error) {
+			children = append(children, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", children, []string{"science", "fiction"})
+
+		type descendant struct {
+			Key   string
+			Depth int
+		}
+		var descendants []descendant
+		err = categories.Descendants("root", 0, func(k, _ string, depth int) (bool, error) {
+			descendants = append(descendants, descendant{k, depth})
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", descendants, []descendant{
+			{"books", 1},
+			{"fiction", 2},
+			{"fantasy", 3},
+			{"science", 2},
+			{"music", 1},
+		})
+
+		descendants = nil
+		err = categories.Descendants("root", 2, func(k, _ string, depth int) (bool, error) {
+			descendants = append(descendants, descendant{k, depth})
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", descendants, []descendant{
+			{"books", 1},
+			{"fiction", 2},
+			{"science", 2},
+			{"music", 1},
+		})
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		categories := categoriesDefinition.Tree(tx)
+
+		err := categories.Move("books", ptr("fantasy"))
+		assertError(t, "", err, boltron.ErrTreeCycle)
+
+		err = categories.Move("books", ptr("books"))
+		assertError(t, "", err, boltron.ErrTreeCycle)
+
+		err = categories.Move("fiction", ptr("archive"))
+		assertErrorFail(t, "", err, nil)
+
+		ancestors, err := categories.Ancestors("fantasy")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", ancestors, []string{"fiction", "archive"})
+
+		err = categories.Move("archive", ptr("music"))
+		assertErrorFail(t, "", err, nil)
+
+		err = categories.Update("music", "Music & Sound")
+		assertErrorFail(t, "", err, nil)
+
+		err = categories.DeleteSubtree("music", true)
+		assertErrorFail(t, "", err, nil)
+
+		err = categories.DeleteSubtree("music", true)
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		for _, k := range []string{"archive", "fiction", "fantasy"} {
+			has, err := categories.Has(k)
+			assertErrorFail(t, "", err, nil)
+			assert(t, k, has, false)
+		}
+
+		var keys []string
+		err = categories.Descendants("root", 0, func(k, _ string, _ int) (bool, error) {
+			keys = append(keys, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", keys, []string{"books", "science"})
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		size, err := categoriesDefinition.Tree(tx).Size()
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", size, 3)
+	})
+}
+
+func TestTree_nullValue(t *testing.T) {
+	db := newDB(t)
+
+	tagsDefinition := boltron.NewTreeDefinition(
+		"tags",
+		boltron.StringEncoding,
+		boltron.NullEncoding,
+		nil,
+	)
+
+	root := "root"
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		tags := tagsDefinition.Tree(tx)
+
+		err := tags.Insert(nil, "root", nil)
+		assertErrorFail(t, "", err, nil)
+
+		err = tags.Insert(&root, "news", nil)
+		assertErrorFail(t, "", err, nil)
+
+		_, err = tags.Get("news")
+		assertErrorFail(t, "", err, nil)
+
+		_, err = tags.Get("sports")
+		assertError(t, "", err, boltron.ErrNotFound)
+
+		var children []string
+		_, err = tags.IterateChildren(&root, nil, false, func(k string, _ *struct{}) (bool, error) {
+			children = append(children, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", children, []string{"news"})
+
+		var descendants []string
+		err = tags.Descendants("root", 0, func(k string, _ *struct{}, _ int) (bool, error) {
+			descendants = append(descendants, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", descendants, []string{"news"})
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		_, err := tagsDefinition.Tree(tx).Get("root")
+		assertErrorFail(t, "", err, nil)
+	})
+}
+
+func TestTree_skipCorrupted(t *testing.T) {
+	db := newDB(t)
+
+	var corrupted []string
+
+	definition := boltron.NewTreeDefinition(
+		"checksums",
+		boltron.StringEncoding,
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		&boltron.TreeOptions{
+			CorruptedHandler: func(key []byte, err error) {
+				assertError(t, "", err, boltron.ErrCorrupted)
+				corrupted = append(corrupted, string(key))
+			},
+		},
+	)
+
+	strictDefinition := boltron.NewTreeDefinition(
+		"checksums",
+		boltron.StringEncoding,
+		boltron.Encoding[string](boltron.NewChecksumEncoding(boltron.StringEncoding)),
+		nil,
+	)
+
+	root := "x"
+	b := "b"
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		tree := definition.Tree(tx)
+		err := tree.Insert(nil, root, "value x")
+		assertErrorFail(t, "", err, nil)
+		for _, k := range []string{"a", "b", "c"} {
+			err := tree.Insert(&root, k, "value "+k)
+			assertErrorFail(t, "", err, nil)
+		}
+		err = tree.Insert(&b, "d", "value d")
+		assertErrorFail(t, "", err, nil)
+
+		// corrupt the value of the node b
+		bucket := tx.Bucket([]byte("boltron: tree: checksums nodes"))
+		v := append([]byte(nil), bucket.Get([]byte("b"))...)
+		v[0] ^= 0xff
+		err = bucket.Put([]byte("b"), v)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		var children []string
+		_, err := definition.Tree(tx).IterateChildren(&root, nil, false, func(k, _ string) (bool, error) {
+			children = append(children, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", children, []string{"a", "c"})
+		assert(t, "", corrupted, []string{"b"})
+
+		elements, totalElements, pages, err := definition.Tree(tx).PageOfChildren(&root, 1, 2, false)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", len(elements), 1)
+		assert(t, "", elements[0].Key, "a")
+		assert(t, "", totalElements, 3)
+		assert(t, "", pages, 2)
+		assert(t, "", corrupted, []string{"b", "b"})
+
+		var descendants []string
+		err = definition.Tree(tx).Descendants(root, 0, func(k, _ string, _ int) (bool, error) {
+			descendants = append(descendants, k)
+			return true, nil
+		})
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", descendants, []string{"a", "d", "c"})
+		assert(t, "", corrupted, []string{"b", "b", "b"})
+
+		_, err = strictDefinition.Tree(tx).IterateChildren(&root, nil, false, func(_, _ string) (bool, error) {
+			return true, nil
+		})
+		assertError(t, "", err, boltron.ErrCorrupted)
+	})
+}
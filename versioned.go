@@ -0,0 +1,153 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedVersion is returned by VersionedEncoding if the encoded value
+// has a version that does not have a decoder.
+var ErrUnsupportedVersion = errors.New("boltron: unsupported version")
+
+// versionedEncodingMagic is the first byte of every value encoded by
+// VersionedEncoding. It is not a valid first byte of UTF-8 or JSON encoded
+// data, which allows values stored before the versioning was introduced to be
+// distinguished from the versioned ones.
+const versionedEncodingMagic = 0xfe
+
+// VersionDecoder decodes a value of a specific version and upgrades it to the
+// current type.
+type VersionDecoder[T any] struct {
+	version uint64
+	decode  func([]byte) (T, error)
+}
+
+// NewVersionDecoder constructs a VersionDecoder for values stored with the
+// provided version using the encoding of the previous type P and an upgrade
+// function that converts it to the current type T. Version 0 is reserved for
+// legacy values that were stored without the version header.
+func NewVersionDecoder[T, P any](
+	version uint64,
+	encoding Encoding[P],
+	upgrade func(P) (T, error),
+) VersionDecoder[T] {
+	return VersionDecoder[T]{
+		version: version,
+		decode: func(b []byte) (t T, err error) {
+			p, err := encoding.Decode(b)
+			if err != nil {
+				return t, err
+			}
+			return upgrade(p)
+		},
+	}
+}
+
+// VersionedEncoding prefixes the encoded value with its version and dispatches
+// decoding to the decoder for the stored version, upgrading older values to the
+// current type. Values are always encoded with the current version, so the
+// upgraded values are rewritten lazily when they are saved again.
+//
+// Values stored by the wrapped encoding before the versioning was introduced
+// are decoded as version 0, but only if their first byte is not 0xfe, which
+// marks versioned values. It is safe for encodings of text, like
+// StringEncoding of valid UTF-8 strings or JSON, but not for binary encodings
+// that may produce that first byte, like Uint64BinaryEncoding, whose existing
+// values must be rewritten with the VersionedEncoding instead.
+type VersionedEncoding[T any] struct {
+	version  uint64
+	header   []byte
+	encoding Encoding[T]
+	decoders map[uint64]func([]byte) (T, error)
+}
+
+// NewVersionedEncoding constructs a VersionedEncoding where the encoding is used
+// for the current version and decoders for all previous versions. The current
+// version should be greater than 0, as with version 0 legacy values are
+// decoded with the current encoding. A decoder for the current version is
+// never used.
+func NewVersionedEncoding[T any](
+	version uint64,
+	encoding Encoding[T],
+	decoders ...VersionDecoder[T],
+) *VersionedEncoding[T] {
+	header := make([]byte, 1, 1+binary.MaxVarintLen64)
+	header[0] = versionedEncodingMagic
+	header = binary.AppendUvarint(header, version)
+
+	m := make(map[uint64]func([]byte) (T, error), len(decoders))
+	for _, d := range decoders {
+		m[d.version] = d.decode
+	}
+	return &VersionedEncoding[T]{
+		version:  version,
+		header:   header,
+		encoding: encoding,
+		decoders: m,
+	}
+}
+
+// Encode serializes the value with the current version encoding and prefixes it
+// with the version header.
+func (e *VersionedEncoding[T]) Encode(t T) ([]byte, error) {
+	b, err := e.encoding.Encode(t)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(make([]byte, 0, len(e.header)+len(b)), e.header...), b...), nil
+}
+
+// Decode deserializes the value with the decoder for its stored version. If the
+// version is not the current one, the value is upgraded to the current type.
+func (e *VersionedEncoding[T]) Decode(b []byte) (t T, err error) {
+	version, data, err := e.split(b)
+	if err != nil {
+		return t, err
+	}
+	if version == e.version {
+		return e.encoding.Decode(data)
+	}
+	decode, ok := e.decoders[version]
+	if !ok {
+		return t, fmt.Errorf("version %v: %w", version, ErrUnsupportedVersion)
+	}
+	t, err = decode(data)
+	if err != nil {
+		return t, fmt.Errorf("version %v: %w", version, err)
+	}
+	return t, nil
+}
+
+// Version returns the version of the encoded value. Version 0 is returned for
+// values without the version header.
+func (e *VersionedEncoding[T]) Version(b []byte) (uint64, error) {
+	version, _, err := e.split(b)
+	return version, err
+}
+
+// Outdated returns true if the encoded value is not stored with the current
+// version and it should be saved again to be upgraded.
+func (e *VersionedEncoding[T]) Outdated(b []byte) (bool, error) {
+	version, err := e.Version(b)
+	if err != nil {
+		return false, err
+	}
+	return version != e.version, nil
+}
+
+func (e *VersionedEncoding[T]) split(b []byte) (version uint64, data []byte, err error) {
+	if len(b) == 0 || b[0] != versionedEncodingMagic {
+		return 0, b, nil
+	}
+	version, n := binary.Uvarint(b[1:])
+	if n <= 0 {
+		return 0, nil, errors.New("invalid version header")
+	}
+	return version, b[1+n:], nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2021, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boltron_test
+
+import (
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+	"resenje.org/boltron"
+)
+
+type profileV1 struct {
+	Name string
+}
+
+type profileV2 struct {
+	FirstName string
+	LastName  string
+}
+
+type profile struct {
+	FirstName string
+	LastName  string
+	Email     string
+}
+
+func newProfileEncoding() *boltron.VersionedEncoding[profile] {
+	return boltron.NewVersionedEncoding(3, boltron.NewJSONEncoding[profile](),
+		boltron.NewVersionDecoder(0, boltron.NewJSONEncoding[profileV1](), func(p profileV1) (profile, error) {
+			return profile{FirstName: p.Name}, nil
+		}),
+		boltron.NewVersionDecoder(2, boltron.NewJSONEncoding[profileV2](), func(p profileV2) (profile, error) {
+			return profile{FirstName: p.FirstName, LastName: p.LastName}, nil
+		}),
+	)
+}
+
+func TestVersionedEncoding(t *testing.T) {
+	encoding := newProfileEncoding()
+
+	testEncoding(t, boltron.Encoding[profile](encoding), profile{FirstName: "Jane", LastName: "Doe", Email: "jane@example.com"},
+		append([]byte{0xfe, 3}, []byte(`{"FirstName":"Jane","LastName":"Doe","Email":"jane@example.com"}`)...),
+	)
+
+	t.Run("legacy", func(t *testing.T) {
+		b := []byte(`{"Name":"Jane"}`)
+
+		v, err := encoding.Decode(b)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, profile{FirstName: "Jane"})
+
+		version, err := encoding.Version(b)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", version, uint64(0))
+
+		outdated, err := encoding.Outdated(b)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", outdated, true)
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		b := append([]byte{0xfe, 2}, []byte(`{"FirstName":"Jane","LastName":"Doe"}`)...)
+
+		v, err := encoding.Decode(b)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, profile{FirstName: "Jane", LastName: "Doe"})
+
+		version, err := encoding.Version(b)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", version, uint64(2))
+	})
+
+	t.Run("current", func(t *testing.T) {
+		b, err := encoding.Encode(profile{FirstName: "Jane"})
+		assertErrorFail(t, "", err, nil)
+
+		outdated, err := encoding.Outdated(b)
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", outdated, false)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := encoding.Decode(append([]byte{0xfe, 1}, []byte(`{}`)...))
+		assertError(t, "", err, boltron.ErrUnsupportedVersion)
+	})
+}
+
+func TestVersionedEncoding_collection(t *testing.T) {
+	db := newDB(t)
+
+	legacyDefinition := boltron.NewCollectionDefinition(
+		"profiles",
+		boltron.StringEncoding,
+		boltron.NewJSONEncoding[profileV1](),
+		nil,
+	)
+
+	definition := boltron.NewCollectionDefinition(
+		"profiles",
+		boltron.StringEncoding,
+		boltron.Encoding[profile](newProfileEncoding()),
+		nil,
+	)
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		_, err := legacyDefinition.Collection(tx).Save("jane", profileV1{Name: "Jane"}, false)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbUpdate(t, db, func(t testing.TB, tx *bolt.Tx) {
+		profiles := definition.Collection(tx)
+
+		v, err := profiles.Get("jane")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, profile{FirstName: "Jane"})
+
+		v.Email = "jane@example.com"
+		_, err = profiles.Save("jane", v, true)
+		assertErrorFail(t, "", err, nil)
+	})
+
+	dbView(t, db, func(t testing.TB, tx *bolt.Tx) {
+		v, err := definition.Collection(tx).Get("jane")
+		assertErrorFail(t, "", err, nil)
+		assert(t, "", v, profile{FirstName: "Jane", Email: "jane@example.com"})
+
+		_, err = legacyDefinition.Collection(tx).Get("jane")
+		assert(t, "legacy decoding fails", err != nil, true)
+	})
+}